@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	configureStructure "github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	notifierStructure "github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// ack acknowledges the outages of a service, or of one of its endpoints, so that their escalation stops at the next check
+func ack(args []string) int {
+	flags := flag.NewFlagSet("ack", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	service := flags.String("service", "", "name of the service whose outages are acknowledged (required)")
+	endpoint := flags.String("endpoint", "", "URL or key of the endpoint whose outage is acknowledged, all endpoints if empty")
+	by := flags.String("by", os.Getenv("USER"), "who acknowledges the outages")
+	note := flags.String("note", "", "note kept with the acknowledgement")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+	if *service == "" {
+		log.Println("Missing -service")
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	index := slices.IndexFunc(cfg.Services, func(s configureStructure.Service) bool { return s.Name == *service })
+	if index < 0 {
+		log.Println("Unknown service:", *service)
+		return 1
+	}
+	if *endpoint != "" && !slices.ContainsFunc(cfg.Services[index].Endpoints, func(e configureStructure.Endpoint) bool { return e.URL == *endpoint || e.Key == *endpoint }) {
+		log.Println("Unknown endpoint of service", *service, ":", *endpoint)
+		return 1
+	}
+	if err := createOutputDirs(paths); err != nil {
+		log.Println("Error creating output directories:", err)
+		return 1
+	}
+
+	acknowledgement := notifierStructure.Acknowledgement{Service: *service, Endpoint: *endpoint, By: *by, Note: *note}
+	if err := notifier.Acknowledge(paths.Acks, acknowledgement); err != nil {
+		log.Println("Error saving acknowledgement:", err)
+		return 1
+	}
+	log.Println("Acknowledgement saved to", paths.Acks, ", applied at the next check of", *service)
+	return 0
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/announcement"
+	"github.com/wcy-dt/ponghub/internal/configure"
+	announcementStructure "github.com/wcy-dt/ponghub/internal/types/structures/announcement"
+	configureStructure "github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// announce posts an announcement on the status page, adds an update to an existing one,
+// or lists the announcements without -title and -id
+func announce(args []string) int {
+	flags := flag.NewFlagSet("announce", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	id := flags.String("id", "", "id of the announcement to update, as listed without flags")
+	title := flags.String("title", "", "title of a new announcement")
+	services := flags.String("service", "", "comma-separated names of the services a new announcement is about")
+	status := flags.String("status", "", "investigating, identified, monitoring, resolved or notice; investigating for a new announcement, unchanged for an update")
+	message := flags.String("message", "", "message of the update, in Markdown")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	if *id == "" && *title == "" {
+		announcements, err := announcement.Load(paths.Announcements)
+		if err != nil {
+			log.Println("Error loading announcements:", err)
+		}
+		if err := printAnnouncements(announcements); err != nil {
+			log.Println("Error printing announcements:", err)
+			return 1
+		}
+		return 0
+	}
+	if *id != "" && *title != "" {
+		log.Println("Use either -id to update an announcement or -title to post one")
+		return 2
+	}
+	if *message == "" {
+		log.Println("Missing -message")
+		return 2
+	}
+
+	if *id != "" {
+		updated, err := announcement.AddUpdate(paths.Announcements, *id, *status, *message, time.Now())
+		if err != nil {
+			log.Println("Error updating announcement:", err)
+			return 1
+		}
+		log.Println("Announcement", updated.ID, "is now", updated.Status())
+		return 0
+	}
+
+	// load the configuration to check the services
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	serviceNames := splitList(*services)
+	for _, name := range serviceNames {
+		if !slices.ContainsFunc(cfg.Services, func(s configureStructure.Service) bool { return s.Name == name }) {
+			log.Println("Unknown service:", name)
+			return 1
+		}
+	}
+	if *status == "" {
+		*status = announcementStructure.StatusInvestigating
+	}
+
+	posted, err := announcement.Post(paths.Announcements, *title, serviceNames, *status, *message, time.Now())
+	if err != nil {
+		log.Println("Error posting announcement:", err)
+		return 1
+	}
+	log.Println("Announcement", posted.ID, "saved to", paths.Announcements, ", shown in the next report")
+	return 0
+}
+
+// printAnnouncements prints the id, status, last update and title of each announcement as an aligned table
+func printAnnouncements(announcements announcementStructure.Announcements) error {
+	if len(announcements) == 0 {
+		fmt.Println("No announcements")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tSTATUS\tUPDATED\tTITLE")
+	for _, a := range announcements {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", a.ID, a.Status(), a.Latest().Time, a.Title)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/wcy-dt/ponghub/internal/checker"
+	"github.com/wcy-dt/ponghub/internal/configure"
+	checkerStructure "github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// check checks every service once and prints the results without writing logs, notifications or the report.
+// It exits with status 1 if any endpoint is unavailable.
+func check(args []string) int {
+	flags := flag.NewFlagSet("check", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	asJSON := flags.Bool("json", false, "print the full check results as JSON")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+
+	checkResult := checker.CheckServices(cfg)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(checkResult); err != nil {
+			log.Println("Error encoding check results:", err)
+			return 1
+		}
+	} else if err := printCheckResult(checkResult); err != nil {
+		log.Println("Error printing check results:", err)
+		return 1
+	}
+
+	for _, serviceResult := range checkResult {
+		for _, endpoint := range serviceResult.Endpoints {
+			if endpoint.Status == chk_result.NONE {
+				return 1
+			}
+		}
+	}
+	return 0
+}
+
+// printCheckResult prints one line per endpoint as an aligned table
+func printCheckResult(checkResult []checkerStructure.Service) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "SERVICE\tSTATUS\tMETHOD\tURL\tCODE\tTIME\tATTEMPTS")
+	for _, serviceResult := range checkResult {
+		for _, endpoint := range serviceResult.Endpoints {
+			statusCode := "-"
+			if endpoint.StatusCode > 0 {
+				statusCode = fmt.Sprint(endpoint.StatusCode)
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%d/%d\n",
+				serviceResult.Name, endpoint.Status, endpoint.Method, endpoint.DisplayURL,
+				statusCode, endpoint.ResponseTime, endpoint.SuccessNum, endpoint.AttemptNum)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/logger"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	loggerStructure "github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// export writes the check history, filtered by service, endpoint and time, as CSV or JSON Lines
+func export(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	output := flags.String("o", "", "write the history to this file instead of stdout")
+	format := flags.String("format", "", "csv or jsonl, defaults to the extension of -o or csv")
+	services := flags.String("service", "", "comma-separated names of the services to export")
+	endpoints := flags.String("endpoint", "", "comma-separated ids or URLs of the endpoints to export")
+	from := flags.String("from", "", "export entries from this time, as RFC 3339 or YYYY-MM-DD")
+	to := flags.String("to", "", "export entries up to this time, as RFC 3339 or YYYY-MM-DD (inclusive)")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	filter := loggerStructure.RecordFilter{Services: splitList(*services), Endpoints: splitList(*endpoints)}
+	var err error
+	if filter.From, err = parseTimeFlag(*from, false); err != nil {
+		log.Println("Error parsing -from:", err)
+		return 2
+	}
+	if filter.To, err = parseTimeFlag(*to, true); err != nil {
+		log.Println("Error parsing -to:", err)
+		return 2
+	}
+	if *format == "" {
+		*format = logger.DetectFormat(*output)
+	}
+	if *format == "" {
+		*format = loggerStructure.FormatCSV
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+
+	// open the history store
+	store, err := storage.Open(cfg.Storage, paths)
+	if err != nil {
+		log.Println("Error opening the history store:", err)
+		return 1
+	}
+	defer closeStore(store)
+
+	logResult, err := store.Read(filter.From, filter.To)
+	if err != nil {
+		log.Println("Error loading log data:", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Println("Error creating export file:", err)
+			return 1
+		}
+		defer func() {
+			if err := f.Close(); err != nil {
+				log.Println("Error closing export file:", err)
+			}
+		}()
+		w = f
+	}
+
+	count, err := logger.ExportHistory(w, logResult, filter, *format)
+	if err != nil {
+		log.Println("Error exporting history:", err)
+		return 1
+	}
+	if *output != "" {
+		log.Printf("Exported %d record(s) to %s", count, *output)
+	}
+	return 0
+}
+
+// splitList splits a comma-separated flag value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseTimeFlag parses an RFC 3339 time or a local YYYY-MM-DD date.
+// With endOfDay, a date stands for the last second of that day.
+func parseTimeFlag(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 such as 2025-01-02T15:04:05Z or a date such as 2025-01-02", value)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1).Add(-time.Second), nil
+	}
+	return day, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"log"
+	"os"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/logger"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	loggerStructure "github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// importHistory merges history exported as CSV or JSON Lines, e.g. by another monitoring tool, into the log.
+// Entries whose timestamp is already logged are skipped.
+func importHistory(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	format := flags.String("format", "", "csv or jsonl, defaults to the extension of each file")
+	flags.Usage = func() {
+		_, _ = io.WriteString(flags.Output(), "Usage: ponghub import [flags] <file>... (use - for stdin)\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	// read every file before touching the log, so a bad file imports nothing
+	var records loggerStructure.Records
+	for _, path := range flags.Args() {
+		fileFormat := *format
+		if fileFormat == "" {
+			fileFormat = logger.DetectFormat(path)
+		}
+		if fileFormat == "" {
+			log.Println("Cannot tell the format of", path, ": use -format csv or -format jsonl")
+			return 2
+		}
+
+		fileRecords, err := readRecordsFile(path, fileFormat)
+		if err != nil {
+			log.Println("Error reading", path, ":", err)
+			return 1
+		}
+		records = append(records, fileRecords...)
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	if err := createOutputDirs(paths); err != nil {
+		log.Println("Error creating output directories:", err)
+		return 1
+	}
+
+	// open the history store
+	store, err := storage.Open(cfg.Storage, paths)
+	if err != nil {
+		log.Println("Error opening the history store:", err)
+		return 1
+	}
+	defer closeStore(store)
+
+	added, err := logger.ImportHistory(records, cfg, store)
+	if err != nil {
+		log.Println("Error importing history:", err)
+		return 1
+	}
+	log.Printf("Imported %d of %d record(s)", added, len(records))
+	return 0
+}
+
+// readRecordsFile reads the history records of a file, or of stdin if path is -
+func readRecordsFile(path, format string) (loggerStructure.Records, error) {
+	if path == "-" {
+		return logger.ReadRecords(os.Stdin, format)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return logger.ReadRecords(f, format)
+}
@@ -1,49 +1,69 @@
 package main
 
 import (
-	"log"
-
-	"github.com/wcy-dt/ponghub/internal/checker"
-	"github.com/wcy-dt/ponghub/internal/configure"
-	"github.com/wcy-dt/ponghub/internal/logger"
-	"github.com/wcy-dt/ponghub/internal/notifier"
-	"github.com/wcy-dt/ponghub/internal/reporter"
-	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
+	"fmt"
+	"os"
+	"strings"
 )
 
-func main() {
-	// load the default configuration
-	cfg, err := configure.ReadConfigs(default_config.GetConfigPath())
-	if err != nil {
-		log.Fatalln("Error loading config at", default_config.GetConfigPath(), ":", err)
-	}
+// command defines a subcommand of the ponghub binary
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) int
+}
 
-	// check services based on the configuration
-	checkResult := checker.CheckServices(cfg)
+// commands lists every subcommand in the order shown by the usage message
+var commands = []command{
+	{name: "run", usage: "Check all services once, then write logs, notifications and the report (default)", run: run},
+	{name: "serve", usage: "Keep running and check each service on its own interval", run: serve},
+	{name: "check", usage: "Check all services and print the results without writing anything", run: check},
+	{name: "validate", usage: "Validate the configuration file", run: validate},
+	{name: "schema", usage: "Print the JSON Schema of the configuration file", run: schema},
+	{name: "report", usage: "Re-render the HTML report from the existing log without checking services", run: report},
+	{name: "export", usage: "Export the check history as CSV or JSON Lines", run: export},
+	{name: "import", usage: "Merge history from CSV or JSON Lines files into the log", run: importHistory},
+	{name: "announce", usage: "Post, update or list the announcements shown on the status page", run: announce},
+	{name: "ack", usage: "Acknowledge the outages of a service to stop their escalation", run: ack},
+	{name: "notify-test", usage: "Send a sample alert through every configured notification channel", run: notifyTest},
+}
 
-	// notify the result
-	notifier.WriteNotifications(checkResult, cfg.CertNotifyDays)
-	notifier.SendNotifications(checkResult, cfg.CertNotifyDays, cfg.Notifications)
+func main() {
+	// without a subcommand, check all services once as the GitHub Actions workflow expects
+	if len(os.Args) < 2 {
+		os.Exit(run(nil))
+	}
 
-	// get and write log results
-	logResult, err := logger.GetLog(checkResult, cfg.MaxLogDays, default_config.GetLogPath())
-	if err != nil {
-		log.Fatalln("Error outputting checkResult:", err)
+	name, args := os.Args[1], os.Args[2:]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage()
+		return
 	}
-	if err := logger.WriteLog(logResult, default_config.GetLogPath()); err != nil {
-		log.Fatalln("Error writing logs to", default_config.GetLogPath(), ":", err)
-	} else {
-		log.Println("Logs written to", default_config.GetLogPath())
+
+	// flags without a subcommand, e.g. "ponghub --config prod.yaml", belong to run
+	if strings.HasPrefix(name, "-") {
+		os.Exit(run(os.Args[1:]))
 	}
 
-	// generate the report based on the checkResult
-	reportResult, err := reporter.GetReport(checkResult, default_config.GetLogPath(), cfg)
-	if err != nil {
-		log.Fatalln("Error generating report data:", err)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			os.Exit(cmd.run(args))
+		}
 	}
-	if err := reporter.WriteReport(reportResult, default_config.GetReportPath(), cfg.DisplayNum); err != nil {
-		log.Fatalln("Error generating report:", err)
-	} else {
-		log.Println("Report generated at", default_config.GetReportPath())
+
+	_, _ = fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", name)
+	printUsage()
+	os.Exit(2)
+}
+
+// printUsage prints the list of available subcommands
+func printUsage() {
+	_, _ = fmt.Fprintln(os.Stderr, "Usage: ponghub [command] [flags]")
+	_, _ = fmt.Fprintln(os.Stderr)
+	_, _ = fmt.Fprintln(os.Stderr, "Commands:")
+	for _, cmd := range commands {
+		_, _ = fmt.Fprintf(os.Stderr, "  %-12s %s\n", cmd.name, cmd.usage)
 	}
+	_, _ = fmt.Fprintln(os.Stderr)
+	_, _ = fmt.Fprintln(os.Stderr, "Run 'ponghub <command> -h' for the flags of a command.")
 }
@@ -7,11 +7,14 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/wcy-dt/ponghub/internal/announcement"
 	"github.com/wcy-dt/ponghub/internal/checker"
 	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/incident"
 	"github.com/wcy-dt/ponghub/internal/logger"
 	"github.com/wcy-dt/ponghub/internal/notifier"
 	"github.com/wcy-dt/ponghub/internal/reporter"
+	"github.com/wcy-dt/ponghub/internal/storage"
 	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
 )
 
@@ -36,7 +39,7 @@ func runMainFunctionality(copyExistingLog bool) {
 
 	// copy log file to a temporary location for testing (only if copyExistingLog is true)
 	if copyExistingLog {
-		logPath := default_config.GetLogPath()
+		logPath := default_config.GetLogPath(default_config.GetDataDir())
 		if err := copyLogFile(logPath, tmpLogPath); err != nil {
 			log.Fatalln("Error copying log file:", err)
 		}
@@ -45,30 +48,34 @@ func runMainFunctionality(copyExistingLog bool) {
 	// check services based on the configuration
 	checkResult := checker.CheckServices(cfg)
 
-	// notify the result
-	notifier.WriteNotifications(checkResult, cfg.CertNotifyDays)
-	notifier.SendNotifications(checkResult, cfg.CertNotifyDays, cfg.Notifications)
-
 	// get and write log results
-	logResult, err := logger.GetLog(checkResult, cfg.MaxLogDays, tmpLogPath)
+	store := storage.NewJSONStore(tmpLogPath)
+	logResult, err := logger.UpdateLog(checkResult, cfg, store)
 	if err != nil {
-		log.Fatalln("Error outputting checkResult:", err)
-	}
-	if err := logger.WriteLog(logResult, tmpLogPath); err != nil {
 		log.Fatalln("Error writing logs to", tmpLogPath, ":", err)
 	} else {
 		log.Println("Logs written to", tmpLogPath)
 	}
 
+	// notify the result
+	notifier.WriteNotifications(checkResult, cfg.CertNotifyDays, default_config.GetNotifyPath(default_config.GetDataDir()))
+	notifier.SendNotifications(checkResult, logResult, cfg, default_config.GetAlertStatePath(default_config.GetDataDir()), default_config.GetAcksPath(default_config.GetDataDir()))
+	incidents := incident.Track(default_config.GetIncidentsPath(default_config.GetDataDir()), logResult, checkResult, cfg)
+
 	// generate the report based on the checkResult
-	reportResult, err := reporter.GetReport(checkResult, tmpLogPath, cfg)
+	reportResult, err := reporter.GetReport(checkResult, store, cfg)
 	if err != nil {
 		log.Fatalln("Error generating report data:", err)
 	}
-	if err := reporter.WriteReport(reportResult, default_config.GetReportPath(), cfg.DisplayNum); err != nil {
+	reportPath := default_config.GetReportPath(default_config.GetDataDir())
+	announcements, err := announcement.Load(default_config.GetAnnouncementsPath())
+	if err != nil {
+		log.Fatalln("Error loading announcements:", err)
+	}
+	if err := reporter.WriteReport(reportResult, incidents, announcements, reportPath, default_config.GetTemplatePath(), cfg.DisplayNum); err != nil {
 		log.Fatalln("Error generating report:", err)
 	} else {
-		log.Println("Report generated at", default_config.GetReportPath())
+		log.Println("Report generated at", reportPath)
 	}
 
 	// Remove the temporary log file after tests
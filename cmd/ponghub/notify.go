@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	notifierStructure "github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// notifyTest sends a sample alert through every configured notification channel
+func notifyTest(args []string) int {
+	flags := flag.NewFlagSet("notify-test", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	channels := flags.String("channel", "", "comma-separated names of the channels or methods to test, all by default")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+
+	deliveries, err := notifier.SendTestNotification(cfg.Notifications, splitList(*channels)...)
+	if printErr := printDeliveries(deliveries); printErr != nil {
+		log.Println("Error printing delivery results:", printErr)
+	}
+	if err != nil {
+		log.Println("Error sending test notification:", err)
+		return 1
+	}
+	log.Println("Test notification sent")
+	return 0
+}
+
+// printDeliveries prints the outcome of the delivery through each channel as an aligned table
+func printDeliveries(deliveries []notifierStructure.Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CHANNEL\tTYPE\tRESULT\tTIME")
+	for _, delivery := range deliveries {
+		result := "delivered"
+		if delivery.Err != nil {
+			result = "failed: " + delivery.Err.Error()
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", delivery.Channel, delivery.Type, result, delivery.Elapsed.Round(1e6))
+	}
+	return w.Flush()
+}
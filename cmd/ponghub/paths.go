@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
+)
+
+// pathFlags holds the path flags shared by every subcommand
+type pathFlags struct {
+	config        *string
+	dataDir       *string
+	template      *string
+	log           *string
+	database      *string
+	report        *string
+	notify        *string
+	state         *string
+	acks          *string
+	incidents     *string
+	static        *string
+	announcements *string
+}
+
+// addPathFlags registers the path flags on flags.
+// Each flag falls back to its PONGHUB_* environment variable, then to the built-in default.
+func addPathFlags(flags *flag.FlagSet) *pathFlags {
+	return &pathFlags{
+		config:    flags.String("config", getEnv("PONGHUB_CONFIG", default_config.GetConfigPath()), "path to the configuration file (env PONGHUB_CONFIG)"),
+		dataDir:   flags.String("data-dir", getEnv("PONGHUB_DATA_DIR", default_config.GetDataDir()), "directory for the log, report and notification files (env PONGHUB_DATA_DIR)"),
+		template:  flags.String("template", getEnv("PONGHUB_TEMPLATE", default_config.GetTemplatePath()), "path to the HTML report template (env PONGHUB_TEMPLATE)"),
+		log:       flags.String("log", os.Getenv("PONGHUB_LOG"), "path to the JSON log file, defaults to ponghub_log.json in the data directory (env PONGHUB_LOG)"),
+		database:  flags.String("database", os.Getenv("PONGHUB_DATABASE"), "path to the SQLite database used by the sqlite storage, defaults to ponghub.db in the data directory (env PONGHUB_DATABASE)"),
+		report:    flags.String("report", os.Getenv("PONGHUB_REPORT"), "path to the HTML report, defaults to index.html in the data directory (env PONGHUB_REPORT)"),
+		notify:    flags.String("notify", os.Getenv("PONGHUB_NOTIFY"), "path to the notification file, defaults to notify.txt in the data directory (env PONGHUB_NOTIFY)"),
+		state:     flags.String("state", os.Getenv("PONGHUB_STATE"), "path to the alerting state file, defaults to alert_state.json in the data directory (env PONGHUB_STATE)"),
+		acks:      flags.String("acks", os.Getenv("PONGHUB_ACKS"), "path to the acknowledgements file, defaults to acknowledgements.json in the data directory (env PONGHUB_ACKS)"),
+		incidents: flags.String("incidents", os.Getenv("PONGHUB_INCIDENTS"), "path to the incidents file, defaults to incidents.json in the data directory (env PONGHUB_INCIDENTS)"),
+		static:    flags.String("static", getEnv("PONGHUB_STATIC", default_config.GetStaticPath()), "directory of static assets served with the report (env PONGHUB_STATIC)"),
+
+		announcements: flags.String("announcements", getEnv("PONGHUB_ANNOUNCEMENTS", default_config.GetAnnouncementsPath()), "directory of the announcements shown in the report (env PONGHUB_ANNOUNCEMENTS)"),
+	}
+}
+
+// resolve returns the paths selected by the flags, placing unset data files in the data directory
+func (p *pathFlags) resolve() configure.Paths {
+	paths := configure.Paths{
+		Config:        *p.config,
+		Log:           *p.log,
+		Database:      *p.database,
+		Report:        *p.report,
+		Template:      *p.template,
+		Notify:        *p.notify,
+		State:         *p.state,
+		Acks:          *p.acks,
+		Incidents:     *p.incidents,
+		Static:        *p.static,
+		Announcements: *p.announcements,
+	}
+	if paths.Log == "" {
+		paths.Log = default_config.GetLogPath(*p.dataDir)
+	}
+	if paths.Database == "" {
+		paths.Database = default_config.GetDatabasePath(*p.dataDir)
+	}
+	if paths.Report == "" {
+		paths.Report = default_config.GetReportPath(*p.dataDir)
+	}
+	if paths.Notify == "" {
+		paths.Notify = default_config.GetNotifyPath(*p.dataDir)
+	}
+	if paths.State == "" {
+		paths.State = default_config.GetAlertStatePath(*p.dataDir)
+	}
+	if paths.Acks == "" {
+		paths.Acks = default_config.GetAcksPath(*p.dataDir)
+	}
+	if paths.Incidents == "" {
+		paths.Incidents = default_config.GetIncidentsPath(*p.dataDir)
+	}
+	return paths
+}
+
+// createOutputDirs creates the directories of the files written by PongHub
+func createOutputDirs(paths configure.Paths) error {
+	for _, path := range []string{paths.Log, paths.Database, paths.Report, paths.Notify, paths.State, paths.Acks, paths.Incidents} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// closeStore closes the history store, logging any error
+func closeStore(store storage.Store) {
+	if err := store.Close(); err != nil {
+		log.Println("Error closing the history store:", err)
+	}
+}
+
+// getEnv returns the value of the environment variable key, or fallback if it is unset or empty
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
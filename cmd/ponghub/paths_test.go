@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePaths(t *testing.T) {
+	t.Setenv("PONGHUB_CONFIG", "env.yaml")
+	t.Setenv("PONGHUB_REPORT", "")
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	pathFlag := addPathFlags(flags)
+	if err := flags.Parse([]string{"--data-dir", "staging", "--log", "custom/log.json"}); err != nil {
+		t.Fatalf("Expected no error parsing flags, got %v", err)
+	}
+	paths := pathFlag.resolve()
+
+	if paths.Config != "env.yaml" {
+		t.Errorf("Expected config path from environment, got %s", paths.Config)
+	}
+	if paths.Log != "custom/log.json" {
+		t.Errorf("Expected explicit log path to win over the data directory, got %s", paths.Log)
+	}
+	if want := filepath.Join("staging", "index.html"); paths.Report != want {
+		t.Errorf("Expected report path %s, got %s", want, paths.Report)
+	}
+	if want := filepath.Join("staging", "ponghub.db"); paths.Database != want {
+		t.Errorf("Expected database path %s, got %s", want, paths.Database)
+	}
+	if want := filepath.Join("staging", "notify.txt"); paths.Notify != want {
+		t.Errorf("Expected notify path %s, got %s", want, paths.Notify)
+	}
+	if want := filepath.Join("staging", "alert_state.json"); paths.State != want {
+		t.Errorf("Expected alert state path %s, got %s", want, paths.State)
+	}
+	if want := filepath.Join("staging", "acknowledgements.json"); paths.Acks != want {
+		t.Errorf("Expected acknowledgements path %s, got %s", want, paths.Acks)
+	}
+	if want := filepath.Join("staging", "incidents.json"); paths.Incidents != want {
+		t.Errorf("Expected incidents path %s, got %s", want, paths.Incidents)
+	}
+	if paths.Announcements != "announcements" {
+		t.Errorf("Expected the announcements directory to stay outside the data directory, got %s", paths.Announcements)
+	}
+
+	// flags take precedence over environment variables
+	flags = flag.NewFlagSet("test", flag.ContinueOnError)
+	pathFlag = addPathFlags(flags)
+	if err := flags.Parse([]string{"--config", "flag.yaml"}); err != nil {
+		t.Fatalf("Expected no error parsing flags, got %v", err)
+	}
+	if paths := pathFlag.resolve(); paths.Config != "flag.yaml" {
+		t.Errorf("Expected config path from flag, got %s", paths.Config)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/wcy-dt/ponghub/internal/announcement"
+	"github.com/wcy-dt/ponghub/internal/checker"
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/incident"
+	"github.com/wcy-dt/ponghub/internal/reporter"
+	"github.com/wcy-dt/ponghub/internal/storage"
+)
+
+// report re-renders the HTML report from the existing log without checking any service.
+// Certificate status is unknown without a check, so it is shown as unavailable.
+func report(args []string) int {
+	flags := flag.NewFlagSet("report", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	if err := createOutputDirs(paths); err != nil {
+		log.Println("Error creating output directories:", err)
+		return 1
+	}
+
+	// open the history store
+	store, err := storage.Open(cfg.Storage, paths)
+	if err != nil {
+		log.Println("Error opening the history store:", err)
+		return 1
+	}
+	defer closeStore(store)
+
+	reportResult, err := reporter.GetReport(checker.DescribeServices(cfg), store, cfg)
+	if err != nil {
+		log.Println("Error generating report data:", err)
+		return 1
+	}
+	incidents, err := incident.Load(paths.Incidents)
+	if err != nil {
+		log.Println("Error loading incidents:", err)
+	}
+	announcements, err := announcement.Load(paths.Announcements)
+	if err != nil {
+		log.Println("Error loading announcements:", err)
+	}
+	if err := reporter.WriteReport(reportResult, incidents, announcements, paths.Report, paths.Template, cfg.DisplayNum); err != nil {
+		log.Println("Error generating report:", err)
+		return 1
+	}
+	log.Println("Report generated at", paths.Report)
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/wcy-dt/ponghub/internal/announcement"
+	"github.com/wcy-dt/ponghub/internal/checker"
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/incident"
+	"github.com/wcy-dt/ponghub/internal/logger"
+	"github.com/wcy-dt/ponghub/internal/metrics"
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	"github.com/wcy-dt/ponghub/internal/reporter"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/telemetry"
+)
+
+// run checks every service a single time, which is how the GitHub Actions workflow runs PongHub
+func run(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	if err := createOutputDirs(paths); err != nil {
+		log.Println("Error creating output directories:", err)
+		return 1
+	}
+
+	// open the history store
+	store, err := storage.Open(cfg.Storage, paths)
+	if err != nil {
+		log.Println("Error opening the history store:", err)
+		return 1
+	}
+	defer closeStore(store)
+
+	// check services based on the configuration
+	checkResult := checker.CheckServices(cfg)
+
+	// export probe results to the OpenTelemetry collector
+	if err := telemetry.NewExporter(cfg.Telemetry).Export(checkResult); err != nil {
+		log.Println("Error exporting telemetry:", err)
+	}
+
+	// get and write log results
+	logResult, err := logger.UpdateLog(checkResult, cfg, store)
+	if err != nil {
+		log.Println("Error updating logs:", err)
+		return 1
+	}
+	log.Println("Logs written to the", cfg.Storage.Type, "store")
+
+	// notify the result, comparing with the previous state of every endpoint
+	notifier.WriteNotifications(checkResult, cfg.CertNotifyDays, paths.Notify)
+	notifier.SendNotifications(checkResult, logResult, cfg, paths.State, paths.Acks)
+
+	// track the incidents found in the history
+	incidents := incident.Track(paths.Incidents, logResult, checkResult, cfg)
+
+	// generate the report based on the checkResult
+	reportResult, err := reporter.GetReport(checkResult, store, cfg)
+	if err != nil {
+		log.Println("Error generating report data:", err)
+		return 1
+	}
+	announcements, err := announcement.Load(paths.Announcements)
+	if err != nil {
+		log.Println("Error loading announcements:", err)
+	}
+	if err := reporter.WriteReport(reportResult, incidents, announcements, paths.Report, paths.Template, cfg.DisplayNum); err != nil {
+		log.Println("Error generating report:", err)
+		return 1
+	}
+	log.Println("Report generated at", paths.Report)
+
+	// export metrics for the node_exporter textfile collector
+	if cfg.MetricsFile != "" {
+		if err := metrics.WriteFile(cfg.MetricsFile, checkResult, reportResult); err != nil {
+			log.Println("Error writing metrics to", cfg.MetricsFile, ":", err)
+			return 1
+		}
+		log.Println("Metrics written to", cfg.MetricsFile)
+	}
+
+	return 0
+}
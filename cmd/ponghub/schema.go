@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+)
+
+// schema prints the JSON Schema of the configuration file, or writes it to the file given by -o
+func schema(args []string) int {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := flags.String("o", "", "write the schema to this file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	if *output == "" {
+		if err := configure.WriteSchema(os.Stdout); err != nil {
+			log.Println("Error writing schema:", err)
+			return 1
+		}
+		return 0
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		log.Println("Error creating schema file:", err)
+		return 1
+	}
+	if err := configure.WriteSchema(f); err != nil {
+		_ = f.Close()
+		log.Println("Error writing schema to", *output, ":", err)
+		return 1
+	}
+	if err := f.Close(); err != nil {
+		log.Println("Error closing schema file:", err)
+		return 1
+	}
+	log.Println("Schema written to", *output)
+	return 0
+}
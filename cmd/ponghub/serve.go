@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	"github.com/wcy-dt/ponghub/internal/daemon"
+	"github.com/wcy-dt/ponghub/internal/server"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	configureStructure "github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// serve keeps PongHub running and checks every service on its own interval until SIGINT or SIGTERM
+func serve(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	listen := flags.String("listen", "", "address to serve the report and JSON API on, e.g. :8080 (disabled if empty)")
+	ackToken := flags.String("ack-token", os.Getenv("PONGHUB_ACK_TOKEN"), "bearer token required to acknowledge outages through the API, which is disabled if empty (env PONGHUB_ACK_TOKEN)")
+	watchInterval := flags.Duration("watch-interval", 5*time.Second, "how often to check the config files for changes (0 disables hot reload)")
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	// load the configuration
+	cfg, err := configure.ReadConfigs(paths.Config)
+	if err != nil {
+		log.Println("Error loading config at", paths.Config, ":", err)
+		return 1
+	}
+	if err := createOutputDirs(paths); err != nil {
+		log.Println("Error creating output directories:", err)
+		return 1
+	}
+
+	// open the history store
+	store, err := storage.Open(cfg.Storage, paths)
+	if err != nil {
+		log.Println("Error opening the history store:", err)
+		return 1
+	}
+	defer closeStore(store)
+
+	// stop gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	d := daemon.New(cfg, paths, store)
+
+	var wg sync.WaitGroup
+	if *listen != "" {
+		srv := server.New(*listen, paths.Report, paths.Static, store)
+		if *ackToken != "" {
+			srv.EnableAcks(paths.Acks, *ackToken)
+		}
+		d.OnReport(srv.Update)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.ListenAndServe(ctx); err != nil {
+				log.Println("HTTP server exited with error:", err)
+				stop()
+			}
+		}()
+	}
+
+	if *watchInterval > 0 {
+		go watchConfig(ctx, paths.Config, cfg, *watchInterval, d)
+	}
+
+	if err := d.Run(ctx); err != nil {
+		log.Println("Daemon exited with error:", err)
+		wg.Wait()
+		return 1
+	}
+	wg.Wait()
+	return 0
+}
+
+// watchConfig reloads the configuration into the daemon whenever its files change.
+// Invalid configurations are logged and ignored, so the daemon keeps running with the last valid one.
+func watchConfig(ctx context.Context, path string, cfg *configureStructure.Configure, interval time.Duration, d *daemon.Daemon) {
+	watcher := configure.NewWatcher(path, cfg)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !watcher.Changed() {
+			continue
+		}
+
+		newCfg, err := configure.ReadConfigs(path)
+		if err != nil {
+			log.Println("Config changed but is invalid, keeping the current configuration:", err)
+			watcher.Update(nil)
+			continue
+		}
+		watcher.Update(newCfg)
+		d.Reload(newCfg)
+	}
+}
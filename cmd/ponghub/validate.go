@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/wcy-dt/ponghub/internal/configure"
+	configureStructure "github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// validate loads the configuration file and reports whether it is valid
+func validate(args []string) int {
+	flags := flag.NewFlagSet("validate", flag.ExitOnError)
+	pathFlag := addPathFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		log.Println("Error parsing flags:", err)
+		return 2
+	}
+
+	paths := pathFlag.resolve()
+
+	cfg, err := configure.ReadConfigs(paths.Config)
+	var validationErrs configureStructure.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		for _, validationErr := range validationErrs {
+			_, _ = fmt.Fprintln(os.Stderr, validationErr.Error())
+		}
+		_, _ = fmt.Fprintf(os.Stderr, "%s is invalid: %d problem(s) found\n", paths.Config, len(validationErrs))
+		return 1
+	}
+	if err != nil {
+		log.Println("Invalid config at", paths.Config, ":", err)
+		return 1
+	}
+
+	endpointNum := 0
+	for _, service := range cfg.Services {
+		endpointNum += len(service.Endpoints)
+	}
+	fmt.Printf("%s is valid: %d services, %d endpoints in %d file(s)\n", paths.Config, len(cfg.Services), endpointNum, len(cfg.Sources))
+	return 0
+}
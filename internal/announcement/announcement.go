@@ -0,0 +1,301 @@
+package announcement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/announcement"
+	"gopkg.in/yaml.v3"
+)
+
+// resolvedDays is how long a resolved announcement stays on the status page after its last update
+const resolvedDays = 7
+
+// frontMatterDelimiter opens and closes the YAML front matter of a Markdown announcement
+const frontMatterDelimiter = "---"
+
+// supportedStatuses lists the statuses an update may have
+var supportedStatuses = []string{
+	announcement.StatusInvestigating,
+	announcement.StatusIdentified,
+	announcement.StatusMonitoring,
+	announcement.StatusResolved,
+	announcement.StatusNotice,
+}
+
+// slugPattern matches the runs of characters left out of the ids derived from titles
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// markdownFile is the front matter of a Markdown announcement, whose body is the message of its latest update
+type markdownFile struct {
+	Title    string                `yaml:"title"`
+	Services []string              `yaml:"services,omitempty"`
+	Status   string                `yaml:"status"`
+	Time     string                `yaml:"time"`
+	Updates  []announcement.Update `yaml:"updates,omitempty"` // the updates before the latest one
+}
+
+// Load reads the announcements in the YAML and Markdown files of dir, from the most recently updated to the oldest.
+// A missing directory holds none. Invalid files are left out and reported together in the error.
+func Load(dir string) (announcement.Announcements, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var announcements announcement.Announcements
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !isAnnouncementFile(entry.Name()) {
+			continue
+		}
+		a, err := readFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		announcements = append(announcements, a)
+	}
+	sortByLatest(announcements)
+	return announcements, errors.Join(errs...)
+}
+
+// Post creates an announcement in dir with its first update, and returns it.
+// Its id is derived from the date and the title.
+func Post(dir, title string, services []string, status, message string, now time.Time) (announcement.Announcement, error) {
+	a := announcement.Announcement{
+		Title:    title,
+		Services: services,
+		Updates:  []announcement.Update{{Time: now.Format(time.RFC3339), Status: status, Message: message}},
+	}
+	if err := check(a); err != nil {
+		return a, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return a, err
+	}
+
+	slug := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	base := strings.TrimSuffix(now.Format("2006-01-02")+"-"+slug, "-")
+	a.ID = base
+	for i := 2; findFile(dir, a.ID) != ""; i++ {
+		a.ID = fmt.Sprintf("%s-%d", base, i)
+	}
+	return a, writeFile(filepath.Join(dir, a.ID+".yaml"), a)
+}
+
+// AddUpdate adds an update to the announcement with the given id in dir, keeping the format of its file, and returns it.
+// An empty status keeps the status of the latest update.
+func AddUpdate(dir, id, status, message string, now time.Time) (announcement.Announcement, error) {
+	path := findFile(dir, id)
+	if path == "" {
+		return announcement.Announcement{}, fmt.Errorf("unknown announcement %q", id)
+	}
+	a, err := readFile(path)
+	if err != nil {
+		return a, err
+	}
+	if status == "" {
+		status = a.Status()
+	}
+	a.Updates = append(a.Updates, announcement.Update{Time: now.Format(time.RFC3339), Status: status, Message: message})
+	if err := check(a); err != nil {
+		return a, err
+	}
+	return a, writeFile(path, a)
+}
+
+// Visible returns the announcements shown on the status page at now:
+// those that are not resolved, and those resolved within the last resolvedDays days
+func Visible(announcements announcement.Announcements, now time.Time) announcement.Announcements {
+	cutoff := now.AddDate(0, 0, -resolvedDays)
+	var visible announcement.Announcements
+	for _, a := range announcements {
+		latest := a.Latest()
+		if latest.Status == announcement.StatusResolved {
+			if updated, err := time.Parse(time.RFC3339, latest.Time); err != nil || updated.Before(cutoff) {
+				continue
+			}
+		}
+		visible = append(visible, a)
+	}
+	return visible
+}
+
+// ByService groups the announcements that are not resolved by the services they are attached to
+func ByService(announcements announcement.Announcements) map[string]announcement.Announcements {
+	byService := make(map[string]announcement.Announcements)
+	for _, a := range announcements {
+		if a.Status() == announcement.StatusResolved {
+			continue
+		}
+		for _, service := range a.Services {
+			byService[service] = append(byService[service], a)
+		}
+	}
+	return byService
+}
+
+// isAnnouncementFile reports whether name is a YAML or Markdown file
+func isAnnouncementFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".yaml", ".yml", ".md":
+		return true
+	}
+	return false
+}
+
+// isMarkdown reports whether path is a Markdown announcement
+func isMarkdown(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".md")
+}
+
+// findFile returns the path of the file of the announcement with the given id in dir, or an empty string if there is none
+func findFile(dir, id string) string {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return ""
+	}
+	for _, ext := range []string{".yaml", ".yml", ".md"} {
+		path := filepath.Join(dir, id+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// readFile reads and checks the announcement in the file at path
+func readFile(path string) (announcement.Announcement, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return announcement.Announcement{}, err
+	}
+
+	var a announcement.Announcement
+	if isMarkdown(path) {
+		a, err = parseMarkdown(content)
+	} else {
+		err = yaml.Unmarshal(content, &a)
+	}
+	if err != nil {
+		return a, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	a.ID = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := check(a); err != nil {
+		return a, fmt.Errorf("invalid announcement %s: %w", path, err)
+	}
+	return a, nil
+}
+
+// parseMarkdown parses a Markdown announcement: YAML front matter followed by the message of the latest update
+func parseMarkdown(content []byte) (announcement.Announcement, error) {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	rest, found := strings.CutPrefix(text, frontMatterDelimiter+"\n")
+	if !found {
+		return announcement.Announcement{}, fmt.Errorf("missing front matter")
+	}
+	frontMatter, body, found := strings.Cut(rest, "\n"+frontMatterDelimiter+"\n")
+	if !found {
+		frontMatter, found = strings.CutSuffix(rest, "\n"+frontMatterDelimiter)
+		if !found {
+			return announcement.Announcement{}, fmt.Errorf("unterminated front matter")
+		}
+	}
+
+	var file markdownFile
+	if err := yaml.Unmarshal([]byte(frontMatter), &file); err != nil {
+		return announcement.Announcement{}, err
+	}
+	a := announcement.Announcement{Title: file.Title, Services: file.Services, Updates: file.Updates}
+	if body = strings.TrimSpace(body); body != "" || file.Status != "" {
+		a.Updates = append(a.Updates, announcement.Update{Time: file.Time, Status: file.Status, Message: body})
+	}
+	return a, nil
+}
+
+// writeFile writes the announcement to the file at path, as YAML or as Markdown depending on its extension
+func writeFile(path string, a announcement.Announcement) error {
+	var content []byte
+	if isMarkdown(path) {
+		latest := a.Latest()
+		frontMatter, err := marshalYAML(markdownFile{
+			Title:    a.Title,
+			Services: a.Services,
+			Status:   latest.Status,
+			Time:     latest.Time,
+			Updates:  a.Updates[:len(a.Updates)-1],
+		})
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		buf.WriteString(frontMatterDelimiter + "\n")
+		buf.Write(frontMatter)
+		buf.WriteString(frontMatterDelimiter + "\n\n")
+		buf.WriteString(strings.TrimSpace(latest.Message) + "\n")
+		content = buf.Bytes()
+	} else {
+		var err error
+		if content, err = marshalYAML(a); err != nil {
+			return err
+		}
+	}
+	return common.WriteFileAtomic(path, content, 0644)
+}
+
+// marshalYAML encodes v as YAML indented by two spaces, as announcements are written by hand
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// check reports the first problem of an announcement: a missing title, no update, or an update
+// with an invalid time or an unsupported status
+func check(a announcement.Announcement) error {
+	if strings.TrimSpace(a.Title) == "" {
+		return fmt.Errorf("missing title")
+	}
+	if len(a.Updates) == 0 {
+		return fmt.Errorf("no updates")
+	}
+	for i, update := range a.Updates {
+		if _, err := time.Parse(time.RFC3339, update.Time); err != nil {
+			return fmt.Errorf("update %d: invalid time %q: expected an RFC 3339 time", i+1, update.Time)
+		}
+		if !slices.Contains(supportedStatuses, update.Status) {
+			return fmt.Errorf("update %d: unsupported status %q, expected one of %s", i+1, update.Status, strings.Join(supportedStatuses, ", "))
+		}
+	}
+	return nil
+}
+
+// sortByLatest sorts announcements from the most recently updated to the oldest, then by id
+func sortByLatest(announcements announcement.Announcements) {
+	slices.SortStableFunc(announcements, func(a, b announcement.Announcement) int {
+		aTime, _ := time.Parse(time.RFC3339, a.Latest().Time)
+		bTime, _ := time.Parse(time.RFC3339, b.Latest().Time)
+		if c := bTime.Compare(aTime); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+}
@@ -0,0 +1,136 @@
+package announcement
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/announcement"
+)
+
+// writeTestFile writes content to the file name in dir
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Expected no error writing %s, got %v", name, err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "api-errors.yaml", `title: Elevated errors on API
+services: [API]
+updates:
+  - time: 2025-01-01T10:00:00Z
+    status: investigating
+    message: We are looking into it.
+  - time: 2025-01-01T11:00:00Z
+    status: monitoring
+    message: A fix has been deployed.
+`)
+	writeTestFile(t, dir, "upgrade.md", `---
+title: Database upgrade
+status: notice
+time: 2025-01-02T08:00:00Z
+---
+
+The database is upgraded on **Sunday**.
+`)
+	writeTestFile(t, dir, "broken.yml", "title: No updates\n")
+	writeTestFile(t, dir, "notes.txt", "not an announcement")
+
+	announcements, err := Load(dir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yml") {
+		t.Errorf("Expected an error for broken.yml, got %v", err)
+	}
+	if len(announcements) != 2 {
+		t.Fatalf("Expected the 2 valid announcements, got %+v", announcements)
+	}
+
+	upgrade, apiErrors := announcements[0], announcements[1]
+	if upgrade.ID != "upgrade" || upgrade.Status() != announcement.StatusNotice || upgrade.Latest().Message != "The database is upgraded on **Sunday**." {
+		t.Errorf("Expected the Markdown announcement to be updated last, got %+v", upgrade)
+	}
+	if apiErrors.ID != "api-errors" || len(apiErrors.Updates) != 2 || apiErrors.Status() != announcement.StatusMonitoring || apiErrors.Services[0] != "API" {
+		t.Errorf("Expected the YAML announcement with its 2 updates, got %+v", apiErrors)
+	}
+
+	if announcements, err := Load(filepath.Join(dir, "missing")); err != nil || len(announcements) != 0 {
+		t.Errorf("Expected no announcements without a directory, got %+v, %v", announcements, err)
+	}
+}
+
+func TestPostAndAddUpdate(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	first, err := Post(dir, "Elevated errors on API!", []string{"API"}, announcement.StatusInvestigating, "Looking into it.", now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if first.ID != "2025-01-01-elevated-errors-on-api" {
+		t.Errorf("Expected an id derived from the date and the title, got %s", first.ID)
+	}
+	second, err := Post(dir, "Elevated errors on API", nil, announcement.StatusNotice, "Another one.", now)
+	if err != nil || second.ID != "2025-01-01-elevated-errors-on-api-2" {
+		t.Errorf("Expected a numbered id for the same title, got %s, %v", second.ID, err)
+	}
+	if _, err := Post(dir, "Bad status", nil, "fixed", "Done.", now); err == nil {
+		t.Error("Expected an error for an unsupported status")
+	}
+
+	updated, err := AddUpdate(dir, first.ID, "", "Still looking.", now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(updated.Updates) != 2 || updated.Status() != announcement.StatusInvestigating {
+		t.Errorf("Expected the update to keep the status, got %+v", updated)
+	}
+	if _, err := AddUpdate(dir, "missing", announcement.StatusResolved, "Done.", now); err == nil {
+		t.Error("Expected an error for an unknown announcement")
+	}
+
+	// a Markdown announcement stays in Markdown, with the latest message as its body
+	writeTestFile(t, dir, "upgrade.md", "---\ntitle: Upgrade\nstatus: notice\ntime: 2025-01-01T08:00:00Z\n---\nStarting.\n")
+	if _, err := AddUpdate(dir, "upgrade", announcement.StatusResolved, "Done.", now); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "upgrade.md"))
+	if err != nil || !strings.HasSuffix(string(content), "---\n\nDone.\n") {
+		t.Errorf("Expected the latest message as the body, got %q, %v", content, err)
+	}
+
+	announcements, err := Load(dir)
+	if err != nil || len(announcements) != 3 {
+		t.Fatalf("Expected 3 announcements, got %+v, %v", announcements, err)
+	}
+	if announcements[0].ID != first.ID {
+		t.Errorf("Expected the most recently updated announcement first, got %s", announcements[0].ID)
+	}
+	for _, a := range announcements {
+		if a.ID == "upgrade" && (len(a.Updates) != 2 || a.Updates[0].Message != "Starting." || a.Status() != announcement.StatusResolved) {
+			t.Errorf("Expected the Markdown announcement to keep both updates, got %+v", a)
+		}
+	}
+}
+
+func TestVisibleAndByService(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	announcements := announcement.Announcements{
+		{ID: "ongoing", Services: []string{"API", "Website"}, Updates: []announcement.Update{{Time: "2025-01-01T00:00:00Z", Status: announcement.StatusInvestigating}}},
+		{ID: "recent", Services: []string{"API"}, Updates: []announcement.Update{{Time: "2025-01-08T00:00:00Z", Status: announcement.StatusResolved}}},
+		{ID: "old", Services: []string{"API"}, Updates: []announcement.Update{{Time: "2025-01-01T00:00:00Z", Status: announcement.StatusResolved}}},
+	}
+
+	visible := Visible(announcements, now)
+	if len(visible) != 2 || visible[0].ID != "ongoing" || visible[1].ID != "recent" {
+		t.Errorf("Expected the ongoing and recently resolved announcements, got %+v", visible)
+	}
+
+	byService := ByService(visible)
+	if len(byService["API"]) != 1 || byService["API"][0].ID != "ongoing" || len(byService["Website"]) != 1 {
+		t.Errorf("Expected only the unresolved announcement attached to each service, got %+v", byService)
+	}
+}
@@ -0,0 +1,169 @@
+package announcement
+
+import (
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+var (
+	// headingPattern matches the marker of a heading
+	headingPattern = regexp.MustCompile(`^#{1,6}\s+`)
+
+	// listItemPattern matches the marker of a bullet or numbered list item
+	listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|(\d+)[.)])\s+`)
+
+	// inline patterns, applied to escaped text outside code spans
+	boldPattern = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	linkPattern = regexp.MustCompile(`\[([^\]]+)\]\(((?:https?://|mailto:)[^\s)]+)\)`)
+)
+
+// RenderMarkdown renders the subset of Markdown used by announcements as HTML: paragraphs, bullet and numbered lists,
+// headings, bold, italics, code spans and http, https and mailto links. Any HTML in text is escaped.
+func RenderMarkdown(text string) template.HTML {
+	var out strings.Builder
+	for _, block := range splitBlocks(text) {
+		lines := strings.Split(block, "\n")
+		if marker := headingPattern.FindString(lines[0]); marker != "" {
+			// headings are demoted below the headings of the report
+			out.WriteString("<h4>" + renderInline(lines[0][len(marker):]) + "</h4>")
+			if lines = lines[1:]; len(lines) == 0 {
+				continue
+			}
+		}
+		switch {
+		case isList(lines):
+			tag := "ul"
+			if listItemPattern.FindStringSubmatch(lines[0])[1] != "" {
+				tag = "ol"
+			}
+			out.WriteString("<" + tag + ">")
+			for _, item := range listItems(lines) {
+				out.WriteString("<li>" + renderInline(item) + "</li>")
+			}
+			out.WriteString("</" + tag + ">")
+		default:
+			out.WriteString("<p>" + renderInline(strings.Join(lines, "\n")) + "</p>")
+		}
+	}
+	return template.HTML(out.String())
+}
+
+// splitBlocks splits text into the blocks separated by blank lines
+func splitBlocks(text string) []string {
+	var blocks, current []string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, strings.TrimRight(line, " \t"))
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// isList reports whether a block is a list, i.e. starts with a list item
+func isList(lines []string) bool {
+	return listItemPattern.MatchString(lines[0])
+}
+
+// listItems returns the text of each item of a list, joining the lines that continue an item
+func listItems(lines []string) []string {
+	var items []string
+	for _, line := range lines {
+		if marker := listItemPattern.FindString(line); marker != "" {
+			items = append(items, line[len(marker):])
+			continue
+		}
+		items[len(items)-1] += "\n" + strings.TrimSpace(line)
+	}
+	return items
+}
+
+// renderInline escapes text and renders its code spans, bold and italic text and links
+func renderInline(text string) string {
+	var out strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			out.WriteString("<code>" + template.HTMLEscapeString(part) + "</code>")
+		case i%2 == 1:
+			// an unmatched backtick is kept as is
+			out.WriteString("`" + renderEmphasis(part))
+		default:
+			out.WriteString(renderEmphasis(part))
+		}
+	}
+	return out.String()
+}
+
+// renderEmphasis escapes text and renders its bold and italic text and links.
+// Emphasis is rendered in the text and the labels of links, never in their addresses.
+func renderEmphasis(text string) string {
+	escaped := template.HTMLEscapeString(text)
+	var out strings.Builder
+	last := 0
+	for _, match := range linkPattern.FindAllStringSubmatchIndex(escaped, -1) {
+		out.WriteString(renderBoldItalic(escaped[last:match[0]]))
+		out.WriteString(`<a href="` + escaped[match[4]:match[5]] + `" rel="noopener">` + renderBoldItalic(escaped[match[2]:match[3]]) + "</a>")
+		last = match[1]
+	}
+	out.WriteString(renderBoldItalic(escaped[last:]))
+	return out.String()
+}
+
+// renderBoldItalic renders the bold and italic text of escaped text
+func renderBoldItalic(escaped string) string {
+	return renderItalics(boldPattern.ReplaceAllString(escaped, "<strong>$1</strong>"))
+}
+
+// renderItalics renders the text between single asterisks or underscores as italics. The delimiters must not touch
+// a word character or another asterisk on their outer side, so that snake_case_name is left as is, and they are
+// checked without being consumed, so that adjacent italics such as *a* *b* are all rendered.
+func renderItalics(text string) string {
+	var out strings.Builder
+	for i := 0; i < len(text); i++ {
+		if end := italicEnd(text, i); end > 0 {
+			out.WriteString("<em>" + text[i+1:end] + "</em>")
+			i = end
+			continue
+		}
+		out.WriteByte(text[i])
+	}
+	return out.String()
+}
+
+// italicEnd returns the position of the delimiter closing the italics opened at position i of text, or 0 if none
+// is opened there. Italics do not span lines and neither start nor end with a space.
+func italicEnd(text string, i int) int {
+	delimiter := text[i]
+	if delimiter != '*' && delimiter != '_' {
+		return 0
+	}
+	if i > 0 && isWordOrStar(text[i-1]) || i+1 >= len(text) || isSpace(text[i+1]) {
+		return 0
+	}
+	for end := i + 1; end < len(text) && text[end] != '\n'; end++ {
+		if text[end] == delimiter && !isSpace(text[end-1]) && (end+1 == len(text) || !isWordOrStar(text[end+1])) {
+			return end
+		}
+	}
+	return 0
+}
+
+// isWordOrStar reports whether b is an ASCII letter, digit or underscore, or an asterisk
+func isWordOrStar(b byte) bool {
+	return b == '*' || b == '_' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// isSpace reports whether b is an ASCII white space character
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f' || b == '\v'
+}
@@ -0,0 +1,32 @@
+package announcement
+
+import "testing"
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"paragraphs", "First line\nsame paragraph\n\nSecond", "<p>First line\nsame paragraph</p><p>Second</p>"},
+		{"escape", `<script>alert("x")</script>`, "<p>&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;</p>"},
+		{"emphasis", "**Fixed** in _v2_ and *v3*, not snake_case_name", "<p><strong>Fixed</strong> in <em>v2</em> and <em>v3</em>, not snake_case_name</p>"},
+		{"adjacent italics", "*a* *b* and _c_ _d_", "<p><em>a</em> <em>b</em> and <em>c</em> <em>d</em></p>"},
+		{"emphasis in link", "[*docs*](https://h/_a_/b_c_) and _d_", `<p><a href="https://h/_a_/b_c_" rel="noopener"><em>docs</em></a> and <em>d</em></p>`},
+		{"code", "Run `a **b** <c>` now", "<p>Run <code>a **b** &lt;c&gt;</code> now</p>"},
+		{"link", "See [the post](https://example.com/?a=1&b=2)", `<p>See <a href="https://example.com/?a=1&amp;b=2" rel="noopener">the post</a></p>`},
+		{"unsafe link", "[click](javascript:alert(1))", "<p>[click](javascript:alert(1))</p>"},
+		{"bullet list", "- one\n- two\n  continued", "<ul><li>one</li><li>two\ncontinued</li></ul>"},
+		{"numbered list", "1. one\n2. two", "<ol><li>one</li><li>two</li></ol>"},
+		{"heading", "## Impact\nAll users", "<h4>Impact</h4><p>All users</p>"},
+		{"hashtag", "#1 priority", "<p>#1 priority</p>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RenderMarkdown(tt.text)); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
@@ -13,11 +13,15 @@ import (
 	"github.com/wcy-dt/ponghub/internal/common/params"
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/highlight"
 )
 
-// checkEndpoint checks a single port based on the provided configuration
-func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serviceName string) checker.Endpoint {
+// checkEndpoint checks a single port based on the provided configuration.
+// If propagateTrace is set, every request carries a W3C traceparent header matching its attempt.
+func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serviceName string, propagateTrace bool) checker.Endpoint {
 	var failureDetails []string
+	var attempts []checker.Attempt
+	traceID := newTraceID()
 	successNum := 0
 	attemptNum := 0
 
@@ -33,14 +37,7 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 	isCertExpired := false
 
 	// Generate display URL for smart showing of template vs resolved URL
-	resolver := params.NewParameterResolver()
-	displayURL, highlightSegments := resolver.HighlightChanges(cfg.URL)
-	originalURL := cfg.URL
-	if originalURL == "" {
-		originalURL = cfg.ParsedURL
-		displayURL = cfg.ParsedURL
-		highlightSegments = nil
-	}
+	displayURL, highlightSegments := getDisplayURL(cfg)
 
 	// Check SSL certificate if it's an HTTPS URL
 	if urlIsHTTPS {
@@ -61,6 +58,7 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 	startTime := time.Now()
 	for currentAttemptNum := range maxRetryTimes {
 		attemptNum++
+		attempt := checker.Attempt{SpanID: newSpanID(), StartTime: time.Now()}
 		client := &http.Client{
 			Timeout: time.Duration(timeout) * time.Second,
 		}
@@ -73,11 +71,15 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 		if err != nil {
 			failureDetails = append(failureDetails, fmt.Sprintf("StatusCode: N/A, Error: %s", err.Error()))
 			log.Printf("FAILED - Error: %s", err.Error())
+			attempts = append(attempts, finishAttempt(attempt, 0, false, err.Error()))
 			continue
 		}
 		for headerName, headerValue := range cfg.ParsedHeaders {
 			req.Header.Set(headerName, headerValue)
 		}
+		if propagateTrace && req.Header.Get("traceparent") == "" {
+			req.Header.Set("traceparent", formatTraceparent(traceID, attempt.SpanID))
+		}
 		if cfg.ParsedBody != "" {
 			req.Body = io.NopCloser(strings.NewReader(cfg.ParsedBody))
 		}
@@ -89,12 +91,14 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 		if err != nil {
 			failureDetails = append(failureDetails, fmt.Sprintf("StatusCode: N/A, Error: %s", err.Error()))
 			log.Printf("FAILED - Error: %s", err.Error())
+			attempts = append(attempts, finishAttempt(attempt, 0, false, err.Error()))
 			continue
 		}
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
 			failureDetails = append(failureDetails, fmt.Sprintf("StatusCode: %d, Error: %s", resp.StatusCode, err.Error()))
 			log.Printf("FAILED - StatusCode: %d, Error: %s", resp.StatusCode, err.Error())
+			attempts = append(attempts, finishAttempt(attempt, resp.StatusCode, false, err.Error()))
 			if err := resp.Body.Close(); err != nil {
 				// Only log response body errors during tests to avoid exposing secrets
 				logIfTest("Error closing response body for %s: %v", cfg.ParsedURL, err)
@@ -108,6 +112,7 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 		isOnline := isSuccessfulResponse(cfg, resp, body)
 		if isOnline {
 			successNum++
+			attempts = append(attempts, finishAttempt(attempt, resp.StatusCode, true, ""))
 			if responseTime > maxResponseTime {
 				maxResponseTime = responseTime
 			}
@@ -123,6 +128,7 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 		}
 		failureDetails = append(failureDetails, fmt.Sprintf("StatusCode or ResponseRegex mismatch: %d", resp.StatusCode))
 		log.Printf("FAILED - StatusCode or ResponseRegex mismatch: %d", resp.StatusCode)
+		attempts = append(attempts, finishAttempt(attempt, resp.StatusCode, false, fmt.Sprintf("StatusCode or ResponseRegex mismatch: %d", resp.StatusCode)))
 		if err := resp.Body.Close(); err != nil {
 			// Only log response body errors during tests to avoid exposing secrets
 			logIfTest("Error closing response body for %s: %v", cfg.ParsedURL, err)
@@ -131,6 +137,8 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 	endTime := time.Now()
 
 	return checker.Endpoint{
+		Key:               cfg.Key,
+		PreviousKeys:      getPreviousKeys(cfg),
 		URL:               cfg.URL,
 		Method:            httpMethod,
 		Body:              cfg.Body,
@@ -148,7 +156,38 @@ func checkEndpoint(cfg *configure.Endpoint, timeout int, maxRetryTimes int, serv
 		IsCertExpired:     isCertExpired,
 		DisplayURL:        displayURL,
 		HighlightSegments: highlightSegments,
+		TraceID:           traceID,
+		SpanID:            newSpanID(),
+		Attempts:          attempts,
+	}
+}
+
+// getDisplayURL returns the URL shown in the report together with the segments resolved from parameters
+func getDisplayURL(cfg *configure.Endpoint) (string, []highlight.Segment) {
+	if cfg.URL == "" {
+		return cfg.ParsedURL, nil
 	}
+	resolver := params.NewParameterResolver()
+	return resolver.HighlightChanges(cfg.URL)
+}
+
+// getPreviousKeys returns the keys the history of the endpoint may still be logged under:
+// its previous ids and, when the key is no longer the URL, the URL used as key by older logs
+func getPreviousKeys(cfg *configure.Endpoint) []string {
+	previousKeys := append([]string(nil), cfg.PreviousIDs...)
+	if cfg.Key != cfg.URL {
+		previousKeys = append(previousKeys, cfg.URL)
+	}
+	return previousKeys
+}
+
+// finishAttempt completes an attempt with its outcome and the time elapsed since it started
+func finishAttempt(attempt checker.Attempt, statusCode int, isSuccessful bool, errMsg string) checker.Attempt {
+	attempt.Duration = time.Since(attempt.StartTime)
+	attempt.StatusCode = statusCode
+	attempt.IsSuccessful = isSuccessful
+	attempt.Error = errMsg
+	return attempt
 }
 
 // getHttpMethod converts a string method to an HTTP method constant
@@ -3,6 +3,7 @@ package checker
 import (
 	"time"
 
+	"github.com/wcy-dt/ponghub/internal/maintenance"
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
 	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
@@ -11,6 +12,7 @@ import (
 // CheckServices checks all services defined in the configuration
 func CheckServices(cfg *configure.Configure) []checker.Service {
 	var checkResult []checker.Service
+	propagateTrace := cfg.Telemetry != nil && cfg.Telemetry.Enabled && cfg.Telemetry.Propagate
 	for _, service := range cfg.Services {
 		attemptNum := 0
 		successNum := 0
@@ -21,7 +23,7 @@ func CheckServices(cfg *configure.Configure) []checker.Service {
 		startTime := time.Now()
 		var endpointResults []checker.Endpoint
 		for _, endpoint := range service.Endpoints {
-			endpointResult := checkEndpoint(&endpoint, service.Timeout, service.MaxRetryTimes, service.Name)
+			endpointResult := checkEndpoint(&endpoint, service.Timeout, service.MaxRetryTimes, service.Name, propagateTrace)
 			endpointResults = append(endpointResults, endpointResult)
 			attemptNum += endpointResult.AttemptNum
 			successNum += endpointResult.SuccessNum
@@ -41,7 +43,49 @@ func CheckServices(cfg *configure.Configure) []checker.Service {
 			AttemptNum: attemptNum,
 			SuccessNum: successNum,
 		}
+		if maintenance.ActiveWindow(service.Maintenance, startTime) != nil {
+			markMaintenance(&serviceResult)
+		}
 		checkResult = append(checkResult, serviceResult)
 	}
 	return checkResult
 }
+
+// markMaintenance marks the failed checks of a service under maintenance with the MAINTENANCE status,
+// so that they neither count against its availability nor trigger alerts
+func markMaintenance(serviceResult *checker.Service) {
+	for i := range serviceResult.Endpoints {
+		if serviceResult.Endpoints[i].Status != chk_result.ALL {
+			serviceResult.Endpoints[i].Status = chk_result.MAINTENANCE
+		}
+	}
+	if serviceResult.Status != chk_result.ALL {
+		serviceResult.Status = chk_result.MAINTENANCE
+	}
+}
+
+// DescribeServices lists the services and endpoints defined in the configuration without checking them.
+// The results carry no status or certificate information, so they are only used to re-render the report from the log.
+func DescribeServices(cfg *configure.Configure) []checker.Service {
+	var services []checker.Service
+	for _, service := range cfg.Services {
+		var endpoints []checker.Endpoint
+		for _, endpoint := range service.Endpoints {
+			displayURL, highlightSegments := getDisplayURL(&endpoint)
+			endpoints = append(endpoints, checker.Endpoint{
+				Key:               endpoint.Key,
+				PreviousKeys:      getPreviousKeys(&endpoint),
+				URL:               endpoint.URL,
+				Method:            getHttpMethod(endpoint.Method),
+				Body:              endpoint.Body,
+				DisplayURL:        displayURL,
+				HighlightSegments: highlightSegments,
+			})
+		}
+		services = append(services, checker.Service{
+			Name:      service.Name,
+			Endpoints: endpoints,
+		})
+	}
+	return services
+}
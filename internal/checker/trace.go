@@ -0,0 +1,28 @@
+package checker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// newTraceID generates a random 16-byte W3C trace ID in hex
+func newTraceID() string {
+	return randomHex(16)
+}
+
+// newSpanID generates a random 8-byte W3C span ID in hex
+func newSpanID() string {
+	return randomHex(8)
+}
+
+// formatTraceparent builds a W3C traceparent header value for a sampled span
+func formatTraceparent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// randomHex returns n random bytes encoded in hex
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
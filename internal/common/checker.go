@@ -9,26 +9,26 @@ import (
 
 // processCheckResult processes the check results for a service
 func processCheckResult(serviceResult checker.Service) (map[string][]chk_result.CheckResult, map[string]string, map[string]time.Duration) {
-	urlStatusMap := make(map[string][]chk_result.CheckResult)
-	urlTimeMap := make(map[string]string)
-	urlResponseTimeMap := make(map[string]time.Duration)
+	keyStatusMap := make(map[string][]chk_result.CheckResult)
+	keyTimeMap := make(map[string]string)
+	keyResponseTimeMap := make(map[string]time.Duration)
 
 	// Process Endpoints checks
 	for _, endpoint := range serviceResult.Endpoints {
-		urlStatusMap[endpoint.URL] = append(urlStatusMap[endpoint.URL], endpoint.Status)
+		keyStatusMap[endpoint.Key] = append(keyStatusMap[endpoint.Key], endpoint.Status)
 
-		if _, exists := urlTimeMap[endpoint.URL]; !exists {
-			urlTimeMap[endpoint.URL] = endpoint.StartTime
-		} else if endpoint.StartTime < urlTimeMap[endpoint.URL] {
-			urlTimeMap[endpoint.URL] = endpoint.StartTime
+		if _, exists := keyTimeMap[endpoint.Key]; !exists {
+			keyTimeMap[endpoint.Key] = endpoint.StartTime
+		} else if endpoint.StartTime < keyTimeMap[endpoint.Key] {
+			keyTimeMap[endpoint.Key] = endpoint.StartTime
 		}
 
-		if _, exists := urlResponseTimeMap[endpoint.URL]; !exists {
-			urlResponseTimeMap[endpoint.URL] = endpoint.ResponseTime
-		} else if endpoint.ResponseTime > urlResponseTimeMap[endpoint.URL] {
-			urlResponseTimeMap[endpoint.URL] = endpoint.ResponseTime
+		if _, exists := keyResponseTimeMap[endpoint.Key]; !exists {
+			keyResponseTimeMap[endpoint.Key] = endpoint.ResponseTime
+		} else if endpoint.ResponseTime > keyResponseTimeMap[endpoint.Key] {
+			keyResponseTimeMap[endpoint.Key] = endpoint.ResponseTime
 		}
 	}
 
-	return urlStatusMap, urlTimeMap, urlResponseTimeMap
+	return keyStatusMap, keyTimeMap, keyResponseTimeMap
 }
@@ -0,0 +1,104 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// lockPollInterval is how often a held lock is checked again
+	lockPollInterval = 50 * time.Millisecond
+
+	// staleLockAge is the age after which a lock is considered left behind by a crashed process.
+	// Locks are only held while a file is written, so they never get this old otherwise.
+	staleLockAge = 30 * time.Second
+)
+
+// WriteFileAtomic writes data to a temporary file next to path and renames it over path,
+// so readers see either the old or the new content but never a partial write
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		// only left behind when writing failed
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// LockFile takes an advisory lock on path by creating path.lock, waiting up to timeout for another holder.
+// A lock older than staleLockAge is removed, as its holder must have crashed.
+// The returned function releases the lock.
+func LockFile(path string, timeout time.Duration) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+			_ = f.Close()
+			return func() {
+				if err := os.Remove(lockPath); err != nil && !os.IsNotExist(err) {
+					log.Printf("Error releasing lock %s: %v", lockPath, err)
+				}
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			log.Printf("Removing stale lock %s left since %s", lockPath, info.ModTime().Format(time.RFC3339))
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%s is locked by another process, remove %s if no other PongHub is running", path, lockPath)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// backupPath returns the path of the n-th backup of path, 1 being the most recent
+func backupPath(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// rotateBackups shifts the backups of path by one and saves data as the most recent backup,
+// keeping at most count backups
+func rotateBackups(path string, data []byte, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	_ = os.Remove(backupPath(path, count))
+	for n := count - 1; n >= 1; n-- {
+		if err := os.Rename(backupPath(path, n), backupPath(path, n+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return WriteFileAtomic(backupPath(path, 1), data, 0644)
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "data.json")
+	if err := WriteFileAtomic(path, []byte("first"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := WriteFileAtomic(path, []byte("second"), 0644); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil || string(content) != "second" {
+		t.Errorf("Expected the file to hold the last write, got %q (%v)", content, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Expected no temporary files to be left behind, got %d entries", len(entries))
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+
+	unlock, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock to be taken, got %v", err)
+	}
+	if _, err := LockFile(path, 100*time.Millisecond); err == nil {
+		t.Errorf("Expected a second lock to time out while the first is held")
+	}
+	unlock()
+
+	unlock, err = LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock to be taken after it was released, got %v", err)
+	}
+	unlock()
+
+	// a lock left behind by a crashed process is taken over once stale
+	if err := os.WriteFile(path+".lock", []byte("1\n"), 0644); err != nil {
+		t.Fatalf("Failed to write lock: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatalf("Failed to age lock: %v", err)
+	}
+	unlock, err = LockFile(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Expected a stale lock to be taken over, got %v", err)
+	}
+	unlock()
+}
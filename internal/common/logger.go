@@ -2,40 +2,90 @@ package common
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
+	"time"
 
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
 	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
 )
 
-// ReadLogs loads log data from file or returns empty data
-func ReadLogs(logPath string) (logger.Logger, error) {
-	logResult := make(logger.Logger)
+const (
+	// logBackupNum is the number of previous logs kept next to the log file
+	logBackupNum = 3
+
+	// logLockTimeout is how long a run waits for another process updating the log
+	logLockTimeout = 10 * time.Second
+)
 
+// ReadLogs loads log data from file or returns empty data.
+// If the file cannot be parsed, the most recent backup that can is returned instead.
+func ReadLogs(logPath string) (logger.Logger, error) {
 	logContent, err := os.ReadFile(logPath)
 	if err != nil {
 		if !os.IsNotExist(err) {
 			return nil, err
 		}
+		return make(logger.Logger), nil
+	}
+
+	logResult, err := parseLogs(logContent)
+	if err == nil {
 		return logResult, nil
 	}
 
+	log.Printf("Log %s is corrupted, trying its backups: %v", logPath, err)
+	for n := 1; n <= logBackupNum; n++ {
+		backupContent, readErr := os.ReadFile(backupPath(logPath, n))
+		if readErr != nil {
+			continue
+		}
+		if backupResult, parseErr := parseLogs(backupContent); parseErr == nil {
+			log.Printf("Recovered the log from backup %s", backupPath(logPath, n))
+			return backupResult, nil
+		}
+	}
+	return nil, fmt.Errorf("log %s is corrupted and has no readable backup: %w", logPath, err)
+}
+
+// parseLogs decodes the content of a log file
+func parseLogs(logContent []byte) (logger.Logger, error) {
+	logResult := make(logger.Logger)
 	if err := json.Unmarshal(logContent, &logResult); err != nil {
 		return nil, err
 	}
 	return logResult, nil
 }
 
-// WriteLogs writes log data to file
+// LockLogs locks the log at logPath, waiting for another process updating it.
+// The lock is held from reading the log to writing it back, so that concurrent runs do not drop each other's entries.
+func LockLogs(logPath string) (func(), error) {
+	return LockFile(logPath, logLockTimeout)
+}
+
+// WriteLogs writes log data to file.
+// The file is replaced atomically, and the previous log is kept as a backup; callers hold the lock of LockLogs.
 func WriteLogs(logResult logger.Logger, logPath string) error {
 	logContent, err := json.MarshalIndent(logResult, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(logPath, logContent, 0644)
+
+	// only a log that can be read again is worth keeping as a backup
+	if previousContent, err := os.ReadFile(logPath); err == nil {
+		if _, err := parseLogs(previousContent); err == nil {
+			if err := rotateBackups(logPath, previousContent, logBackupNum); err != nil {
+				log.Printf("Error backing up log %s: %v", logPath, err)
+			}
+		}
+	}
+
+	return WriteFileAtomic(logPath, logContent, 0644)
 }
 
-// FilterLogs filters the previous log to include only services and endpoints present in the current check results
+// FilterLogs filters the previous log to include only services and endpoints present in the current check results.
+// The history logged under a previous key of an endpoint is carried over to its current key.
 func FilterLogs(previousLog logger.Logger, currentCheckResult []checker.Service) logger.Logger {
 	filteredPreviousLogs := make(logger.Logger)
 
@@ -46,19 +96,29 @@ func FilterLogs(previousLog logger.Logger, currentCheckResult []checker.Service)
 	for serviceName, serviceLog := range previousLog {
 		if currentServices[serviceName] {
 			filteredPreviousLog := logger.Service{
-				ServiceHistory: serviceLog.ServiceHistory,
-				Endpoints:      make(logger.Endpoints),
+				ServiceHistory:  serviceLog.ServiceHistory,
+				Endpoints:       make(logger.Endpoints),
+				ServiceRollups:  serviceLog.ServiceRollups,
+				EndpointRollups: make(map[string]logger.Rollups),
 			}
 
 			// Filter endpoints for this service
-			for endpointURL, endpointHistory := range serviceLog.Endpoints {
-				if currentEndpoints[serviceName][endpointURL] {
-					filteredPreviousLog.Endpoints[endpointURL] = endpointHistory
+			for endpointKey, endpointHistory := range serviceLog.Endpoints {
+				if currentEndpoints[serviceName][endpointKey] {
+					filteredPreviousLog.Endpoints[endpointKey] = endpointHistory
+				}
+			}
+			for endpointKey, endpointRollups := range serviceLog.EndpointRollups {
+				if currentEndpoints[serviceName][endpointKey] {
+					filteredPreviousLog.EndpointRollups[endpointKey] = endpointRollups
 				}
 			}
 
-			// Only add the service if it has at least one endpoint
-			if len(filteredPreviousLog.Endpoints) > 0 {
+			// Carry over the history of renamed endpoints
+			migrateEndpoints(serviceName, serviceLog, filteredPreviousLog, currentCheckResult, currentEndpoints[serviceName])
+
+			// Only add the service if it has at least one endpoint or rollup
+			if len(filteredPreviousLog.Endpoints) > 0 || len(filteredPreviousLog.ServiceRollups) > 0 {
 				filteredPreviousLogs[serviceName] = filteredPreviousLog
 			}
 		}
@@ -67,26 +127,76 @@ func FilterLogs(previousLog logger.Logger, currentCheckResult []checker.Service)
 	return filteredPreviousLogs
 }
 
+// migrateEndpoints moves the history logged under a previous key of an endpoint to its current key.
+// A previous key that is still the key of an endpoint, or that an earlier endpoint already claimed, is left alone.
+func migrateEndpoints(serviceName string, serviceLog, filteredLog logger.Service, currentCheckResult []checker.Service, currentKeys map[string]bool) {
+	claimedKeys := make(map[string]bool)
+	for _, serviceResult := range currentCheckResult {
+		if serviceResult.Name != serviceName {
+			continue
+		}
+		for _, endpoint := range serviceResult.Endpoints {
+			for _, previousKey := range endpoint.PreviousKeys {
+				if currentKeys[previousKey] || claimedKeys[previousKey] {
+					continue
+				}
+				previousHistory, hasHistory := serviceLog.Endpoints[previousKey]
+				previousRollups, hasRollups := serviceLog.EndpointRollups[previousKey]
+				if !hasHistory && !hasRollups {
+					continue
+				}
+				claimedKeys[previousKey] = true
+				if hasHistory {
+					filteredLog.Endpoints[endpoint.Key] = filteredLog.Endpoints[endpoint.Key].Merge(previousHistory)
+				}
+				if hasRollups {
+					filteredLog.EndpointRollups[endpoint.Key] = filteredLog.EndpointRollups[endpoint.Key].Merge(previousRollups)
+				}
+				log.Printf("Migrated the history of endpoint %s in service %s to %s", previousKey, serviceName, endpoint.Key)
+			}
+		}
+	}
+}
+
+// EndpointMoves returns the moves of the history logged under the previous keys of the endpoints to their current keys.
+// A previous key that is still the key of an endpoint is left alone, as in FilterLogs.
+func EndpointMoves(currentCheckResult []checker.Service) []logger.EndpointMove {
+	_, currentEndpoints := getMapOfCurrentServicesAndEndpoints(currentCheckResult)
+	var moves []logger.EndpointMove
+	for _, serviceResult := range currentCheckResult {
+		for _, endpoint := range serviceResult.Endpoints {
+			for _, previousKey := range endpoint.PreviousKeys {
+				if !currentEndpoints[serviceResult.Name][previousKey] {
+					moves = append(moves, logger.EndpointMove{Service: serviceResult.Name, From: previousKey, To: endpoint.Key})
+				}
+			}
+		}
+	}
+	return moves
+}
+
 // getMapOfCurrentServicesAndEndpoints creates maps for quick lookup of existing services and endpoints
 func getMapOfCurrentServicesAndEndpoints(currentCheckResult []checker.Service) (map[string]bool, map[string]map[string]bool) {
 	// Create maps for quick lookup of existing services and endpoints
 	currentServices := make(map[string]bool)
-	currentEndpoints := make(map[string]map[string]bool) // serviceName -> endpoint URL -> exists
+	currentEndpoints := make(map[string]map[string]bool) // serviceName -> endpoint key -> exists
 
 	for _, serviceResult := range currentCheckResult {
 		currentServices[serviceResult.Name] = true
 		currentEndpoints[serviceResult.Name] = make(map[string]bool)
 		for _, endpoint := range serviceResult.Endpoints {
-			currentEndpoints[serviceResult.Name][endpoint.URL] = true
+			currentEndpoints[serviceResult.Name][endpoint.Key] = true
 		}
 	}
 
 	return currentServices, currentEndpoints
 }
 
-// MergeLogs merges previous log data with current check results and cleans up old entries
-func MergeLogs(previousLog logger.Logger, currentCheckResult []checker.Service, maxLogDays int) logger.Logger {
+// MergeLogs merges previous log data with current check results.
+// Entries older than the raw retention are rolled up, and old rollups are compacted or dropped.
+func MergeLogs(previousLog logger.Logger, currentCheckResult []checker.Service, retention logger.Retention) logger.Logger {
 	mergedLog := previousLog
+	now := time.Now()
 
 	for _, serviceResult := range currentCheckResult {
 		serviceName := serviceResult.Name
@@ -105,26 +215,42 @@ func MergeLogs(previousLog logger.Logger, currentCheckResult []checker.Service,
 			Status: serviceResult.Status.String(),
 		}
 		serviceLog.ServiceHistory = serviceLog.ServiceHistory.AddEntry(newServiceHistoryEntry)
-		serviceLog.ServiceHistory = serviceLog.ServiceHistory.CleanExpiredEntries(maxLogDays)
 
 		// Update port statusList
-		urlStatusMap, urlTimeMap, urlResponseTimeMap := processCheckResult(serviceResult)
-		for url, statusList := range urlStatusMap {
+		keyStatusMap, keyTimeMap, keyResponseTimeMap := processCheckResult(serviceResult)
+		for key, statusList := range keyStatusMap {
 			mergedStatus := calcMergedStatus(statusList)
 			newEndpointHistoryEntry := logger.HistoryEntry{
-				Time:         urlTimeMap[url],
+				Time:         keyTimeMap[key],
 				Status:       mergedStatus.String(),
-				ResponseTime: int(urlResponseTimeMap[url].Milliseconds()),
+				ResponseTime: int(keyResponseTimeMap[key].Milliseconds()),
 			}
-
-			tmp := serviceLog.Endpoints[url]
-			tmp = tmp.AddEntry(newEndpointHistoryEntry)
-			tmp = tmp.CleanExpiredEntries(maxLogDays)
-			serviceLog.Endpoints[url] = tmp
+			serviceLog.Endpoints[key] = serviceLog.Endpoints[key].AddEntry(newEndpointHistoryEntry)
 		}
 
-		mergedLog[serviceName] = serviceLog
+		mergedLog[serviceName] = RollupExpiredEntries(serviceLog, retention, now)
 	}
 
 	return mergedLog
 }
+
+// RollupExpiredEntries moves the entries older than the raw retention of a service into its rollups,
+// then compacts or drops the old rollups
+func RollupExpiredEntries(serviceLog logger.Service, retention logger.Retention, now time.Time) logger.Service {
+	serviceHistory, expiredHistory := serviceLog.ServiceHistory.SplitExpiredEntries(retention.RawDays)
+	serviceLog.ServiceHistory = serviceHistory
+	serviceLog.ServiceRollups = serviceLog.ServiceRollups.AddEntries(expiredHistory).Compact(retention, now)
+
+	if serviceLog.EndpointRollups == nil {
+		serviceLog.EndpointRollups = make(map[string]logger.Rollups)
+	}
+	for key, endpointHistory := range serviceLog.Endpoints {
+		endpointHistory, expired := endpointHistory.SplitExpiredEntries(retention.RawDays)
+		serviceLog.Endpoints[key] = endpointHistory
+		serviceLog.EndpointRollups[key] = serviceLog.EndpointRollups[key].AddEntries(expired).Compact(retention, now)
+		if len(serviceLog.EndpointRollups[key]) == 0 {
+			delete(serviceLog.EndpointRollups, key)
+		}
+	}
+	return serviceLog
+}
@@ -0,0 +1,206 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+func TestFilterLogsMigratesPreviousKeys(t *testing.T) {
+	previousLog := logger.Logger{
+		"API": {
+			ServiceHistory: logger.History{{Time: "2025-01-01T00:00:00Z", Status: "ALL"}},
+			Endpoints: logger.Endpoints{
+				"https://old.example.com/health": {{Time: "2025-01-01T00:00:00Z", Status: "ALL"}},
+				"https://api.example.com/login":  {{Time: "2025-01-01T00:00:00Z", Status: "NONE"}},
+				"https://api.example.com/gone":   {{Time: "2025-01-01T00:00:00Z", Status: "ALL"}},
+			},
+		},
+	}
+	currentCheckResult := []checker.Service{{
+		Name: "API",
+		Endpoints: []checker.Endpoint{
+			{Key: "health", PreviousKeys: []string{"https://old.example.com/health"}},
+			{Key: "POST https://api.example.com/login", PreviousKeys: []string{"https://api.example.com/login"}},
+		},
+	}}
+
+	filteredLog := FilterLogs(previousLog, currentCheckResult)
+
+	endpoints := filteredLog["API"].Endpoints
+	if len(endpoints) != 2 {
+		t.Fatalf("Expected 2 endpoints after filtering, got %d: %v", len(endpoints), endpoints)
+	}
+	if len(endpoints["health"]) != 1 || endpoints["health"][0].Status != "ALL" {
+		t.Errorf("Expected the history of the renamed endpoint to be kept, got %v", endpoints["health"])
+	}
+	if len(endpoints["POST https://api.example.com/login"]) != 1 {
+		t.Errorf("Expected the history logged under the URL to be migrated, got %v", endpoints["POST https://api.example.com/login"])
+	}
+}
+
+func TestFilterLogsKeepsCurrentKeys(t *testing.T) {
+	previousLog := logger.Logger{
+		"API": {
+			Endpoints: logger.Endpoints{
+				"https://api.example.com/items": {{Time: "2025-01-01T00:00:00Z", Status: "ALL"}},
+			},
+		},
+	}
+	currentCheckResult := []checker.Service{{
+		Name: "API",
+		Endpoints: []checker.Endpoint{
+			{Key: "https://api.example.com/items"},
+			{Key: "POST https://api.example.com/items", PreviousKeys: []string{"https://api.example.com/items"}},
+		},
+	}}
+
+	endpoints := FilterLogs(previousLog, currentCheckResult)["API"].Endpoints
+	if len(endpoints["https://api.example.com/items"]) != 1 {
+		t.Errorf("Expected the GET endpoint to keep its history, got %v", endpoints)
+	}
+	if _, exists := endpoints["POST https://api.example.com/items"]; exists {
+		t.Errorf("Expected the POST endpoint not to take over the history of the GET endpoint, got %v", endpoints)
+	}
+}
+
+func TestWriteLogsKeepsBackups(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "ponghub_log.json")
+	for i, status := range []string{"ALL", "PART", "NONE"} {
+		logResult := logger.Logger{"API": {ServiceHistory: logger.History{{Time: "2025-01-01T00:00:00Z", Status: status}}}}
+		if err := WriteLogs(logResult, logPath); err != nil {
+			t.Fatalf("Expected write %d to succeed, got %v", i, err)
+		}
+	}
+
+	backup, err := ReadLogs(logPath + ".1")
+	if err != nil || backup["API"].ServiceHistory[0].Status != "PART" {
+		t.Errorf("Expected the previous log as the first backup, got %v (%v)", backup, err)
+	}
+	if _, err := os.Stat(logPath + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("Expected the lock to be released after writing")
+	}
+}
+
+func TestReadLogsRecoversFromBackup(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "ponghub_log.json")
+	for _, status := range []string{"ALL", "PART"} {
+		logResult := logger.Logger{"API": {ServiceHistory: logger.History{{Time: "2025-01-01T00:00:00Z", Status: status}}}}
+		if err := WriteLogs(logResult, logPath); err != nil {
+			t.Fatalf("Expected no error writing, got %v", err)
+		}
+	}
+
+	// simulate a write cut short by a crash
+	if err := os.WriteFile(logPath, []byte(`{"API": {"service_hist`), 0644); err != nil {
+		t.Fatalf("Failed to truncate log: %v", err)
+	}
+
+	logResult, err := ReadLogs(logPath)
+	if err != nil {
+		t.Fatalf("Expected the log to be recovered, got %v", err)
+	}
+	if logResult["API"].ServiceHistory[0].Status != "ALL" {
+		t.Errorf("Expected the last good log from the backup, got %v", logResult)
+	}
+
+	// the corrupted log must not push the good one out of the backups
+	if err := WriteLogs(logResult, logPath); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+	if backup, err := ReadLogs(logPath + ".1"); err != nil || backup["API"].ServiceHistory[0].Status != "ALL" {
+		t.Errorf("Expected the good log to remain the first backup, got %v (%v)", backup, err)
+	}
+}
+
+func TestMergeLogsRollsUpExpiredEntries(t *testing.T) {
+	now := time.Now().UTC()
+	expiredHour := now.AddDate(0, 0, -5).Truncate(time.Hour)
+	previousLog := logger.Logger{
+		"API": {
+			ServiceHistory: logger.History{
+				{Time: expiredHour.Format(time.RFC3339), Status: "all"},
+				{Time: expiredHour.Add(10 * time.Minute).Format(time.RFC3339), Status: "none"},
+			},
+			Endpoints: logger.Endpoints{
+				"https://api.example.com/health": {
+					{Time: expiredHour.Format(time.RFC3339), Status: "all", ResponseTime: 100},
+					{Time: expiredHour.Add(10 * time.Minute).Format(time.RFC3339), Status: "none", ResponseTime: 300},
+				},
+			},
+		},
+	}
+	currentCheckResult := []checker.Service{{
+		Name:      "API",
+		Status:    chk_result.ALL,
+		StartTime: now.Format(time.RFC3339),
+		Endpoints: []checker.Endpoint{{
+			Key:          "https://api.example.com/health",
+			Status:       chk_result.ALL,
+			StartTime:    now.Format(time.RFC3339),
+			ResponseTime: 50 * time.Millisecond,
+		}},
+	}}
+	retention := logger.Retention{RawDays: 3, HourlyWeeks: 4, DailyDays: 365}
+
+	mergedLog := MergeLogs(previousLog, currentCheckResult, retention)
+
+	serviceLog := mergedLog["API"]
+	if len(serviceLog.ServiceHistory) != 1 {
+		t.Errorf("Expected only the new service entry to stay raw, got %v", serviceLog.ServiceHistory)
+	}
+	if len(serviceLog.ServiceRollups) != 1 || serviceLog.ServiceRollups[0].Count != 2 || serviceLog.ServiceRollups[0].Uptime != 0.5 {
+		t.Errorf("Expected one hourly rollup of 2 checks at 50%% uptime, got %+v", serviceLog.ServiceRollups)
+	}
+	endpointRollups := serviceLog.EndpointRollups["https://api.example.com/health"]
+	if len(endpointRollups) != 1 {
+		t.Fatalf("Expected one endpoint rollup, got %+v", endpointRollups)
+	}
+	rollup := endpointRollups[0]
+	if rollup.Period != logger.PeriodHour || rollup.MinResponseTime != 100 || rollup.AvgResponseTime != 200 || rollup.P95ResponseTime != 300 {
+		t.Errorf("Expected an hourly rollup with min 100, avg 200 and p95 300, got %+v", rollup)
+	}
+
+	// merging the same expired entries again must not count them twice
+	previousLog["API"] = logger.Service{
+		ServiceHistory: append(logger.History{{Time: expiredHour.Add(20 * time.Minute).Format(time.RFC3339), Status: "all"}}, serviceLog.ServiceHistory...),
+		Endpoints:      serviceLog.Endpoints,
+		ServiceRollups: serviceLog.ServiceRollups,
+	}
+	mergedLog = MergeLogs(previousLog, currentCheckResult, retention)
+	if rollups := mergedLog["API"].ServiceRollups; len(rollups) != 1 || rollups[0].Count != 2 {
+		t.Errorf("Expected the existing rollup to be kept unchanged, got %+v", rollups)
+	}
+}
+
+func TestRollupsCompact(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	day := now.AddDate(0, 0, -40).Truncate(24 * time.Hour)
+	rollups := logger.Rollups{
+		{Time: day.Add(time.Hour).Format(time.RFC3339), Period: logger.PeriodHour, Count: 10, Uptime: 1, MinResponseTime: 20, AvgResponseTime: 100, P95ResponseTime: 150},
+		{Time: day.Add(2 * time.Hour).Format(time.RFC3339), Period: logger.PeriodHour, Count: 30, Uptime: 0.5, MinResponseTime: 10, AvgResponseTime: 200, P95ResponseTime: 400},
+		{Time: now.AddDate(0, 0, -400).Truncate(24 * time.Hour).Format(time.RFC3339), Period: logger.PeriodDay, Count: 100, Uptime: 1},
+		{Time: now.AddDate(0, 0, -5).Truncate(time.Hour).Format(time.RFC3339), Period: logger.PeriodHour, Count: 5, Uptime: 1},
+	}
+
+	compacted := rollups.Compact(logger.Retention{RawDays: 3, HourlyWeeks: 4, DailyDays: 365}, now)
+
+	if len(compacted) != 2 {
+		t.Fatalf("Expected a daily and an hourly rollup, got %+v", compacted)
+	}
+	daily := compacted[0]
+	if daily.Period != logger.PeriodDay || daily.Time != day.Format(time.RFC3339) {
+		t.Errorf("Expected a daily rollup starting at %s, got %+v", day.Format(time.RFC3339), daily)
+	}
+	if daily.Count != 40 || daily.Uptime != 0.625 || daily.MinResponseTime != 10 || daily.AvgResponseTime != 175 || daily.P95ResponseTime != 400 {
+		t.Errorf("Expected 40 checks at 62.5%% uptime with min 10, avg 175 and p95 400, got %+v", daily)
+	}
+	if compacted[1].Period != logger.PeriodHour {
+		t.Errorf("Expected the recent hourly rollup to be kept, got %+v", compacted[1])
+	}
+}
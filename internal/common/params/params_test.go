@@ -598,3 +598,23 @@ func TestMixedNestedParameters(t *testing.T) {
 		t.Error("Third part should start with 'Time: '")
 	}
 }
+
+func TestSubstituteVars(t *testing.T) {
+	vars := map[string]string{"host": "api.example.com", "port": "8443"}
+
+	result, undefined := SubstituteVars("https://{{var(host)}}:{{ var(port) }}/health?t={{%Y}}", vars)
+	if result != "https://api.example.com:8443/health?t={{%Y}}" {
+		t.Errorf("Expected variables to be substituted and other parameters kept, got %s", result)
+	}
+	if len(undefined) != 0 {
+		t.Errorf("Expected no undefined variables, got %v", undefined)
+	}
+
+	result, undefined = SubstituteVars("https://{{var(region)}}.example.com", vars)
+	if result != "https://{{var(region)}}.example.com" {
+		t.Errorf("Expected undefined variable to be kept, got %s", result)
+	}
+	if len(undefined) != 1 || undefined[0] != "region" {
+		t.Errorf("Expected region to be reported as undefined, got %v", undefined)
+	}
+}
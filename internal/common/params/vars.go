@@ -0,0 +1,26 @@
+package params
+
+import (
+	"regexp"
+	"strings"
+)
+
+// varPattern matches {{var(name)}} references to service variables
+var varPattern = regexp.MustCompile(`\{\{\s*var\(([^(){}]*)\)\s*}}`)
+
+// SubstituteVars replaces every {{var(name)}} reference in input with the value of name in vars.
+// Other parameters are left untouched so they are resolved later like any other {{...}} parameter.
+// It also returns the names that are referenced but not defined in vars.
+func SubstituteVars(input string, vars map[string]string) (string, []string) {
+	var undefined []string
+	result := varPattern.ReplaceAllStringFunc(input, func(match string) string {
+		name := strings.TrimSpace(varPattern.FindStringSubmatch(match)[1])
+		value, exists := vars[name]
+		if !exists {
+			undefined = append(undefined, name)
+			return match
+		}
+		return value
+	})
+	return result, undefined
+}
@@ -8,17 +8,22 @@ func calcMergedStatus(statusList []chk_result.CheckResult) chk_result.CheckResul
 		return chk_result.NONE
 	}
 
-	hasNone, hasAll := false, false
+	hasNone, hasAll, hasMaintenance := false, false, false
 	for _, s := range statusList {
 		switch s {
 		case chk_result.NONE:
 			hasNone = true
 		case chk_result.ALL:
 			hasAll = true
+		case chk_result.MAINTENANCE:
+			hasMaintenance = true
 		}
 	}
 
 	switch {
+	case hasMaintenance:
+		// failures are only marked as maintenance, so the other checks were either fine or under maintenance too
+		return chk_result.MAINTENANCE
 	case hasNone && !hasAll:
 		return chk_result.NONE
 	case !hasNone && hasAll:
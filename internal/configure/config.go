@@ -1,8 +1,13 @@
 package configure
 
 import (
-	"log"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 
 	"github.com/wcy-dt/ponghub/internal/common/params"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
@@ -11,38 +16,175 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// ReadConfigs loads the configuration from a YAML file at the specified path
+// ReadConfigs loads the configuration from a YAML file at the specified path,
+// together with the files matched by its include globs and the YAML files in the services.d directory next to it.
+// Every problem found in the files is returned at once as configure.ValidationErrors.
 func ReadConfigs(path string) (*configure.Configure, error) {
-	// Read the configuration file
-	f, err := os.Open(path)
+	v := &validator{file: path}
+
+	// Decode the main configuration file
+	cfg := new(configure.Configure)
+	root, err := v.decodeFile(path, cfg)
 	if err != nil {
 		return nil, err
 	}
-	defer func(f *os.File) {
-		if err := f.Close(); err != nil {
-			log.Println("Error closing config file:", err)
+	cfg.Sources = []string{path}
+
+	servicesNode := mappingValue(root, "services")
+	serviceNodes := make([]*yaml.Node, 0, len(cfg.Services))
+	for i := range cfg.Services {
+		cfg.Services[i].Source = path
+		serviceNodes = append(serviceNodes, sequenceItem(servicesNode, i))
+	}
+
+	// Merge the services of the included files
+	includes, err := v.resolveIncludes(path, root, cfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	for _, include := range includes {
+		v.file = include
+		included := new(configure.IncludeFile)
+		includeRoot, err := v.decodeFile(include, included)
+		if err != nil {
+			return nil, err
 		}
-	}(f)
+		cfg.Sources = append(cfg.Sources, include)
 
-	// Decode the YAML configuration
-	cfg := new(configure.Configure)
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(cfg); err != nil {
-		log.Fatalln("Failed to decode YAML config:", err)
+		includeServicesNode := mappingValue(includeRoot, "services")
+		for i, service := range included.Services {
+			service.Source = include
+			cfg.Services = append(cfg.Services, service)
+			serviceNodes = append(serviceNodes, sequenceItem(includeServicesNode, i))
+		}
 	}
+	v.file = path
+
+	// Apply service templates and substitute service variables
+	endpointNodes := v.expandTemplates(cfg, serviceNodes)
 
 	// Resolve dynamic parameters
 	resolveConfigParameters(cfg)
 
+	// Derive the keys the endpoint histories are logged under
+	setEndpointKeys(cfg)
+
+	// Check the values before any service is probed
+	v.validateConfig(root, cfg, serviceNodes, endpointNodes)
+	if len(v.errs) > 0 {
+		v.sortErrors(cfg.Sources)
+		return nil, v.errs
+	}
+
 	// Set default values for the configuration
 	setDefaultConfigs(cfg)
 
-	if len(cfg.Services) == 0 {
-		log.Fatalln("No services defined in the configuration file")
-	}
 	return cfg, nil
 }
 
+// decodeFile decodes the YAML file at path into out, recording unknown fields and type errors.
+// It returns the parsed document so that later problems can be reported with their position.
+func (v *validator) decodeFile(path string, out any) (*yaml.Node, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the YAML document, keeping line and column numbers
+	root := new(yaml.Node)
+	if err := yaml.Unmarshal(content, root); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML config %s: %w", path, err)
+	}
+	if len(root.Content) == 0 {
+		return root, nil
+	}
+
+	// Reject unknown fields such as typos in key names
+	v.checkUnknownFields(root, reflect.TypeOf(out), "")
+
+	// Decode the YAML configuration
+	if err := root.Decode(out); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("failed to decode YAML config %s: %w", path, err)
+		}
+		v.addTypeErrors(typeErr)
+	}
+	return root, nil
+}
+
+// resolveIncludes returns the files matched by the include globs and the YAML files in the services.d directory.
+// Relative globs are resolved against the directory of the main configuration file.
+func (v *validator) resolveIncludes(path string, root *yaml.Node, patterns []string) ([]string, error) {
+	baseDir := filepath.Dir(path)
+	includeNode := mappingValue(root, "include")
+
+	seen := map[string]bool{filepath.Clean(path): true}
+	var includes []string
+	addMatches := func(matches []string) {
+		sort.Strings(matches)
+		for _, match := range matches {
+			if info, err := os.Stat(match); err != nil || info.IsDir() {
+				continue
+			}
+			if clean := filepath.Clean(match); !seen[clean] {
+				seen[clean] = true
+				includes = append(includes, match)
+			}
+		}
+	}
+
+	for i, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			v.add(firstNonNil(sequenceItem(includeNode, i), includeNode), "invalid include pattern %q: %v", patterns[i], err)
+			continue
+		}
+		if len(matches) == 0 && !hasGlobMeta(pattern) {
+			v.add(firstNonNil(sequenceItem(includeNode, i), includeNode), "included file %q does not exist", patterns[i])
+			continue
+		}
+		addMatches(matches)
+	}
+
+	servicesDir := filepath.Join(baseDir, default_config.GetServicesDir())
+	for _, ext := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(servicesDir, ext))
+		if err != nil {
+			return nil, err
+		}
+		addMatches(matches)
+	}
+
+	return includes, nil
+}
+
+// sortErrors orders the problems by file, in the order the files were loaded, then by position
+func (v *validator) sortErrors(sources []string) {
+	fileIndex := make(map[string]int, len(sources))
+	for i, source := range sources {
+		fileIndex[source] = i
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		a, b := v.errs[i], v.errs[j]
+		if fileIndex[a.File] != fileIndex[b.File] {
+			return fileIndex[a.File] < fileIndex[b.File]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// hasGlobMeta reports whether pattern contains any of the special characters recognized by filepath.Match
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
 // resolveConfigParameters resolves dynamic parameters in configuration
 func resolveConfigParameters(cfg *configure.Configure) {
 	resolver := params.NewParameterResolver()
@@ -72,9 +214,29 @@ func setDefaultConfigs(cfg *configure.Configure) {
 	default_config.SetDefaultMaxLogDays(&cfg.MaxLogDays)
 	default_config.SetDefaultCertNotifyDays(&cfg.CertNotifyDays)
 	default_config.SetDefaultDisplayNum(&cfg.DisplayNum)
+	default_config.SetDefaultInterval(&cfg.ParsedInterval, default_config.GetDefaultInterval())
+	default_config.SetDefaultAlertAfter(&cfg.AlertAfter, default_config.GetDefaultAlertAfter())
+
+	if cfg.Storage == nil {
+		cfg.Storage = &configure.StorageConfig{}
+	}
+	default_config.SetDefaultStorageType(&cfg.Storage.Type)
+	default_config.SetDefaultRetentionDays(&cfg.Storage.RetentionDays)
+
+	if cfg.Rollups == nil {
+		cfg.Rollups = &configure.RollupConfig{}
+	}
+	default_config.SetDefaultRollupHourlyWeeks(&cfg.Rollups.HourlyWeeks)
+	default_config.SetDefaultRollupDailyDays(&cfg.Rollups.DailyDays)
 
 	for i := range cfg.Services {
 		default_config.SetDefaultTimeout(&cfg.Services[i].Timeout)
 		default_config.SetDefaultMaxRetryTimes(&cfg.Services[i].MaxRetryTimes)
+		default_config.SetDefaultInterval(&cfg.Services[i].ParsedInterval, cfg.ParsedInterval)
+		default_config.SetDefaultAlertAfter(&cfg.Services[i].AlertAfter, cfg.AlertAfter)
+		default_config.SetDefaultSeverity(&cfg.Services[i].Severity)
+		if cfg.Services[i].Flapping == nil {
+			cfg.Services[i].Flapping = cfg.Flapping
+		}
 	}
 }
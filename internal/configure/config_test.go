@@ -0,0 +1,631 @@
+package configure
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// writeConfig writes content to a temporary config file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestReadConfigsValid(t *testing.T) {
+	path := writeConfig(t, `
+interval: 5m
+services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com"
+        method: post
+        status_code: 201
+        response_regex: "ok|healthy"
+`)
+
+	cfg, err := ReadConfigs(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(cfg.Services) != 1 || len(cfg.Services[0].Endpoints) != 1 {
+		t.Fatalf("Expected 1 service with 1 endpoint, got %+v", cfg.Services)
+	}
+	if cfg.Services[0].ParsedInterval.Minutes() != 5 {
+		t.Errorf("Expected service interval to fall back to 5m, got %v", cfg.Services[0].ParsedInterval)
+	}
+}
+
+func TestReadConfigsValidationErrors(t *testing.T) {
+	path := writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com"
+        statuscode: 200
+      - url: "ftp://api.example.com"
+        response_regex: "([a-z"
+      - url: "https://api.example.com"
+        method: get
+  - name: "API"
+    endpoints:
+      - url: "https://other.example.com"
+        method: DELETE
+`)
+
+	_, err := ReadConfigs(path)
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	expected := []struct {
+		line, column int
+	}{
+		{5, 9},   // unknown field statuscode
+		{6, 14},  // ftp scheme
+		{7, 25},  // invalid regex
+		{8, 14},  // duplicate endpoint
+		{10, 11}, // duplicate service name
+		{13, 17}, // unsupported method
+	}
+	if len(validationErrs) != len(expected) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expected), len(validationErrs), validationErrs)
+	}
+	for i, want := range expected {
+		if validationErrs[i].Line != want.line || validationErrs[i].Column != want.column {
+			t.Errorf("Expected error %d at %d:%d, got %s", i, want.line, want.column, validationErrs[i].Error())
+		}
+		if validationErrs[i].File != path {
+			t.Errorf("Expected error %d to name file %s, got %s", i, path, validationErrs[i].File)
+		}
+	}
+}
+
+func TestReadConfigsSameURLInTwoServices(t *testing.T) {
+	// duplicates are only rejected within a service: each service keeps its own history of a shared URL
+	cfg, err := ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - url: "https://shared.example.com/health"
+  - name: "Website"
+    endpoints:
+      - url: "https://shared.example.com/health"
+`))
+	if err != nil {
+		t.Fatalf("Expected the same URL to be allowed in two services, got %v", err)
+	}
+	if len(cfg.Services) != 2 || cfg.Services[0].Endpoints[0].Key != cfg.Services[1].Endpoints[0].Key {
+		t.Errorf("Expected both services to check the shared URL, got %+v", cfg.Services)
+	}
+}
+
+func TestReadConfigsNoServices(t *testing.T) {
+	_, err := ReadConfigs(writeConfig(t, "timeout: 5\n"))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+		t.Fatalf("Expected a single validation error, got %v", err)
+	}
+	if validationErrs[0].Message != "no services defined" {
+		t.Errorf("Expected 'no services defined', got %s", validationErrs[0].Message)
+	}
+}
+
+func TestReadConfigsIncludes(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"config.yaml": `include:
+  - "teams/*.yaml"
+services:
+  - name: "Main"
+    endpoints:
+      - url: "https://main.example.com"
+`,
+		"teams/a.yaml": `services:
+  - name: "Team A"
+    endpoints:
+      - url: "https://a.example.com"
+`,
+		"services.d/b.yaml": `services:
+  - name: "Team B"
+    endpoints:
+      - url: "https://b.example.com"
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	cfg, err := ReadConfigs(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []struct{ name, source string }{
+		{"Main", filepath.Join(dir, "config.yaml")},
+		{"Team A", filepath.Join(dir, "teams", "a.yaml")},
+		{"Team B", filepath.Join(dir, "services.d", "b.yaml")},
+	}
+	if len(cfg.Services) != len(expected) {
+		t.Fatalf("Expected %d services, got %d", len(expected), len(cfg.Services))
+	}
+	for i, want := range expected {
+		if cfg.Services[i].Name != want.name || cfg.Services[i].Source != want.source {
+			t.Errorf("Expected service %s from %s, got %s from %s", want.name, want.source, cfg.Services[i].Name, cfg.Services[i].Source)
+		}
+	}
+	if len(cfg.Sources) != 3 {
+		t.Errorf("Expected 3 source files, got %v", cfg.Sources)
+	}
+
+	// a duplicate name in an included file is reported in that file
+	duplicate := filepath.Join(dir, "services.d", "c.yaml")
+	if err := os.WriteFile(duplicate, []byte("services:\n  - name: \"Main\"\n    endpoints:\n      - url: \"https://c.example.com\"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write c.yaml: %v", err)
+	}
+	_, err = ReadConfigs(filepath.Join(dir, "config.yaml"))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 1 {
+		t.Fatalf("Expected a single validation error, got %v", err)
+	}
+	if validationErrs[0].File != duplicate || validationErrs[0].Line != 2 {
+		t.Errorf("Expected duplicate name reported at %s:2, got %s", duplicate, validationErrs[0].Error())
+	}
+}
+
+func TestReadConfigsTemplates(t *testing.T) {
+	path := writeConfig(t, `templates:
+  microservice:
+    timeout: 3
+    vars:
+      scheme: "https"
+    endpoints:
+      - url: "{{var(scheme)}}://{{var(host)}}/health"
+        headers:
+          X-Service: "{{var(host)}}"
+      - url: "{{var(scheme)}}://{{var(host)}}/ready"
+        status_code: 204
+services:
+  - name: "Orders"
+    extends: microservice
+    vars:
+      host: "orders.example.com"
+    endpoints:
+      - url: "https://orders.example.com/metrics"
+  - name: "Users"
+    extends: microservice
+    timeout: 10
+    vars:
+      host: "users.internal"
+      scheme: "http"
+`)
+
+	cfg, err := ReadConfigs(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	orders, users := cfg.Services[0], cfg.Services[1]
+	if len(orders.Endpoints) != 3 {
+		t.Fatalf("Expected template endpoints followed by the service endpoint, got %d endpoints", len(orders.Endpoints))
+	}
+	if orders.Endpoints[0].URL != "https://orders.example.com/health" || orders.Endpoints[2].URL != "https://orders.example.com/metrics" {
+		t.Errorf("Unexpected endpoint URLs: %s, %s", orders.Endpoints[0].URL, orders.Endpoints[2].URL)
+	}
+	if orders.Endpoints[0].ParsedHeaders["X-Service"] != "orders.example.com" {
+		t.Errorf("Expected header variable to be substituted, got %s", orders.Endpoints[0].ParsedHeaders["X-Service"])
+	}
+	if orders.Endpoints[1].StatusCode != 204 {
+		t.Errorf("Expected status_code to be inherited, got %d", orders.Endpoints[1].StatusCode)
+	}
+	if orders.Timeout != 3 || users.Timeout != 10 {
+		t.Errorf("Expected timeouts 3 and 10, got %d and %d", orders.Timeout, users.Timeout)
+	}
+	if users.Endpoints[0].URL != "http://users.internal/health" {
+		t.Errorf("Expected service variables to override template variables, got %s", users.Endpoints[0].URL)
+	}
+	if users.Endpoints[0].Headers["X-Service"] != "users.internal" || orders.Endpoints[0].Headers["X-Service"] != "orders.example.com" {
+		t.Error("Expected every service to get its own copy of the template headers")
+	}
+}
+
+func TestReadConfigsTemplateErrors(t *testing.T) {
+	path := writeConfig(t, `templates:
+  web:
+    endpoints:
+      - url: "https://{{var(host)}}/"
+services:
+  - name: "A"
+    extends: web
+  - name: "B"
+    extends: missing
+`)
+
+	_, err := ReadConfigs(path)
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	var undefinedVar, unknownTemplate bool
+	for _, validationErr := range validationErrs {
+		switch {
+		case validationErr.Line == 7 && validationErr.Message == `undefined variable "host" in service A`:
+			undefinedVar = true
+		case validationErr.Line == 9 && validationErr.Message == `service B extends unknown template "missing"`:
+			unknownTemplate = true
+		}
+	}
+	if !undefinedVar || !unknownTemplate {
+		t.Errorf("Expected undefined variable and unknown template errors, got:\n%v", validationErrs)
+	}
+}
+
+func TestReadConfigsEndpointKeys(t *testing.T) {
+	cfg, err := ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com/items"
+      - url: "https://api.example.com/items"
+        method: POST
+        body: '{"name": "a"}'
+      - url: "https://api.example.com/items"
+        method: POST
+        body: '{"name": "b"}'
+      - id: "search"
+        previous_ids: ["https://old.example.com/search"]
+        url: "https://api.example.com/search?q=x"
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	endpoints := cfg.Services[0].Endpoints
+	if endpoints[0].Key != "https://api.example.com/items" {
+		t.Errorf("Expected a plain GET endpoint to be keyed by its URL, got %s", endpoints[0].Key)
+	}
+	if endpoints[1].Key == endpoints[2].Key || !strings.HasPrefix(endpoints[1].Key, "POST https://api.example.com/items body=") {
+		t.Errorf("Expected distinct POST keys, got %s and %s", endpoints[1].Key, endpoints[2].Key)
+	}
+	if endpoints[3].Key != "search" {
+		t.Errorf("Expected the id to be used as key, got %s", endpoints[3].Key)
+	}
+}
+
+func TestReadConfigsEndpointKeyErrors(t *testing.T) {
+	_, err := ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - id: "items"
+        url: "https://api.example.com/items"
+      - id: "items"
+        url: "https://api.example.com/other"
+      - url: "https://api.example.com/search"
+        previous_ids: ["items"]
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 2 {
+		t.Fatalf("Expected 2 validation errors, got %v", err)
+	}
+	if validationErrs[0].Line != 6 || !strings.Contains(validationErrs[0].Message, `duplicate endpoint id "items"`) {
+		t.Errorf("Expected a duplicate id error on line 6, got %s", validationErrs[0].Error())
+	}
+	if validationErrs[1].Line != 9 || !strings.Contains(validationErrs[1].Message, `previous id "items"`) {
+		t.Errorf("Expected a previous id error on line 9, got %s", validationErrs[1].Error())
+	}
+}
+
+func TestReadConfigsAlertThresholds(t *testing.T) {
+	cfg, err := ReadConfigs(writeConfig(t, `
+alert_after: 3
+flapping:
+  changes: 4
+  window: 1h
+templates:
+  flaky:
+    alert_after: 5
+services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com"
+  - name: "Flaky"
+    extends: flaky
+    endpoints:
+      - url: "https://flaky.example.com"
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if cfg.Services[0].AlertAfter != 3 || cfg.Services[1].AlertAfter != 5 {
+		t.Errorf("Expected alert_after 3 and 5, got %d and %d", cfg.Services[0].AlertAfter, cfg.Services[1].AlertAfter)
+	}
+	if flapping := cfg.Services[0].Flapping; flapping == nil || flapping.Changes != 4 || flapping.ParsedWindow.Hours() != 1 {
+		t.Errorf("Expected the global flapping settings, got %+v", flapping)
+	}
+
+	_, err = ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    alert_after: -1
+    flapping:
+      changes: 0
+      window: soon
+    endpoints:
+      - url: "https://api.example.com"
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) || len(validationErrs) != 3 {
+		t.Fatalf("Expected 3 validation errors, got %v", err)
+	}
+}
+
+func TestReadConfigsTemplateFlapping(t *testing.T) {
+	_, err := ReadConfigs(writeConfig(t, `templates:
+  flaky:
+    flapping:
+      changes: 0
+      window: soon
+services:
+  - name: "A"
+    extends: flaky
+    endpoints:
+      - url: "https://a.example.com"
+  - name: "B"
+    extends: flaky
+    endpoints:
+      - url: "https://b.example.com"
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+
+	// the settings shared by both services are reported once, where the template sets them
+	if len(validationErrs) != 2 {
+		t.Fatalf("Expected 2 validation errors, got:\n%v", validationErrs)
+	}
+	if validationErrs[0].Line != 4 || validationErrs[0].Message != "invalid flapping changes 0 for template flaky: must be a positive number of status changes" {
+		t.Errorf("Expected the flapping changes error on line 4, got %s", validationErrs[0].Error())
+	}
+	if validationErrs[1].Line != 5 {
+		t.Errorf("Expected the flapping window error on line 5, got %s", validationErrs[1].Error())
+	}
+}
+
+func TestReadConfigsMaintenance(t *testing.T) {
+	cfg, err := ReadConfigs(writeConfig(t, `
+templates:
+  nightly:
+    maintenance:
+      - schedule: "0 3 * * *"
+        duration: 30m
+services:
+  - name: "API"
+    extends: nightly
+    maintenance:
+      - start: 2025-01-10T22:00:00Z
+        end: 2025-01-10T23:00:00Z
+        reason: "Database upgrade"
+    endpoints:
+      - url: "https://api.example.com"
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	windows := cfg.Services[0].Maintenance
+	if len(windows) != 2 {
+		t.Fatalf("Expected the template window followed by the service window, got %+v", windows)
+	}
+	if windows[0].ParsedSchedule == nil || windows[0].ParsedDuration.Minutes() != 30 || windows[1].ParsedEnd.Sub(windows[1].ParsedStart).Hours() != 1 {
+		t.Errorf("Expected the windows to be parsed, got %+v", windows)
+	}
+
+	_, err = ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    maintenance:
+      - start: 2025-01-10T22:00:00Z
+      - start: 2025-01-10T22:00:00Z
+        end: 2025-01-10T21:00:00Z
+      - schedule: "0 25 * * *"
+        duration: soon
+        timezone: Nowhere/Special
+    endpoints:
+      - url: "https://api.example.com"
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expectedLines := []int{4, 6, 7, 8, 9}
+	if len(validationErrs) != len(expectedLines) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expectedLines), len(validationErrs), validationErrs)
+	}
+	for i, line := range expectedLines {
+		if validationErrs[i].Line != line {
+			t.Errorf("Expected error %d on line %d, got %s", i, line, validationErrs[i].Error())
+		}
+	}
+}
+
+func TestReadConfigsNotificationRoutes(t *testing.T) {
+	cfg, err := ReadConfigs(writeConfig(t, `
+templates:
+  web:
+    tags: [web]
+    severity: critical
+services:
+  - name: "Website"
+    extends: web
+    tags: [frontend]
+    endpoints:
+      - url: "https://www.example.com"
+  - name: "Batch"
+    endpoints:
+      - url: "https://batch.example.com"
+notifications:
+  enabled: true
+  methods: [ops]
+  channels:
+    - name: ops
+      type: slack
+      slack:
+        webhook_url: "https://hooks.slack.com/ops"
+  routes:
+    - tags: [web]
+      alerts: [down, recovered]
+      channels: [ops]
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if tags := cfg.Services[0].Tags; len(tags) != 2 || tags[0] != "web" || tags[1] != "frontend" {
+		t.Errorf("Expected the template tags followed by the service tags, got %v", tags)
+	}
+	if cfg.Services[0].Severity != "critical" || cfg.Services[1].Severity != "warning" {
+		t.Errorf("Expected severities critical and warning, got %s and %s", cfg.Services[0].Severity, cfg.Services[1].Severity)
+	}
+
+	_, err = ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    severity: urgent
+    endpoints:
+      - url: "https://api.example.com"
+notifications:
+  enabled: true
+  methods: [team]
+  channels:
+    - name: slack
+      type: slack
+      slack: {}
+    - name: ops
+      type: slack
+      discord: {}
+  routes:
+    - services: ["[db"]
+      alerts: [outage]
+      channels: [pager]
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expectedLines := []int{3, 8, 10, 14, 15, 17, 18, 19}
+	if len(validationErrs) != len(expectedLines) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expectedLines), len(validationErrs), validationErrs)
+	}
+	for i, line := range expectedLines {
+		if validationErrs[i].Line != line {
+			t.Errorf("Expected error %d on line %d, got %s", i, line, validationErrs[i].Error())
+		}
+	}
+}
+
+func TestReadConfigsEscalations(t *testing.T) {
+	cfg, err := ReadConfigs(writeConfig(t, `services:
+  - name: "db-main"
+    severity: critical
+    endpoints:
+      - url: "https://db.example.com"
+notifications:
+  enabled: true
+  channels:
+    - name: team
+      type: slack
+      slack:
+        webhook_url: "https://hooks.slack.com/team"
+  escalations:
+    - name: critical
+      severities: [critical]
+      steps:
+        - channels: [team]
+        - after: 15m
+          channels: [email]
+      repeat: 30m
+`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	escalation := cfg.Notifications.Escalations[0]
+	if escalation.ParsedRepeat != 30*time.Minute || escalation.Steps[0].ParsedAfter != 0 || escalation.Steps[1].ParsedAfter != 15*time.Minute {
+		t.Errorf("Expected the delays and the repeat interval to be parsed, got %+v", escalation)
+	}
+
+	_, err = ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com"
+notifications:
+  enabled: true
+  escalations:
+    - name: first
+      severities: [urgent]
+      steps:
+        - after: 1h
+          channels: [email]
+        - after: 15m
+          channels: [pager]
+      repeat: soon
+    - name: first
+      steps: []
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expectedLines := []int{9, 13, 14, 15, 16, 17}
+	if len(validationErrs) != len(expectedLines) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expectedLines), len(validationErrs), validationErrs)
+	}
+	for i, line := range expectedLines {
+		if validationErrs[i].Line != line {
+			t.Errorf("Expected error %d on line %d, got %s", i, line, validationErrs[i].Error())
+		}
+	}
+}
+
+func TestReadConfigsMessageTemplates(t *testing.T) {
+	_, err := ReadConfigs(writeConfig(t, `services:
+  - name: "API"
+    endpoints:
+      - url: "https://api.example.com"
+notifications:
+  enabled: true
+  report_url: "ftp://status.example.com"
+  message:
+    title: "{{.Channel}} alerts"
+    body: "{{range .Alerts}}{{.Name}}{{end}}"
+  channels:
+    - name: team
+      type: webhook
+      webhook:
+        url: "https://hooks.example.com/team"
+      message:
+        title: "{{if .Alerts}}"
+`))
+	var validationErrs configure.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		t.Fatalf("Expected ValidationErrors, got %v", err)
+	}
+	expectedLines := []int{7, 10, 17}
+	if len(validationErrs) != len(expectedLines) {
+		t.Fatalf("Expected %d errors, got %d:\n%v", len(expectedLines), len(validationErrs), validationErrs)
+	}
+	for i, line := range expectedLines {
+		if validationErrs[i].Line != line {
+			t.Errorf("Expected error %d on line %d, got %s", i, line, validationErrs[i].Error())
+		}
+	}
+}
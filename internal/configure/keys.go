@@ -0,0 +1,43 @@
+package configure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// endpointKey returns the key under which the history of an endpoint is logged.
+// An explicit id wins; plain GET endpoints keep their URL so that existing logs stay valid,
+// other endpoints are told apart by their method and a short hash of their body.
+func endpointKey(endpoint *configure.Endpoint) string {
+	if endpoint.ID != "" {
+		return endpoint.ID
+	}
+
+	method := strings.ToUpper(endpoint.Method)
+	if method == "" {
+		method = "GET"
+	}
+	if method == "GET" && endpoint.Body == "" {
+		return endpoint.URL
+	}
+
+	key := method + " " + endpoint.URL
+	if endpoint.Body != "" {
+		sum := sha256.Sum256([]byte(endpoint.Body))
+		key += " body=" + hex.EncodeToString(sum[:4])
+	}
+	return key
+}
+
+// setEndpointKeys sets the log key of every endpoint
+func setEndpointKeys(cfg *configure.Configure) {
+	for i := range cfg.Services {
+		for j := range cfg.Services[i].Endpoints {
+			endpoint := &cfg.Services[i].Endpoints[j]
+			endpoint.Key = endpointKey(endpoint)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package configure
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// schemaID is the identifier of the generated JSON Schema
+const schemaID = "https://github.com/wcy-dt/ponghub/raw/main/config.schema.json"
+
+// durationPattern matches Go durations such as 30s, 1h or 1h30m
+const durationPattern = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+// fieldSchemas holds the descriptions and constraints of the configuration fields,
+// keyed by the name of the Go type followed by the yaml key
+var fieldSchemas = map[string]map[string]any{
+	"Configure.services":         {"description": "List of services to monitor; may be left out when the services come from included files"},
+	"Configure.include":          {"description": "Glob patterns of files holding more services, relative to this file; services.d/*.yaml is always included"},
+	"Configure.templates":        {"description": "Reusable service settings, keyed by the name services use in extends"},
+	"Configure.timeout":          {"description": "Timeout for each request in seconds (default 5)", "minimum": 0},
+	"Configure.max_retry_times":  {"description": "Number of attempts per endpoint and check (default 2)", "minimum": 0},
+	"Configure.max_log_days":     {"description": "Number of days to retain logs (default 3)", "minimum": 0},
+	"Configure.cert_notify_days": {"description": "Days before SSL certificate expiration to notify (default 7)", "minimum": 0},
+	"Configure.display_num":      {"description": "Number of history entries per endpoint shown in the report (default 72)", "minimum": 0},
+	"Configure.interval":         {"description": "Interval between two checks in daemon mode, e.g. 30s or 1h (default 30m)", "pattern": durationPattern},
+	"Configure.metrics_file":     {"description": "Path of the Prometheus textfile written after each run"},
+	"Configure.alert_after":      {"description": "Consecutive failed checks before an endpoint is reported down (default 1)", "minimum": 0},
+	"Configure.flapping":         {"description": "When an endpoint that keeps going up and down is reported as flapping, disabled by default"},
+	"Configure.storage":          {"description": "Storage backend of the check history"},
+	"Configure.rollups":          {"description": "Retention of the hourly and daily rollups of expired history entries"},
+	"Configure.notifications":    {"description": "Notification configuration"},
+	"Configure.telemetry":        {"description": "OpenTelemetry export configuration"},
+
+	"Service.name":            {"description": "Name of the service", "minLength": 1},
+	"Service.extends":         {"description": "Name of the template whose endpoints and settings the service inherits"},
+	"Service.vars":            {"description": "Variables referenced as {{var(name)}} in the endpoints, overriding the template variables"},
+	"Service.endpoints":       {"description": "List of endpoints to check for the service, added after the template endpoints"},
+	"Service.timeout":         {"description": "Timeout for each request of this service in seconds", "minimum": 0},
+	"Service.max_retry_times": {"description": "Number of attempts per endpoint of this service", "minimum": 0},
+	"Service.interval":        {"description": "Interval between two checks of this service in daemon mode, defaults to the global interval", "pattern": durationPattern},
+	"Service.alert_after":     {"description": "Consecutive failed checks before an endpoint of this service is reported down, defaults to the global alert_after", "minimum": 0},
+	"Service.flapping":        {"description": "Flapping detection of this service, defaults to the global flapping settings"},
+	"Service.tags":            {"description": "Labels notification routes can match, added after the template tags"},
+	"Service.severity":        {"description": "Severity of the alerts of this service matched by notification routes (default warning)", "enum": supportedSeverities},
+	"Service.maintenance":     {"description": "Maintenance windows during which failures of this service are logged as maintenance and not alerted, added after the template windows"},
+
+	"Template.vars":            {"description": "Default values of the variables referenced as {{var(name)}} in the endpoints"},
+	"Template.endpoints":       {"description": "Endpoints added to every service extending the template"},
+	"Template.timeout":         {"description": "Timeout for each request in seconds, unless the service sets its own", "minimum": 0},
+	"Template.max_retry_times": {"description": "Number of attempts per endpoint, unless the service sets its own", "minimum": 0},
+	"Template.interval":        {"description": "Interval between two checks in daemon mode, unless the service sets its own", "pattern": durationPattern},
+	"Template.alert_after":     {"description": "Consecutive failed checks before an endpoint is reported down, unless the service sets its own", "minimum": 0},
+	"Template.flapping":        {"description": "Flapping detection, unless the service sets its own"},
+	"Template.tags":            {"description": "Labels of every service extending the template"},
+	"Template.severity":        {"description": "Severity of the alerts, unless the service sets its own", "enum": supportedSeverities},
+	"Template.maintenance":     {"description": "Maintenance windows of every service extending the template"},
+
+	"Endpoint.id":             {"description": "Stable identifier the history of the endpoint is logged under (default derived from the method, URL and body)", "minLength": 1},
+	"Endpoint.previous_ids":   {"description": "Former ids or URLs of the endpoint whose history is carried over", "items": map[string]any{"type": "string", "minLength": 1}},
+	"Endpoint.url":            {"description": "URL to request, may contain {{...}} parameters", "minLength": 1},
+	"Endpoint.method":         {"description": "HTTP method of the request (default GET)", "enum": methodEnum()},
+	"Endpoint.headers":        {"description": "Request headers, values may contain {{...}} parameters"},
+	"Endpoint.body":           {"description": "Request body, used for POST and PUT requests"},
+	"Endpoint.status_code":    {"description": "Expected HTTP status code (default 200)", "minimum": 100, "maximum": 599},
+	"Endpoint.response_regex": {"description": "Regular expression the response body must match"},
+
+	"StorageConfig.type":           {"description": "Backend storing the check history (default json)", "enum": supportedStorageTypes},
+	"StorageConfig.retention_days": {"description": "Days of history kept by the sqlite backend (default 90)", "minimum": 0},
+
+	"RollupConfig.hourly_weeks": {"description": "Weeks hourly rollups are kept before they become daily rollups (default 4)", "minimum": 0},
+	"RollupConfig.daily_days":   {"description": "Days daily rollups are kept (default 365)", "minimum": 0},
+
+	"FlappingConfig.changes": {"description": "Number of status changes within the window above which an endpoint is flapping", "minimum": 1},
+	"FlappingConfig.window":  {"description": "Period the status changes are counted over, e.g. 2h", "pattern": durationPattern},
+
+	"MaintenanceWindow.start":    {"description": "Start of a one-off window, RFC 3339 such as 2025-01-02T22:00:00Z", "format": "date-time"},
+	"MaintenanceWindow.end":      {"description": "End of a one-off window, RFC 3339", "format": "date-time"},
+	"MaintenanceWindow.schedule": {"description": "Cron expression starting a recurring window, e.g. 0 3 * * 0 or @daily"},
+	"MaintenanceWindow.duration": {"description": "Length of each recurring window, e.g. 1h", "pattern": durationPattern},
+	"MaintenanceWindow.timezone": {"description": "IANA time zone the schedule is evaluated in (default UTC)"},
+	"MaintenanceWindow.reason":   {"description": "Why the service is under maintenance"},
+
+	"NotificationConfig.enabled":     {"description": "Whether notifications are sent"},
+	"NotificationConfig.methods":     {"description": "Notification methods or channel names receiving every alert, or the alerts no route matches when routes are set", "items": map[string]any{"type": "string", "minLength": 1}},
+	"NotificationConfig.default":     {"description": "GitHub Actions notification settings"},
+	"NotificationConfig.discord":     {"description": "Discord webhook settings"},
+	"NotificationConfig.email":       {"description": "SMTP email settings, credentials are read from SMTP_USERNAME and SMTP_PASSWORD"},
+	"NotificationConfig.slack":       {"description": "Slack webhook settings"},
+	"NotificationConfig.telegram":    {"description": "Telegram bot settings"},
+	"NotificationConfig.wechat":      {"description": "WeChat Work webhook settings"},
+	"NotificationConfig.webhook":     {"description": "Custom webhook settings"},
+	"NotificationConfig.channels":    {"description": "Named channel instances, such as several Slack webhooks"},
+	"NotificationConfig.routes":      {"description": "Rules sending the matching alerts to chosen channels"},
+	"NotificationConfig.escalations": {"description": "Policies notifying more channels the longer an outage lasts unacknowledged"},
+	"NotificationConfig.message":     {"description": "Go text/templates of the title and body of the alert notifications of every channel"},
+	"NotificationConfig.report_url":  {"description": "URL of the status page, linked from the alert notifications"},
+
+	"ChannelConfig.name":     {"description": "Name the channel is referred to by in methods and routes", "minLength": 1},
+	"ChannelConfig.type":     {"description": "Type of the channel, whose settings are given under the key of the same name", "enum": supportedNotificationMethods},
+	"ChannelConfig.default":  {"description": "GitHub Actions notification settings"},
+	"ChannelConfig.discord":  {"description": "Discord webhook settings"},
+	"ChannelConfig.email":    {"description": "SMTP email settings"},
+	"ChannelConfig.slack":    {"description": "Slack webhook settings"},
+	"ChannelConfig.telegram": {"description": "Telegram bot settings"},
+	"ChannelConfig.wechat":   {"description": "WeChat Work webhook settings"},
+	"ChannelConfig.webhook":  {"description": "Custom webhook settings"},
+	"ChannelConfig.message":  {"description": "Go text/templates of the title and body of the alert notifications of this channel, overriding the shared ones"},
+
+	"MessageTemplate.title": {"description": "Go text/template of the notification title, the built-in title if empty"},
+	"MessageTemplate.body":  {"description": "Go text/template of the notification body, the built-in body if empty"},
+
+	"RouteConfig.services":   {"description": "Glob patterns of the service names the route matches, e.g. db-*"},
+	"RouteConfig.tags":       {"description": "Service tags the route matches"},
+	"RouteConfig.severities": {"description": "Service severities the route matches", "items": map[string]any{"type": "string", "enum": supportedSeverities}},
+	"RouteConfig.alerts":     {"description": "Alert types the route matches", "items": map[string]any{"type": "string", "enum": supportedAlertTypes}},
+	"RouteConfig.channels":   {"description": "Methods or channel names receiving the matching alerts", "minItems": 1},
+
+	"EscalationConfig.name":       {"description": "Name of the policy, shown in escalation messages", "minLength": 1},
+	"EscalationConfig.services":   {"description": "Glob patterns of the service names the policy applies to, e.g. db-*"},
+	"EscalationConfig.tags":       {"description": "Service tags the policy applies to"},
+	"EscalationConfig.severities": {"description": "Service severities the policy applies to", "items": map[string]any{"type": "string", "enum": supportedSeverities}},
+	"EscalationConfig.steps":      {"description": "Channels notified once an outage has lasted the delay of each step", "minItems": 1},
+	"EscalationConfig.repeat":     {"description": "Interval between reminders while the outage is unacknowledged, e.g. 30m (no reminders if empty)", "pattern": durationPattern},
+
+	"EscalationStep.after":    {"description": "How long the outage must last before this step, e.g. 15m (default 0)", "pattern": durationPattern},
+	"EscalationStep.channels": {"description": "Methods or channel names notified by this step", "minItems": 1},
+
+	"DefaultConfig.enabled": {"description": "Whether the GitHub Actions notification is enabled"},
+
+	"DiscordConfig.webhook_url": {"description": "Discord webhook URL, read from DISCORD_WEBHOOK_URL if empty"},
+	"DiscordConfig.username":    {"description": "Username shown for the message"},
+	"DiscordConfig.avatar_url":  {"description": "Avatar URL shown for the message"},
+	"DiscordConfig.use_embeds":  {"description": "Send the message as a rich embed"},
+	"DiscordConfig.color":       {"description": "Embed color as a decimal RGB value"},
+	"DiscordConfig.mentions":    {"description": "Users or roles to mention"},
+	"DiscordConfig.retries":     {"description": "Number of retries on failure", "minimum": 0},
+	"DiscordConfig.timeout":     {"description": "Request timeout in seconds", "minimum": 0},
+	"DiscordConfig.user_agent":  {"description": "User-Agent header of the request"},
+
+	"EmailConfig.smtp_host":    {"description": "SMTP server host"},
+	"EmailConfig.smtp_port":    {"description": "SMTP server port", "minimum": 1, "maximum": 65535},
+	"EmailConfig.from":         {"description": "Sender address"},
+	"EmailConfig.to":           {"description": "Recipient addresses", "minItems": 1},
+	"EmailConfig.reply_to":     {"description": "Reply-To address"},
+	"EmailConfig.use_tls":      {"description": "Connect with implicit TLS"},
+	"EmailConfig.use_starttls": {"description": "Upgrade the connection with STARTTLS"},
+	"EmailConfig.skip_verify":  {"description": "Skip TLS certificate verification"},
+
+	"SlackConfig.webhook_url": {"description": "Slack webhook URL, read from SLACK_WEBHOOK_URL if empty"},
+	"SlackConfig.channel":     {"description": "Channel to post to"},
+	"SlackConfig.username":    {"description": "Username shown for the message"},
+	"SlackConfig.icon_emoji":  {"description": "Emoji used as the icon, e.g. :warning:"},
+	"SlackConfig.icon_url":    {"description": "Image URL used as the icon"},
+	"SlackConfig.use_blocks":  {"description": "Send the message using Block Kit"},
+	"SlackConfig.color":       {"description": "Attachment color, e.g. danger or #ff0000"},
+	"SlackConfig.mentions":    {"description": "Users or groups to mention"},
+	"SlackConfig.retries":     {"description": "Number of retries on failure", "minimum": 0},
+	"SlackConfig.timeout":     {"description": "Request timeout in seconds", "minimum": 0},
+	"SlackConfig.user_agent":  {"description": "User-Agent header of the request"},
+
+	"TelegramConfig.bot_token":                {"description": "Bot token, read from TELEGRAM_BOT_TOKEN if empty"},
+	"TelegramConfig.chat_id":                  {"description": "Chat ID, read from TELEGRAM_CHAT_ID if empty"},
+	"TelegramConfig.parse_mode":               {"description": "Message parse mode", "enum": []string{"Markdown", "MarkdownV2", "HTML"}},
+	"TelegramConfig.disable_web_page_preview": {"description": "Disable link previews"},
+	"TelegramConfig.disable_notification":     {"description": "Send the message silently"},
+	"TelegramConfig.reply_to_message_id":      {"description": "ID of the message to reply to"},
+	"TelegramConfig.retries":                  {"description": "Number of retries on failure", "minimum": 0},
+	"TelegramConfig.timeout":                  {"description": "Request timeout in seconds", "minimum": 0},
+	"TelegramConfig.user_agent":               {"description": "User-Agent header of the request"},
+
+	"WeChatConfig.webhook_url": {"description": "WeChat Work webhook URL, read from WECHAT_WEBHOOK_URL if empty"},
+	"WeChatConfig.msg_type":    {"description": "Message type", "enum": []string{"text", "markdown"}},
+	"WeChatConfig.mentions":    {"description": "Users to mention"},
+	"WeChatConfig.retries":     {"description": "Number of retries on failure", "minimum": 0},
+	"WeChatConfig.timeout":     {"description": "Request timeout in seconds", "minimum": 0},
+	"WeChatConfig.user_agent":  {"description": "User-Agent header of the request"},
+
+	"WebhookConfig.url":             {"description": "Webhook URL, read from WEBHOOK_URL if empty"},
+	"WebhookConfig.method":          {"description": "HTTP method of the request (default POST)"},
+	"WebhookConfig.headers":         {"description": "Custom request headers"},
+	"WebhookConfig.template":        {"description": "Custom payload template"},
+	"WebhookConfig.format":          {"description": "Predefined payload format: slack, discord, teams or mattermost"},
+	"WebhookConfig.content_type":    {"description": "Content-Type header of the request"},
+	"WebhookConfig.auth_type":       {"description": "Authentication type: bearer, basic or apikey"},
+	"WebhookConfig.auth_token":      {"description": "Token for bearer or apikey authentication"},
+	"WebhookConfig.auth_username":   {"description": "Username for basic authentication"},
+	"WebhookConfig.auth_password":   {"description": "Password for basic authentication"},
+	"WebhookConfig.auth_header":     {"description": "Header name for apikey authentication (default X-API-Key)"},
+	"WebhookConfig.retries":         {"description": "Number of retries on failure", "minimum": 0},
+	"WebhookConfig.timeout":         {"description": "Request timeout in seconds", "minimum": 0},
+	"WebhookConfig.skip_tls_verify": {"description": "Skip TLS certificate verification"},
+
+	"TelemetryConfig.enabled":      {"description": "Whether probe results are exported"},
+	"TelemetryConfig.endpoint":     {"description": "OTLP/HTTP base URL, defaults to OTEL_EXPORTER_OTLP_ENDPOINT or http://localhost:4318"},
+	"TelemetryConfig.headers":      {"description": "Headers sent with every export request"},
+	"TelemetryConfig.service_name": {"description": "service.name resource attribute (default ponghub)"},
+	"TelemetryConfig.propagate":    {"description": "Send a W3C traceparent header with every probe"},
+	"TelemetryConfig.timeout":      {"description": "Export timeout in seconds", "minimum": 0},
+}
+
+// Schema returns the JSON Schema of the configuration file
+func Schema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(configure.Configure{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = schemaID
+	schema["title"] = "PongHub configuration"
+	return schema
+}
+
+// WriteSchema writes the JSON Schema of the configuration file to w
+func WriteSchema(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(Schema())
+}
+
+// typeSchema returns the JSON Schema of a Go type decoded from YAML
+func typeSchema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		return structSchema(t)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// structSchema returns the JSON Schema of a struct, with a property per yaml key.
+// Fields without omitempty are required and unknown keys are rejected.
+func structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		property := typeSchema(field.Type)
+		for key, value := range fieldSchemas[t.Name()+"."+name] {
+			property[key] = value
+		}
+		properties[name] = property
+
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// methodEnum lists the supported HTTP methods in upper and lower case
+func methodEnum() []string {
+	methods := make([]string, 0, 2*len(supportedMethods))
+	for _, method := range supportedMethods {
+		methods = append(methods, method, strings.ToLower(method))
+	}
+	return methods
+}
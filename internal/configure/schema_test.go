@@ -0,0 +1,43 @@
+package configure
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+// TestSchemaUpToDate ensures the committed config.schema.json matches the configuration structures
+func TestSchemaUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../../config.schema.json")
+	if err != nil {
+		t.Fatalf("Failed to read config.schema.json: %v", err)
+	}
+
+	var generated bytes.Buffer
+	if err := WriteSchema(&generated); err != nil {
+		t.Fatalf("Failed to generate schema: %v", err)
+	}
+
+	if !bytes.Equal(committed, generated.Bytes()) {
+		t.Error("config.schema.json is out of date, regenerate it with 'make schema'")
+	}
+}
+
+func TestSchemaRequiredFields(t *testing.T) {
+	schema := Schema()
+
+	// the services may all come from included files
+	if required, exists := schema["required"]; exists {
+		t.Errorf("Expected nothing to be required at the top level, got %v", required)
+	}
+
+	services := schema["properties"].(map[string]any)["services"].(map[string]any)
+	service := services["items"].(map[string]any)
+	serviceRequired, _ := service["required"].([]string)
+	if len(serviceRequired) != 1 || serviceRequired[0] != "name" {
+		t.Errorf("Expected only name to be required for a service, got %v", serviceRequired)
+	}
+	if service["additionalProperties"] != false {
+		t.Error("Expected unknown service fields to be rejected")
+	}
+}
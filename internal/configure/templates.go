@@ -0,0 +1,146 @@
+package configure
+
+import (
+	"slices"
+
+	"github.com/wcy-dt/ponghub/internal/common/params"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandTemplates applies the template each service extends and substitutes the service variables.
+// It returns the YAML nodes of the endpoints of every service, in the order of cfg.Services.
+// Endpoints taken from a template are reported at the extends key of the service.
+func (v *validator) expandTemplates(cfg *configure.Configure, serviceNodes []*yaml.Node) [][]*yaml.Node {
+	mainFile := v.file
+	endpointNodes := make([][]*yaml.Node, len(cfg.Services))
+	for i := range cfg.Services {
+		service := &cfg.Services[i]
+		serviceNode := serviceNodes[i]
+		if service.Source != "" {
+			v.file = service.Source
+		}
+
+		ownEndpointsNode := mappingValue(serviceNode, "endpoints")
+		nodes := make([]*yaml.Node, 0, len(service.Endpoints))
+		for j := range service.Endpoints {
+			nodes = append(nodes, firstNonNil(sequenceItem(ownEndpointsNode, j), serviceNode))
+		}
+
+		vars := service.Vars
+		if service.Extends != "" {
+			extendsNode := mappingValue(serviceNode, "extends")
+			template, exists := cfg.Templates[service.Extends]
+			if !exists {
+				v.add(extendsNode, "service %s extends unknown template %q", service.Name, service.Extends)
+			} else {
+				vars = mergeVars(template.Vars, service.Vars)
+				inheritTemplate(service, template)
+
+				templateNodes := make([]*yaml.Node, 0, len(template.Endpoints)+len(nodes))
+				for range template.Endpoints {
+					templateNodes = append(templateNodes, extendsNode)
+				}
+				nodes = append(templateNodes, nodes...)
+			}
+		}
+
+		for j := range service.Endpoints {
+			v.substituteVars(nodes[j], service.Name, &service.Endpoints[j], vars)
+		}
+		endpointNodes[i] = nodes
+	}
+	v.file = mainFile
+	return endpointNodes
+}
+
+// inheritTemplate adds the template endpoints and maintenance windows in front of those of the service
+// and fills the settings the service does not set itself
+func inheritTemplate(service *configure.Service, template configure.Template) {
+	endpoints := make([]configure.Endpoint, 0, len(template.Endpoints)+len(service.Endpoints))
+	endpoints = append(endpoints, template.Endpoints...)
+	service.Endpoints = append(endpoints, service.Endpoints...)
+
+	if len(template.Maintenance) > 0 {
+		windows := make([]configure.MaintenanceWindow, 0, len(template.Maintenance)+len(service.Maintenance))
+		windows = append(windows, template.Maintenance...)
+		service.Maintenance = append(windows, service.Maintenance...)
+	}
+
+	if service.Timeout == 0 {
+		service.Timeout = template.Timeout
+	}
+	if service.MaxRetryTimes == 0 {
+		service.MaxRetryTimes = template.MaxRetryTimes
+	}
+	if service.Interval == "" {
+		service.Interval = template.Interval
+	}
+	if service.AlertAfter == 0 {
+		service.AlertAfter = template.AlertAfter
+	}
+	if service.Flapping == nil {
+		service.Flapping = template.Flapping
+	}
+	if service.Severity == "" {
+		service.Severity = template.Severity
+	}
+	service.Tags = mergeTags(template.Tags, service.Tags)
+}
+
+// mergeTags returns the template tags followed by the service tags that are not among them
+func mergeTags(templateTags, serviceTags []string) []string {
+	if len(templateTags) == 0 {
+		return serviceTags
+	}
+	tags := append([]string{}, templateTags...)
+	for _, tag := range serviceTags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// substituteVars replaces the {{var(name)}} references in the URL, headers, body and response regex of an endpoint
+func (v *validator) substituteVars(node *yaml.Node, serviceName string, endpoint *configure.Endpoint, vars map[string]string) {
+	var undefined []string
+	substitute := func(input string) string {
+		result, missing := params.SubstituteVars(input, vars)
+		undefined = append(undefined, missing...)
+		return result
+	}
+
+	endpoint.URL = substitute(endpoint.URL)
+	endpoint.Body = substitute(endpoint.Body)
+	endpoint.ResponseRegex = substitute(endpoint.ResponseRegex)
+	if endpoint.Headers != nil {
+		// the map may be shared with a template, so the substituted headers go into a new one
+		headers := make(map[string]string, len(endpoint.Headers))
+		for key, value := range endpoint.Headers {
+			headers[key] = substitute(value)
+		}
+		endpoint.Headers = headers
+	}
+
+	reported := make(map[string]bool)
+	for _, name := range undefined {
+		if !reported[name] {
+			reported[name] = true
+			v.add(node, "undefined variable %q in service %s", name, serviceName)
+		}
+	}
+}
+
+// mergeVars returns the template variables overridden by the service variables
+func mergeVars(templateVars, serviceVars map[string]string) map[string]string {
+	vars := make(map[string]string, len(templateVars)+len(serviceVars))
+	for name, value := range templateVars {
+		vars[name] = value
+	}
+	for name, value := range serviceVars {
+		vars[name] = value
+	}
+	return vars
+}
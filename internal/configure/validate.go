@@ -0,0 +1,619 @@
+package configure
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	notifierStructure "github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/types/cron"
+
+	"gopkg.in/yaml.v3"
+)
+
+// supportedMethods lists the HTTP methods the checker can send
+var supportedMethods = []string{"GET", "POST", "PUT"}
+
+// supportedNotificationMethods lists the notification channels the notifier knows about
+var supportedNotificationMethods = []string{"default", "email", "discord", "slack", "telegram", "wechat", "webhook"}
+
+// supportedSeverities lists the severities a service can give its alerts
+var supportedSeverities = []string{"critical", "warning", "info"}
+
+// supportedAlertTypes lists the alert types notification routes can match
+var supportedAlertTypes = []string{notifierStructure.AlertDown, notifierStructure.AlertRecovered, notifierStructure.AlertFlapping, notifierStructure.AlertStable, notifierStructure.AlertCert, notifierStructure.AlertReminder}
+
+// supportedStorageTypes lists the backends the check history can be stored in
+var supportedStorageTypes = []string{"json", "sqlite"}
+
+// typeErrorLine matches the line prefix of the messages in a yaml.TypeError
+var typeErrorLine = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// validator collects the problems found in a configuration file
+type validator struct {
+	file string
+	errs configure.ValidationErrors
+}
+
+// add records a problem at the position of node
+func (v *validator) add(node *yaml.Node, format string, args ...any) {
+	err := configure.ValidationError{File: v.file, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		err.Line = node.Line
+		err.Column = node.Column
+	}
+	v.errs = append(v.errs, err)
+}
+
+// addTypeErrors records the problems reported by the YAML decoder
+func (v *validator) addTypeErrors(typeErr *yaml.TypeError) {
+	for _, message := range typeErr.Errors {
+		err := configure.ValidationError{File: v.file, Message: message}
+		if matches := typeErrorLine.FindStringSubmatch(message); matches != nil {
+			err.Line, _ = strconv.Atoi(matches[1])
+			err.Message = matches[2]
+		}
+		v.errs = append(v.errs, err)
+	}
+}
+
+// checkUnknownFields reports every mapping key that does not match a yaml tag of t
+func (v *validator) checkUnknownFields(node *yaml.Node, t reflect.Type, path string) {
+	if node == nil {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case node.Kind == yaml.DocumentNode:
+		for _, child := range node.Content {
+			v.checkUnknownFields(child, t, path)
+		}
+	case t.Kind() == reflect.Struct && node.Kind == yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			fieldType, known := fields[key.Value]
+			if !known {
+				if path == "" {
+					v.add(key, "unknown field %q", key.Value)
+				} else {
+					v.add(key, "unknown field %q in %s", key.Value, path)
+				}
+				continue
+			}
+			v.checkUnknownFields(value, fieldType, joinPath(path, key.Value))
+		}
+	case t.Kind() == reflect.Slice && node.Kind == yaml.SequenceNode:
+		for i, child := range node.Content {
+			v.checkUnknownFields(child, t.Elem(), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case t.Kind() == reflect.Map && node.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.checkUnknownFields(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value))
+		}
+	}
+}
+
+// validateConfig checks the values of the decoded configuration.
+// serviceNodes and endpointNodes hold the YAML nodes of every service and its endpoints, in the order of cfg.Services.
+func (v *validator) validateConfig(root *yaml.Node, cfg *configure.Configure, serviceNodes []*yaml.Node, endpointNodes [][]*yaml.Node) {
+	if cfg.Interval != "" {
+		if d, err := time.ParseDuration(cfg.Interval); err != nil || d <= 0 {
+			v.add(mappingValue(root, "interval"), "invalid interval %q: must be a positive duration such as 30s or 1h", cfg.Interval)
+		} else {
+			cfg.ParsedInterval = d
+		}
+	}
+
+	if len(cfg.Services) == 0 {
+		v.add(firstNonNil(mappingValue(root, "services"), root), "no services defined")
+	}
+
+	if cfg.AlertAfter < 0 {
+		v.add(mappingValue(root, "alert_after"), "invalid alert_after %d: must be a positive number of checks", cfg.AlertAfter)
+	}
+	v.validateFlapping(mappingValue(root, "flapping"), cfg.Flapping, "")
+
+	// the settings of a template are shared by the services extending it, so they are checked once, in the template
+	templatesNode := mappingValue(root, "templates")
+	for _, name := range slices.Sorted(maps.Keys(cfg.Templates)) {
+		templateNode := mappingValue(templatesNode, name)
+		v.validateFlapping(mappingValue(templateNode, "flapping"), cfg.Templates[name].Flapping, "template "+name)
+	}
+
+	// services may come from included files, so problems are reported in the file of each service
+	mainFile := v.file
+	serviceNames := make(map[string]configure.ValidationError)
+	for i := range cfg.Services {
+		service := &cfg.Services[i]
+		serviceNode := serviceNodes[i]
+		if service.Source != "" {
+			v.file = service.Source
+		}
+		v.validateService(serviceNode, service, endpointNodes[i])
+
+		nameNode := firstNonNil(mappingValue(serviceNode, "name"), serviceNode)
+		if service.Name == "" {
+			continue
+		}
+		if first, exists := serviceNames[service.Name]; exists {
+			v.add(nameNode, "duplicate service name %q, first defined at %s:%d", service.Name, first.File, first.Line)
+			continue
+		}
+		serviceNames[service.Name] = configure.ValidationError{File: v.file, Line: nodeLine(nameNode)}
+	}
+	v.file = mainFile
+
+	if cfg.Storage != nil && cfg.Storage.Type != "" && !containsFold(supportedStorageTypes, cfg.Storage.Type) {
+		v.add(mappingValue(mappingValue(root, "storage"), "type"), "unknown storage type %q, expected one of %s",
+			cfg.Storage.Type, strings.Join(supportedStorageTypes, ", "))
+	}
+
+	if cfg.Notifications != nil {
+		v.validateNotifications(mappingValue(root, "notifications"), cfg.Notifications)
+	}
+}
+
+// validateNotifications checks the notification methods, the named channels, the routes, the escalation policies,
+// the message templates and the report URL
+func (v *validator) validateNotifications(node *yaml.Node, notifications *configure.NotificationConfig) {
+	channelNames := make(map[string]bool)
+	channelsNode := mappingValue(node, "channels")
+	for i, channel := range notifications.Channels {
+		channelNode := firstNonNil(sequenceItem(channelsNode, i), channelsNode, node)
+		nameNode := firstNonNil(mappingValue(channelNode, "name"), channelNode)
+		switch {
+		case channel.Name == "":
+			v.add(channelNode, "notification channel has no name")
+		case containsFold(supportedNotificationMethods, channel.Name):
+			v.add(nameNode, "notification channel name %q is reserved for the %s method", channel.Name, strings.ToLower(channel.Name))
+		case channelNames[channel.Name]:
+			v.add(nameNode, "duplicate notification channel name %q", channel.Name)
+		default:
+			channelNames[channel.Name] = true
+		}
+		v.validateChannel(channelNode, channel)
+		v.validateMessage(mappingValue(channelNode, "message"), channel.Message, "notification channel "+channel.Name)
+	}
+
+	methodsNode := mappingValue(node, "methods")
+	for i, method := range notifications.Methods {
+		if !containsFold(supportedNotificationMethods, method) && !channelNames[method] {
+			v.add(firstNonNil(sequenceItem(methodsNode, i), methodsNode), "unknown notification method %q, expected one of %s or a channel name",
+				method, strings.Join(supportedNotificationMethods, ", "))
+		}
+	}
+
+	routesNode := mappingValue(node, "routes")
+	for i, route := range notifications.Routes {
+		routeNode := firstNonNil(sequenceItem(routesNode, i), routesNode, node)
+		item := func(key string, j int) *yaml.Node {
+			listNode := mappingValue(routeNode, key)
+			return firstNonNil(sequenceItem(listNode, j), listNode, routeNode)
+		}
+
+		if len(route.Channels) == 0 {
+			v.add(routeNode, "notification route has no channels")
+		}
+		v.validateChannelNames(item, route.Channels, channelNames, "route")
+		v.validateConditions(item, route.Services, route.Severities, "route")
+		for j, alertType := range route.Alerts {
+			if !containsFold(supportedAlertTypes, alertType) {
+				v.add(item("alerts", j), "unknown alert type %q in route, expected one of %s", alertType, strings.Join(supportedAlertTypes, ", "))
+			}
+		}
+	}
+
+	v.validateEscalations(node, notifications, channelNames)
+	v.validateMessage(mappingValue(node, "message"), notifications.Message, "notifications")
+
+	if notifications.ReportURL != "" {
+		urlNode := mappingValue(node, "report_url")
+		if parsedURL, err := url.Parse(notifications.ReportURL); err != nil {
+			v.add(urlNode, "invalid report_url %q: %v", notifications.ReportURL, err)
+		} else if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+			v.add(urlNode, "invalid report_url %q: scheme must be http or https", notifications.ReportURL)
+		}
+	}
+}
+
+// validateMessage checks that the title and body templates of owner parse and execute with sample alerts
+func (v *validator) validateMessage(node *yaml.Node, message *configure.MessageTemplate, owner string) {
+	if message == nil {
+		return
+	}
+	for _, part := range []struct{ key, text string }{{"title", message.Title}, {"body", message.Body}} {
+		if part.text == "" {
+			continue
+		}
+		if err := notifier.CheckMessageTemplate(part.text); err != nil {
+			v.add(firstNonNil(mappingValue(node, part.key), node), "invalid %s template in %s: %v", part.key, owner, err)
+		}
+	}
+}
+
+// validateEscalations checks that every escalation policy has a unique name, valid conditions
+// and steps notifying known channels in the order of their delay
+func (v *validator) validateEscalations(node *yaml.Node, notifications *configure.NotificationConfig, channelNames map[string]bool) {
+	policyNames := make(map[string]bool)
+	escalationsNode := mappingValue(node, "escalations")
+	for i := range notifications.Escalations {
+		escalation := &notifications.Escalations[i]
+		escalationNode := firstNonNil(sequenceItem(escalationsNode, i), escalationsNode, node)
+		item := func(key string, j int) *yaml.Node {
+			listNode := mappingValue(escalationNode, key)
+			return firstNonNil(sequenceItem(listNode, j), listNode, escalationNode)
+		}
+
+		switch {
+		case escalation.Name == "":
+			v.add(escalationNode, "escalation policy has no name")
+		case policyNames[escalation.Name]:
+			v.add(firstNonNil(mappingValue(escalationNode, "name"), escalationNode), "duplicate escalation policy name %q", escalation.Name)
+		default:
+			policyNames[escalation.Name] = true
+		}
+		v.validateConditions(item, escalation.Services, escalation.Severities, "escalation policy")
+
+		if len(escalation.Steps) == 0 {
+			v.add(firstNonNil(mappingValue(escalationNode, "steps"), escalationNode), "escalation policy %s has no steps", escalation.Name)
+		}
+		var previousAfter time.Duration
+		for j := range escalation.Steps {
+			step := &escalation.Steps[j]
+			stepNode := item("steps", j)
+			if step.After != "" {
+				if d, err := time.ParseDuration(step.After); err != nil || d < 0 {
+					v.add(firstNonNil(mappingValue(stepNode, "after"), stepNode), "invalid escalation delay %q in policy %s: must be a duration such as 15m or 1h", step.After, escalation.Name)
+				} else {
+					step.ParsedAfter = d
+				}
+			}
+			if step.ParsedAfter < previousAfter {
+				v.add(firstNonNil(mappingValue(stepNode, "after"), stepNode), "escalation step of policy %s comes before the previous step", escalation.Name)
+			}
+			previousAfter = step.ParsedAfter
+
+			if len(step.Channels) == 0 {
+				v.add(stepNode, "escalation step of policy %s has no channels", escalation.Name)
+			}
+			v.validateChannelNames(func(key string, k int) *yaml.Node {
+				listNode := mappingValue(stepNode, key)
+				return firstNonNil(sequenceItem(listNode, k), listNode, stepNode)
+			}, step.Channels, channelNames, "escalation step")
+		}
+
+		if escalation.Repeat != "" {
+			if d, err := time.ParseDuration(escalation.Repeat); err != nil || d <= 0 {
+				v.add(firstNonNil(mappingValue(escalationNode, "repeat"), escalationNode), "invalid reminder interval %q in policy %s: must be a positive duration such as 30m or 2h", escalation.Repeat, escalation.Name)
+			} else {
+				escalation.ParsedRepeat = d
+			}
+		}
+	}
+}
+
+// validateChannelNames checks that every channel a route or an escalation step notifies is a method or a named channel.
+// item returns the node of the j-th value of a list.
+func (v *validator) validateChannelNames(item func(key string, j int) *yaml.Node, channels []string, channelNames map[string]bool, owner string) {
+	for j, channel := range channels {
+		if !containsFold(supportedNotificationMethods, channel) && !channelNames[channel] {
+			v.add(item("channels", j), "unknown notification channel %q in %s", channel, owner)
+		}
+	}
+}
+
+// validateConditions checks the service patterns and the severities a route or an escalation policy matches
+func (v *validator) validateConditions(item func(key string, j int) *yaml.Node, services, severities []string, owner string) {
+	for j, pattern := range services {
+		if _, err := path.Match(pattern, ""); err != nil {
+			v.add(item("services", j), "invalid service pattern %q in %s: %v", pattern, owner, err)
+		}
+	}
+	for j, severity := range severities {
+		if !containsFold(supportedSeverities, severity) {
+			v.add(item("severities", j), "unknown severity %q in %s, expected one of %s", severity, owner, strings.Join(supportedSeverities, ", "))
+		}
+	}
+}
+
+// validateChannel checks that a named channel has a known type and only the settings of that type
+func (v *validator) validateChannel(node *yaml.Node, channel configure.ChannelConfig) {
+	typeNode := firstNonNil(mappingValue(node, "type"), node)
+	if !containsFold(supportedNotificationMethods, channel.Type) {
+		v.add(typeNode, "unknown type %q for notification channel %s, expected one of %s",
+			channel.Type, channel.Name, strings.Join(supportedNotificationMethods, ", "))
+		return
+	}
+
+	settings := map[string]bool{
+		"default":  channel.Default != nil,
+		"discord":  channel.Discord != nil,
+		"email":    channel.Email != nil,
+		"slack":    channel.Slack != nil,
+		"telegram": channel.Telegram != nil,
+		"wechat":   channel.WeChat != nil,
+		"webhook":  channel.Webhook != nil,
+	}
+	channelType := strings.ToLower(channel.Type)
+	for _, settingsType := range supportedNotificationMethods {
+		if settings[settingsType] && settingsType != channelType {
+			v.add(firstNonNil(mappingValue(node, settingsType), node), "notification channel %s of type %s has %s settings", channel.Name, channelType, settingsType)
+		}
+	}
+	if !settings[channelType] && channelType != "default" {
+		v.add(typeNode, "notification channel %s of type %s has no %s settings", channel.Name, channelType, channelType)
+	}
+}
+
+// validateService checks a single service and its endpoints
+func (v *validator) validateService(node *yaml.Node, service *configure.Service, endpointNodes []*yaml.Node) {
+	if service.Name == "" {
+		v.add(node, "service has no name")
+	}
+
+	if service.Interval != "" {
+		if d, err := time.ParseDuration(service.Interval); err != nil || d <= 0 {
+			v.add(mappingValue(node, "interval"), "invalid interval %q for service %s: must be a positive duration such as 30s or 1h", service.Interval, service.Name)
+		} else {
+			service.ParsedInterval = d
+		}
+	}
+
+	if service.AlertAfter < 0 {
+		v.add(mappingValue(node, "alert_after"), "invalid alert_after %d for service %s: must be a positive number of checks", service.AlertAfter, service.Name)
+	}
+	if flappingNode := mappingValue(node, "flapping"); flappingNode != nil {
+		v.validateFlapping(flappingNode, service.Flapping, "service "+service.Name)
+	}
+	v.validateMaintenance(node, service)
+
+	if service.Severity != "" && !containsFold(supportedSeverities, service.Severity) {
+		v.add(mappingValue(node, "severity"), "unknown severity %q for service %s, expected one of %s",
+			service.Severity, service.Name, strings.Join(supportedSeverities, ", "))
+	}
+
+	endpointsNode := mappingValue(node, "endpoints")
+	if len(service.Endpoints) == 0 {
+		v.add(firstNonNil(endpointsNode, node), "service %s has no endpoints", service.Name)
+	}
+
+	endpointKeys := make(map[string]*yaml.Node)
+	for i := range service.Endpoints {
+		endpoint := &service.Endpoints[i]
+		endpointNode := endpointNodes[i]
+		v.validateEndpoint(endpointNode, endpoint)
+
+		if endpoint.Key == "" {
+			continue
+		}
+		keyNode := firstNonNil(mappingValue(endpointNode, "id"), mappingValue(endpointNode, "url"), endpointNode)
+		if first, exists := endpointKeys[endpoint.Key]; exists {
+			if endpoint.ID != "" {
+				v.add(keyNode, "duplicate endpoint id %q in service %s, first defined at line %d", endpoint.ID, service.Name, first.Line)
+			} else {
+				v.add(keyNode, "duplicate endpoint %q in service %s, first defined at line %d: set an id to tell them apart", endpoint.Key, service.Name, first.Line)
+			}
+			continue
+		}
+		endpointKeys[endpoint.Key] = keyNode
+	}
+
+	// a previous id must not point at the history of another current endpoint
+	for i, endpoint := range service.Endpoints {
+		previousIDsNode := mappingValue(endpointNodes[i], "previous_ids")
+		for j, previousID := range endpoint.PreviousIDs {
+			if previousID == endpoint.Key {
+				continue
+			}
+			if _, exists := endpointKeys[previousID]; exists {
+				v.add(firstNonNil(sequenceItem(previousIDsNode, j), previousIDsNode, endpointNodes[i]),
+					"previous id %q of endpoint %s is still used by another endpoint in service %s", previousID, endpoint.Key, service.Name)
+			}
+		}
+	}
+}
+
+// validateFlapping checks the flapping settings of owner, or the global ones if owner is empty
+func (v *validator) validateFlapping(node *yaml.Node, flapping *configure.FlappingConfig, owner string) {
+	if flapping == nil {
+		return
+	}
+	suffix := ""
+	if owner != "" {
+		suffix = " for " + owner
+	}
+
+	if flapping.Changes <= 0 {
+		v.add(firstNonNil(mappingValue(node, "changes"), node), "invalid flapping changes %d%s: must be a positive number of status changes", flapping.Changes, suffix)
+	}
+	if d, err := time.ParseDuration(flapping.Window); err != nil || d <= 0 {
+		v.add(firstNonNil(mappingValue(node, "window"), node), "invalid flapping window %q%s: must be a positive duration such as 30m or 2h", flapping.Window, suffix)
+	} else {
+		flapping.ParsedWindow = d
+	}
+}
+
+// validateMaintenance checks the maintenance windows of a service.
+// Windows taken from a template are reported at the extends key of the service.
+func (v *validator) validateMaintenance(node *yaml.Node, service *configure.Service) {
+	maintenanceNode := mappingValue(node, "maintenance")
+	inherited := len(service.Maintenance)
+	if maintenanceNode != nil && maintenanceNode.Kind == yaml.SequenceNode {
+		inherited -= len(maintenanceNode.Content)
+	}
+
+	for i := range service.Maintenance {
+		window := &service.Maintenance[i]
+		windowNode := firstNonNil(mappingValue(node, "extends"), node)
+		if i >= inherited {
+			windowNode = firstNonNil(sequenceItem(maintenanceNode, i-inherited), maintenanceNode, node)
+		}
+		fieldNode := func(key string) *yaml.Node {
+			return firstNonNil(mappingValue(windowNode, key), windowNode)
+		}
+
+		switch {
+		case window.Schedule != "" && (window.Start != "" || window.End != ""):
+			v.add(windowNode, "maintenance window of service %s sets both a schedule and start or end", service.Name)
+			continue
+		case window.Schedule == "" && (window.Start == "" || window.End == ""):
+			v.add(windowNode, "maintenance window of service %s needs either start and end, or schedule and duration", service.Name)
+			continue
+		}
+
+		if window.Schedule == "" {
+			start, startErr := time.Parse(time.RFC3339, window.Start)
+			if startErr != nil {
+				v.add(fieldNode("start"), "invalid maintenance start %q for service %s: must be RFC 3339 such as 2025-01-02T22:00:00Z", window.Start, service.Name)
+			}
+			end, endErr := time.Parse(time.RFC3339, window.End)
+			if endErr != nil {
+				v.add(fieldNode("end"), "invalid maintenance end %q for service %s: must be RFC 3339 such as 2025-01-02T23:00:00Z", window.End, service.Name)
+			}
+			if startErr == nil && endErr == nil {
+				if !end.After(start) {
+					v.add(fieldNode("end"), "maintenance end %s of service %s is not after its start %s", window.End, service.Name, window.Start)
+				} else {
+					window.ParsedStart, window.ParsedEnd = start, end
+				}
+			}
+			continue
+		}
+
+		if schedule, err := cron.ParseSchedule(window.Schedule); err != nil {
+			v.add(fieldNode("schedule"), "invalid maintenance schedule %q for service %s: %v", window.Schedule, service.Name, err)
+		} else {
+			window.ParsedSchedule = &schedule
+		}
+		if d, err := time.ParseDuration(window.Duration); err != nil || d <= 0 {
+			v.add(fieldNode("duration"), "invalid maintenance duration %q for service %s: must be a positive duration such as 30m or 2h", window.Duration, service.Name)
+		} else {
+			window.ParsedDuration = d
+		}
+		if window.Timezone != "" {
+			if location, err := time.LoadLocation(window.Timezone); err != nil {
+				v.add(fieldNode("timezone"), "unknown maintenance timezone %q for service %s", window.Timezone, service.Name)
+			} else {
+				window.ParsedLocation = location
+			}
+		}
+	}
+}
+
+// validateEndpoint checks the URL, method, status code and response regex of an endpoint
+func (v *validator) validateEndpoint(node *yaml.Node, endpoint *configure.Endpoint) {
+	urlNode := firstNonNil(mappingValue(node, "url"), node)
+	if endpoint.URL == "" {
+		v.add(urlNode, "endpoint has no url")
+	} else if parsedURL, err := url.Parse(endpoint.ParsedURL); err != nil {
+		v.add(urlNode, "invalid url %q: %v", endpoint.URL, err)
+	} else if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		v.add(urlNode, "invalid url %q: scheme must be http or https", endpoint.URL)
+	} else if parsedURL.Host == "" {
+		v.add(urlNode, "invalid url %q: missing host", endpoint.URL)
+	}
+
+	if endpoint.Method != "" && !containsFold(supportedMethods, endpoint.Method) {
+		v.add(mappingValue(node, "method"), "unsupported method %q, expected one of %s", endpoint.Method, strings.Join(supportedMethods, ", "))
+	}
+
+	if endpoint.StatusCode != 0 && (endpoint.StatusCode < 100 || endpoint.StatusCode > 599) {
+		v.add(mappingValue(node, "status_code"), "invalid status_code %d: must be between 100 and 599", endpoint.StatusCode)
+	}
+
+	if endpoint.ResponseRegex != "" {
+		if _, err := regexp.Compile(endpoint.ResponseRegex); err != nil {
+			v.add(mappingValue(node, "response_regex"), "invalid response_regex %q: %v", endpoint.ResponseRegex, err)
+		}
+	}
+}
+
+// yamlFields maps the yaml keys of a struct type to the types of their fields
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// mappingValue returns the value node of key in a mapping node, or nil if it is absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node != nil && node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItem returns the i-th item of a sequence node, or nil if it is absent
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
+
+// nodeLine returns the line of node, or 0 if it is nil
+func nodeLine(node *yaml.Node) int {
+	if node == nil {
+		return 0
+	}
+	return node.Line
+}
+
+// firstNonNil returns the first node that is not nil
+func firstNonNil(nodes ...*yaml.Node) *yaml.Node {
+	for _, node := range nodes {
+		if node != nil {
+			return node
+		}
+	}
+	return nil
+}
+
+// joinPath appends a key to a dotted field path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
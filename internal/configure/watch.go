@@ -0,0 +1,84 @@
+package configure
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
+)
+
+// fileState is the modification time and size of a watched file
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watcher detects changes to the files a configuration is loaded from,
+// including files added to or removed from the include globs and the services.d directory
+type Watcher struct {
+	path     string
+	patterns []string
+	files    map[string]fileState
+}
+
+// NewWatcher creates a watcher for the configuration loaded from path
+func NewWatcher(path string, cfg *configure.Configure) *Watcher {
+	w := &Watcher{path: path}
+	w.Update(cfg)
+	return w
+}
+
+// Update records the current state of the files of cfg, so that only later changes are reported
+func (w *Watcher) Update(cfg *configure.Configure) {
+	if cfg != nil {
+		w.patterns = cfg.Include
+	}
+	w.files = w.snapshot()
+}
+
+// Changed reports whether any watched file was modified, added or removed since the last Update
+func (w *Watcher) Changed() bool {
+	current := w.snapshot()
+	if len(current) != len(w.files) {
+		return true
+	}
+	for path, state := range current {
+		previous, exists := w.files[path]
+		if !exists || !previous.modTime.Equal(state.modTime) || previous.size != state.size {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot returns the state of the configuration file and every file it would include
+func (w *Watcher) snapshot() map[string]fileState {
+	baseDir := filepath.Dir(w.path)
+	patterns := []string{w.path}
+	for _, pattern := range w.patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(baseDir, pattern)
+		}
+		patterns = append(patterns, pattern)
+	}
+	servicesDir := filepath.Join(baseDir, default_config.GetServicesDir())
+	patterns = append(patterns, filepath.Join(servicesDir, "*.yaml"), filepath.Join(servicesDir, "*.yml"))
+
+	files := make(map[string]fileState)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			info, err := os.Stat(match)
+			if err != nil || info.IsDir() {
+				continue
+			}
+			files[filepath.Clean(match)] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+	return files
+}
@@ -0,0 +1,46 @@
+package configure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte("services: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	w := NewWatcher(path, nil)
+	if w.Changed() {
+		t.Error("Expected no change right after creating the watcher")
+	}
+
+	// a new file in services.d is picked up
+	if err := os.MkdirAll(filepath.Join(dir, "services.d"), 0755); err != nil {
+		t.Fatalf("Failed to create services.d: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "services.d", "a.yaml"), []byte("services: []\n"), 0644); err != nil {
+		t.Fatalf("Failed to write included file: %v", err)
+	}
+	if !w.Changed() {
+		t.Error("Expected a change after adding a file to services.d")
+	}
+	w.Update(nil)
+
+	// modifying the main file is picked up
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Failed to touch config: %v", err)
+	}
+	if !w.Changed() {
+		t.Error("Expected a change after modifying the config file")
+	}
+	w.Update(nil)
+	if w.Changed() {
+		t.Error("Expected no change after Update")
+	}
+}
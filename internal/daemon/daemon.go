@@ -0,0 +1,266 @@
+package daemon
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/announcement"
+	"github.com/wcy-dt/ponghub/internal/checker"
+	"github.com/wcy-dt/ponghub/internal/incident"
+	"github.com/wcy-dt/ponghub/internal/logger"
+	"github.com/wcy-dt/ponghub/internal/metrics"
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	"github.com/wcy-dt/ponghub/internal/reporter"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/telemetry"
+	checkerStructure "github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	reporterStructure "github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
+)
+
+// Daemon keeps checking services on their own intervals until it is stopped
+type Daemon struct {
+	cfg        *configure.Configure
+	exporter   *telemetry.Exporter
+	paths      configure.Paths
+	store      storage.Store
+	nextRun    map[string]time.Time
+	lastResult map[string]checkerStructure.Service
+	onReport   []func([]checkerStructure.Service, reporterStructure.Reporter)
+
+	mu       sync.Mutex
+	pending  *configure.Configure
+	reloaded chan struct{}
+}
+
+// New creates a daemon for the given configuration that writes the files at paths and keeps the history in store
+func New(cfg *configure.Configure, paths configure.Paths, store storage.Store) *Daemon {
+	return &Daemon{
+		cfg:        cfg,
+		exporter:   telemetry.NewExporter(cfg.Telemetry),
+		paths:      paths,
+		store:      store,
+		nextRun:    make(map[string]time.Time),
+		lastResult: make(map[string]checkerStructure.Service),
+		reloaded:   make(chan struct{}, 1),
+	}
+}
+
+// Reload replaces the configuration before the next cycle.
+// Unchanged services keep their schedule, while new and changed services are checked right away.
+func (d *Daemon) Reload(cfg *configure.Configure) {
+	d.mu.Lock()
+	d.pending = cfg
+	d.mu.Unlock()
+
+	select {
+	case d.reloaded <- struct{}{}:
+	default:
+	}
+}
+
+// OnReport registers fn to be called with the latest check results and report data after every cycle
+func (d *Daemon) OnReport(fn func([]checkerStructure.Service, reporterStructure.Reporter)) {
+	d.onReport = append(d.onReport, fn)
+}
+
+// Run checks the services whenever they are due until ctx is cancelled.
+// A cycle that is already running is always completed before Run returns.
+func (d *Daemon) Run(ctx context.Context) error {
+	log.Printf("Daemon started with %d service(s)", len(d.cfg.Services))
+
+	for {
+		d.runCycle(time.Now())
+
+		wait := time.Until(d.nextWakeup())
+		if wait < 0 {
+			wait = 0
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Daemon stopped")
+			return nil
+		case <-d.reloaded:
+			timer.Stop()
+			d.applyPending()
+		case <-timer.C:
+		}
+	}
+}
+
+// applyPending swaps to the configuration passed to Reload, keeping the state of unchanged services
+func (d *Daemon) applyPending() {
+	d.mu.Lock()
+	cfg := d.pending
+	d.pending = nil
+	d.mu.Unlock()
+	if cfg == nil {
+		return
+	}
+
+	previous := make(map[string]configure.Service, len(d.cfg.Services))
+	for _, service := range d.cfg.Services {
+		previous[service.Name] = service
+	}
+
+	current := make(map[string]bool, len(cfg.Services))
+	changed := 0
+	for _, service := range cfg.Services {
+		current[service.Name] = true
+		if old, exists := previous[service.Name]; !exists || !sameService(old, service) {
+			// check new and changed services in the next cycle
+			delete(d.nextRun, service.Name)
+			changed++
+		}
+	}
+	removed := 0
+	for name := range previous {
+		if !current[name] {
+			delete(d.nextRun, name)
+			delete(d.lastResult, name)
+			removed++
+		}
+	}
+
+	if !reflect.DeepEqual(d.cfg.Telemetry, cfg.Telemetry) {
+		d.exporter = telemetry.NewExporter(cfg.Telemetry)
+	}
+	if !reflect.DeepEqual(d.cfg.Storage, cfg.Storage) {
+		log.Println("Storage configuration changed, restart PongHub to apply it")
+	}
+	d.cfg = cfg
+	log.Printf("Configuration reloaded: %d service(s), %d new or changed, %d removed", len(cfg.Services), changed, removed)
+}
+
+// sameService reports whether two service configurations check the same endpoints in the same way.
+// Values resolved from dynamic parameters are ignored, since they differ on every load.
+func sameService(a, b configure.Service) bool {
+	return reflect.DeepEqual(comparableService(a), comparableService(b))
+}
+
+// comparableService returns a copy of service without the values resolved from dynamic parameters
+func comparableService(service configure.Service) configure.Service {
+	service.Source = ""
+	endpoints := make([]configure.Endpoint, len(service.Endpoints))
+	for i, endpoint := range service.Endpoints {
+		endpoint.ParsedURL = ""
+		endpoint.ParsedHeaders = nil
+		endpoint.ParsedBody = ""
+		endpoint.ParsedResponseRegex = ""
+		endpoints[i] = endpoint
+	}
+	service.Endpoints = endpoints
+	return service
+}
+
+// runCycle checks all due services, then updates the logs, notifications and report
+func (d *Daemon) runCycle(now time.Time) {
+	due := d.dueServices(now)
+	if len(due) == 0 {
+		return
+	}
+
+	// check only the due services
+	dueCfg := *d.cfg
+	dueCfg.Services = due
+	freshResult := checker.CheckServices(&dueCfg)
+	for _, serviceResult := range freshResult {
+		d.lastResult[serviceResult.Name] = serviceResult
+	}
+	for _, service := range due {
+		d.nextRun[service.Name] = now.Add(service.ParsedInterval)
+	}
+	knownResult := d.knownResults()
+
+	// export probe results to the OpenTelemetry collector
+	if err := d.exporter.Export(freshResult); err != nil {
+		log.Println("Error exporting telemetry:", err)
+	}
+
+	// get and write log results
+	logResult, err := logger.UpdatePartialLog(freshResult, knownResult, d.cfg, d.store)
+	if err != nil {
+		log.Println("Error updating logs:", err)
+		return
+	}
+
+	// notify the result, comparing with the previous state of every endpoint;
+	// the notification file describes every service, not only those checked in this cycle
+	notifier.WriteNotifications(knownResult, d.cfg.CertNotifyDays, d.paths.Notify)
+	notifier.SendNotifications(freshResult, logResult, d.cfg, d.paths.State, d.paths.Acks)
+
+	// track the incidents found in the history
+	incidents := incident.Track(d.paths.Incidents, logResult, freshResult, d.cfg)
+
+	// regenerate the report
+	reportResult, err := reporter.GetReport(knownResult, d.store, d.cfg)
+	if err != nil {
+		log.Println("Error generating report data:", err)
+		return
+	}
+	announcements, err := announcement.Load(d.paths.Announcements)
+	if err != nil {
+		log.Println("Error loading announcements:", err)
+	}
+	if err := reporter.WriteReport(reportResult, incidents, announcements, d.paths.Report, d.paths.Template, d.cfg.DisplayNum); err != nil {
+		log.Println("Error generating report:", err)
+		return
+	}
+	if d.cfg.MetricsFile != "" {
+		if err := metrics.WriteFile(d.cfg.MetricsFile, knownResult, reportResult); err != nil {
+			log.Println("Error writing metrics to", d.cfg.MetricsFile, ":", err)
+		}
+	}
+	for _, fn := range d.onReport {
+		fn(knownResult, reportResult)
+	}
+
+	log.Printf("Checked %d service(s), report generated at %s", len(due), d.paths.Report)
+}
+
+// dueServices returns the services whose next check time has been reached
+func (d *Daemon) dueServices(now time.Time) []configure.Service {
+	var due []configure.Service
+	for _, service := range d.cfg.Services {
+		next, scheduled := d.nextRun[service.Name]
+		if !scheduled || !next.After(now) {
+			due = append(due, service)
+		}
+	}
+	return due
+}
+
+// nextWakeup returns the earliest time at which a service becomes due
+func (d *Daemon) nextWakeup() time.Time {
+	var earliest time.Time
+	for _, service := range d.cfg.Services {
+		next, scheduled := d.nextRun[service.Name]
+		if !scheduled {
+			return time.Now()
+		}
+		if earliest.IsZero() || next.Before(earliest) {
+			earliest = next
+		}
+	}
+	if earliest.IsZero() {
+		return time.Now().Add(default_config.GetDefaultInterval())
+	}
+	return earliest
+}
+
+// knownResults returns the latest result of every configured service in config order
+func (d *Daemon) knownResults() []checkerStructure.Service {
+	var results []checkerStructure.Service
+	for _, service := range d.cfg.Services {
+		if result, exists := d.lastResult[service.Name]; exists {
+			results = append(results, result)
+		}
+	}
+	return results
+}
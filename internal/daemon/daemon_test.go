@@ -0,0 +1,137 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/storage"
+	checkerStructure "github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+func TestDueServices(t *testing.T) {
+	cfg := &configure.Configure{
+		Services: []configure.Service{
+			{Name: "fast", ParsedInterval: 30 * time.Second},
+			{Name: "slow", ParsedInterval: time.Hour},
+		},
+	}
+	d := New(cfg, configure.Paths{}, nil)
+	now := time.Now()
+
+	// every service is due before its first check
+	if due := d.dueServices(now); len(due) != 2 {
+		t.Fatalf("Expected 2 due services before the first check, got %d", len(due))
+	}
+
+	d.nextRun["fast"] = now.Add(30 * time.Second)
+	d.nextRun["slow"] = now.Add(time.Hour)
+
+	if due := d.dueServices(now); len(due) != 0 {
+		t.Errorf("Expected no due services right after a check, got %d", len(due))
+	}
+
+	due := d.dueServices(now.Add(time.Minute))
+	if len(due) != 1 || due[0].Name != "fast" {
+		t.Errorf("Expected only the fast service to be due after one minute, got %v", due)
+	}
+
+	if wakeup := d.nextWakeup(); !wakeup.Equal(now.Add(30 * time.Second)) {
+		t.Errorf("Expected next wakeup at the fast service's next run, got %v", wakeup)
+	}
+}
+
+func TestReload(t *testing.T) {
+	endpoint := configure.Endpoint{URL: "https://example.com/{{%Y}}", ParsedURL: "https://example.com/2025"}
+	cfg := &configure.Configure{
+		Services: []configure.Service{
+			{Name: "kept", Endpoints: []configure.Endpoint{endpoint}, ParsedInterval: time.Hour},
+			{Name: "changed", ParsedInterval: time.Hour},
+			{Name: "removed", ParsedInterval: time.Hour},
+		},
+	}
+	d := New(cfg, configure.Paths{}, nil)
+	now := time.Now()
+	for _, service := range cfg.Services {
+		d.nextRun[service.Name] = now.Add(time.Hour)
+	}
+
+	// the kept service resolves its parameters to a different value, which is not a change
+	reloadedEndpoint := endpoint
+	reloadedEndpoint.ParsedURL = "https://example.com/2026"
+	d.Reload(&configure.Configure{
+		Services: []configure.Service{
+			{Name: "kept", Endpoints: []configure.Endpoint{reloadedEndpoint}, ParsedInterval: time.Hour, Source: "services.d/kept.yaml"},
+			{Name: "changed", ParsedInterval: time.Minute},
+			{Name: "added", ParsedInterval: time.Hour},
+		},
+	})
+	d.applyPending()
+
+	due := d.dueServices(now)
+	if len(due) != 2 || due[0].Name != "changed" || due[1].Name != "added" {
+		t.Errorf("Expected the changed and added services to be due, got %v", due)
+	}
+	if _, exists := d.nextRun["removed"]; exists {
+		t.Error("Expected the schedule of the removed service to be dropped")
+	}
+	if next := d.nextRun["kept"]; !next.Equal(now.Add(time.Hour)) {
+		t.Errorf("Expected the kept service to keep its schedule, got %v", next)
+	}
+}
+
+func TestRunCycleWithUnreadableLog(t *testing.T) {
+	dir := t.TempDir()
+	paths := configure.Paths{
+		Log:    filepath.Join(dir, "ponghub_log.json"),
+		Notify: filepath.Join(dir, "notify.txt"),
+		State:  filepath.Join(dir, "alert_state.json"),
+		Acks:   filepath.Join(dir, "acknowledgements.json"),
+	}
+	if err := os.WriteFile(paths.Log, []byte("{"), 0644); err != nil {
+		t.Fatalf("Expected no error writing the log, got %v", err)
+	}
+	cfg := &configure.Configure{Services: []configure.Service{{Name: "API", ParsedInterval: time.Hour}}}
+
+	New(cfg, paths, storage.NewJSONStore(paths.Log)).runCycle(time.Now())
+
+	// the cycle stops before comparing with the previous state, as the run command does
+	if _, err := os.Stat(paths.State); !os.IsNotExist(err) {
+		t.Errorf("Expected no alert state to be saved without a readable log, got %v", err)
+	}
+}
+
+func TestRunCycleNotifiesEveryService(t *testing.T) {
+	dir := t.TempDir()
+	paths := configure.Paths{
+		Log:    filepath.Join(dir, "ponghub_log.json"),
+		Notify: filepath.Join(dir, "notify.txt"),
+		State:  filepath.Join(dir, "alert_state.json"),
+		Acks:   filepath.Join(dir, "acknowledgements.json"),
+		Report: filepath.Join(dir, "index.html"),
+	}
+	cfg := &configure.Configure{Services: []configure.Service{
+		{Name: "API", ParsedInterval: time.Minute},
+		{Name: "Website", ParsedInterval: time.Hour},
+	}}
+	d := New(cfg, paths, storage.NewJSONStore(paths.Log))
+	now := time.Now()
+
+	// the website was found down in an earlier cycle and is not due in this one
+	d.nextRun["Website"] = now.Add(time.Hour)
+	d.lastResult["Website"] = checkerStructure.Service{
+		Name:      "Website",
+		Status:    chk_result.NONE,
+		StartTime: now.Format(time.RFC3339),
+		Endpoints: []checkerStructure.Endpoint{{Key: "https://www.example.com", URL: "https://www.example.com", Status: chk_result.NONE, StartTime: now.Format(time.RFC3339)}},
+	}
+	d.runCycle(now)
+
+	if content, err := os.ReadFile(paths.Notify); err != nil || !strings.Contains(string(content), "https://www.example.com") {
+		t.Errorf("Expected the outage of the service not due to stay in the notify file, got %q, %v", content, err)
+	}
+}
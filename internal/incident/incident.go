@@ -0,0 +1,287 @@
+package incident
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/incident"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// retentionDays is how long incidents are kept after they end
+const retentionDays = 365
+
+// Load reads the incidents kept at path; a missing file holds none
+func Load(path string) (incident.Incidents, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var incidents incident.Incidents
+	if err := json.Unmarshal(data, &incidents); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return incidents, nil
+}
+
+// Save writes the incidents to path
+func Save(path string, incidents incident.Incidents) error {
+	if incidents == nil {
+		incidents = incident.Incidents{}
+	}
+	data, err := json.MarshalIndent(incidents, "", "  ")
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, data, 0644)
+}
+
+// Track updates the incidents kept in the file at path with the history in logResult and the first errors
+// in checkResult, and returns them
+func Track(path string, logResult logger.Logger, checkResult []checker.Service, cfg *configure.Configure) incident.Incidents {
+	incidents, err := Load(path)
+	if err != nil {
+		log.Println("Error loading incidents, deriving them from the log only:", err)
+	}
+	incidents = Update(incidents, logResult, checkResult, cfg, time.Now())
+	if err := Save(path, incidents); err != nil {
+		log.Println("Error saving incidents:", err)
+	}
+	return incidents
+}
+
+// Update derives the incidents from the status transitions in logResult and merges them into the known incidents,
+// which keep the incidents whose history has expired. The first error of the endpoints that went down is taken
+// from checkResult, the only place it is known. Incidents that ended more than retentionDays before now are dropped.
+func Update(incidents incident.Incidents, logResult logger.Logger, checkResult []checker.Service, cfg *configure.Configure, now time.Time) incident.Incidents {
+	var derived incident.Incidents
+	for _, service := range cfg.Services {
+		serviceLog, exists := logResult[service.Name]
+		if !exists {
+			continue
+		}
+		urls := make(map[string]string, len(service.Endpoints))
+		for _, endpoint := range service.Endpoints {
+			urls[endpoint.Key] = endpoint.URL
+		}
+		derived = append(derived, deriveIncidents(service.Name, serviceLog.Endpoints, urls)...)
+	}
+
+	merged := make([]bool, len(incidents))
+	updated := make(incident.Incidents, 0, len(incidents)+len(derived))
+	for _, d := range derived {
+		for i := range incidents {
+			if !merged[i] && overlaps(incidents[i], d) {
+				d = mergeIncident(incidents[i], d)
+				merged[i] = true
+			}
+		}
+		updated = append(updated, d)
+	}
+	for i, known := range incidents {
+		if merged[i] {
+			continue
+		}
+		if known.End == "" {
+			// the history no longer shows the outage, e.g. because the service was removed
+			known.End = now.Format(time.RFC3339)
+		}
+		updated = append(updated, known)
+	}
+
+	recordFirstErrors(updated, checkResult)
+
+	cutoff := now.AddDate(0, 0, -retentionDays)
+	updated = slices.DeleteFunc(updated, func(inc incident.Incident) bool {
+		end, err := time.Parse(time.RFC3339, inc.End)
+		return err == nil && end.Before(cutoff)
+	})
+	slices.SortStableFunc(updated, func(a, b incident.Incident) int {
+		return compareTimes(b.Start, a.Start)
+	})
+	return updated
+}
+
+// deriveIncidents finds the outages of each endpoint in its history, then merges the overlapping outages
+// of the endpoints of a service into incidents. Checks during maintenance neither start nor end an outage.
+func deriveIncidents(serviceName string, endpoints logger.Endpoints, urls map[string]string) incident.Incidents {
+	var outages []incident.Endpoint
+	for key, history := range endpoints {
+		history = slices.Clone(history)
+		slices.SortStableFunc(history, func(a, b logger.HistoryEntry) int { return compareTimes(a.Time, b.Time) })
+
+		var open *incident.Endpoint
+		for _, entry := range history {
+			switch {
+			case chk_result.IsMaintenance(entry.Status):
+				continue
+			case chk_result.ParseCheckResult(entry.Status) == chk_result.NONE:
+				if open == nil {
+					open = &incident.Endpoint{Key: key, URL: urls[key], Start: entry.Time}
+				}
+			case open != nil:
+				open.End = entry.Time
+				outages = append(outages, *open)
+				open = nil
+			}
+		}
+		if open != nil {
+			outages = append(outages, *open)
+		}
+	}
+	slices.SortStableFunc(outages, func(a, b incident.Endpoint) int {
+		if c := compareTimes(a.Start, b.Start); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	var incidents incident.Incidents
+	for _, outage := range outages {
+		last := len(incidents) - 1
+		if last < 0 || (incidents[last].End != "" && compareTimes(outage.Start, incidents[last].End) > 0) {
+			incidents = append(incidents, incident.Incident{
+				Service:   serviceName,
+				Start:     outage.Start,
+				End:       outage.End,
+				Endpoints: []incident.Endpoint{outage},
+			})
+			continue
+		}
+
+		// the outage overlaps the last incident, which lasts until every outage in it has ended
+		current := &incidents[last]
+		current.End = laterEnd(current.End, outage.End)
+		i := slices.IndexFunc(current.Endpoints, func(e incident.Endpoint) bool { return e.Key == outage.Key })
+		if i < 0 {
+			current.Endpoints = append(current.Endpoints, outage)
+		} else {
+			current.Endpoints[i].End = laterEnd(current.Endpoints[i].End, outage.End)
+		}
+	}
+	return incidents
+}
+
+// laterEnd returns the later of two end times, or an empty end if either is ongoing
+func laterEnd(a, b string) string {
+	if a == "" || b == "" {
+		return ""
+	}
+	if compareTimes(a, b) >= 0 {
+		return a
+	}
+	return b
+}
+
+// overlaps reports whether two incidents of the same service share any time
+func overlaps(a, b incident.Incident) bool {
+	if a.Service != b.Service {
+		return false
+	}
+	return (a.End == "" || compareTimes(b.Start, a.End) <= 0) && (b.End == "" || compareTimes(a.Start, b.End) <= 0)
+}
+
+// mergeIncident combines a known incident with a later view of the same incident derived from the history.
+// The later view decides whether the incident and each endpoint outage are still ongoing,
+// while the earliest start and the first errors already recorded are kept.
+func mergeIncident(known, later incident.Incident) incident.Incident {
+	merged := known
+	if compareTimes(later.Start, merged.Start) < 0 {
+		merged.Start = later.Start
+	}
+	merged.End = later.End
+	if merged.FirstError == "" {
+		merged.FirstError = later.FirstError
+	}
+
+	merged.Endpoints = slices.Clone(known.Endpoints)
+	for _, endpoint := range later.Endpoints {
+		i := slices.IndexFunc(merged.Endpoints, func(e incident.Endpoint) bool { return e.Key == endpoint.Key })
+		if i < 0 {
+			merged.Endpoints = append(merged.Endpoints, endpoint)
+			continue
+		}
+		if compareTimes(endpoint.Start, merged.Endpoints[i].Start) < 0 {
+			merged.Endpoints[i].Start = endpoint.Start
+		}
+		merged.Endpoints[i].End = endpoint.End
+		if merged.Endpoints[i].URL == "" {
+			merged.Endpoints[i].URL = endpoint.URL
+		}
+		if merged.Endpoints[i].FirstError == "" {
+			merged.Endpoints[i].FirstError = endpoint.FirstError
+		}
+	}
+	return merged
+}
+
+// recordFirstErrors sets the first error of the endpoints of the ongoing incidents that failed in checkResult
+// and have none yet, then the first error of each incident from the endpoint that went down first
+func recordFirstErrors(incidents incident.Incidents, checkResult []checker.Service) {
+	for _, serviceResult := range checkResult {
+		for _, endpointResult := range serviceResult.Endpoints {
+			if endpointResult.Status != chk_result.NONE || len(endpointResult.FailureDetails) == 0 {
+				continue
+			}
+			for i := range incidents {
+				if incidents[i].Service != serviceResult.Name || incidents[i].End != "" {
+					continue
+				}
+				for j := range incidents[i].Endpoints {
+					endpoint := &incidents[i].Endpoints[j]
+					if endpoint.Key == endpointResult.Key && endpoint.End == "" && endpoint.FirstError == "" {
+						endpoint.FirstError = endpointResult.FailureDetails[0]
+					}
+				}
+			}
+		}
+	}
+
+	for i := range incidents {
+		if incidents[i].FirstError != "" {
+			continue
+		}
+		for _, endpoint := range incidents[i].Endpoints {
+			if endpoint.FirstError != "" {
+				incidents[i].FirstError = endpoint.FirstError
+				break
+			}
+		}
+	}
+}
+
+// Duration returns how long an incident lasted, or has lasted so far at now if it is ongoing
+func Duration(inc incident.Incident, now time.Time) time.Duration {
+	start, err := time.Parse(time.RFC3339, inc.Start)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339, inc.End)
+	if err != nil {
+		end = now
+	}
+	return end.Sub(start)
+}
+
+// compareTimes compares two RFC 3339 times, falling back to comparing the strings if either cannot be parsed
+func compareTimes(a, b string) int {
+	aTime, aErr := time.Parse(time.RFC3339, a)
+	bTime, bErr := time.Parse(time.RFC3339, b)
+	if aErr != nil || bErr != nil {
+		return strings.Compare(a, b)
+	}
+	return aTime.Compare(bTime)
+}
@@ -0,0 +1,116 @@
+package incident
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/incident"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// testConfig returns a configuration with an API service of two endpoints
+func testConfig() *configure.Configure {
+	return &configure.Configure{Services: []configure.Service{{
+		Name: "API",
+		Endpoints: []configure.Endpoint{
+			{Key: "health", URL: "https://api.example.com/health"},
+			{Key: "login", URL: "https://api.example.com/login"},
+		},
+	}}}
+}
+
+// history builds the history of an endpoint checked every 10 minutes from midnight with the given statuses
+func history(statuses ...string) logger.History {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := make(logger.History, len(statuses))
+	for i, status := range statuses {
+		entries[i] = logger.HistoryEntry{Time: start.Add(time.Duration(i) * 10 * time.Minute).Format(time.RFC3339), Status: status}
+	}
+	return entries
+}
+
+func TestUpdateDerivesIncidents(t *testing.T) {
+	logResult := logger.Logger{"API": {Endpoints: logger.Endpoints{
+		"health": history("all", "none", "none", "all", "all", "all", "none", "maintenance", "none"),
+		"login":  history("all", "all", "none", "none", "all", "all", "all", "all", "all"),
+	}}}
+	now := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+
+	incidents := Update(nil, logResult, nil, testConfig(), now)
+	if len(incidents) != 2 {
+		t.Fatalf("Expected 2 incidents, got %+v", incidents)
+	}
+
+	ongoing, resolved := incidents[0], incidents[1]
+	if ongoing.Start != "2025-01-01T01:00:00Z" || ongoing.End != "" || len(ongoing.Endpoints) != 1 {
+		t.Errorf("Expected an ongoing incident of health since 01:00 despite the maintenance, got %+v", ongoing)
+	}
+	if resolved.Start != "2025-01-01T00:10:00Z" || resolved.End != "2025-01-01T00:40:00Z" {
+		t.Errorf("Expected the overlapping outages to form one incident from 00:10 to 00:40, got %+v", resolved)
+	}
+	if len(resolved.Endpoints) != 2 || resolved.Endpoints[0].Key != "health" || resolved.Endpoints[0].End != "2025-01-01T00:30:00Z" ||
+		resolved.Endpoints[1].URL != "https://api.example.com/login" {
+		t.Errorf("Expected health and login to be affected, got %+v", resolved.Endpoints)
+	}
+	if d := Duration(resolved, now); d != 30*time.Minute {
+		t.Errorf("Expected the resolved incident to last 30m, got %v", d)
+	}
+	if d := Duration(ongoing, now); d != time.Hour {
+		t.Errorf("Expected the ongoing incident to have lasted 1h, got %v", d)
+	}
+}
+
+func TestUpdateKeepsKnownIncidents(t *testing.T) {
+	now := time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC)
+	checkResult := []checker.Service{{Name: "API", Endpoints: []checker.Endpoint{
+		{Key: "health", Status: chk_result.NONE, FailureDetails: []string{"StatusCode: 503, Error: unavailable"}},
+	}}}
+	logResult := logger.Logger{"API": {Endpoints: logger.Endpoints{"health": history("all", "none")}}}
+
+	incidents := Update(nil, logResult, checkResult, testConfig(), now)
+	if len(incidents) != 1 || incidents[0].FirstError != "StatusCode: 503, Error: unavailable" {
+		t.Fatalf("Expected the first error to be recorded, got %+v", incidents)
+	}
+
+	// the next failure has another error, and the history before the outage has expired
+	checkResult[0].Endpoints[0].FailureDetails = []string{"timeout"}
+	logResult = logger.Logger{"API": {Endpoints: logger.Endpoints{"health": history("none", "none", "all")[1:]}}}
+	old := incident.Incident{Service: "API", Start: "2024-06-01T00:00:00Z", End: "2024-06-01T01:00:00Z"}
+	expired := incident.Incident{Service: "API", Start: "2023-06-01T00:00:00Z", End: "2023-06-01T01:00:00Z"}
+	incidents = Update(append(incidents, old, expired), logResult, checkResult, testConfig(), now)
+
+	if len(incidents) != 2 {
+		t.Fatalf("Expected the current and the old incident, got %+v", incidents)
+	}
+	current := incidents[0]
+	if current.Start != "2025-01-01T00:10:00Z" || current.End != "2025-01-01T00:20:00Z" {
+		t.Errorf("Expected the known start and the end from the history, got %+v", current)
+	}
+	if current.FirstError != "StatusCode: 503, Error: unavailable" || current.Endpoints[0].FirstError != "StatusCode: 503, Error: unavailable" {
+		t.Errorf("Expected the first error to be kept, got %+v", current)
+	}
+	if incidents[1].Start != old.Start || incidents[1].End != old.End {
+		t.Errorf("Expected the old incident to be kept as is, got %+v", incidents[1])
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "incidents.json")
+	incidents, err := Load(path)
+	if err != nil || len(incidents) != 0 {
+		t.Fatalf("Expected no incidents without a file, got %+v, %v", incidents, err)
+	}
+
+	saved := incident.Incidents{{Service: "API", Start: "2025-01-01T00:00:00Z", Endpoints: []incident.Endpoint{{Key: "health"}}}}
+	if err := Save(path, saved); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	incidents, err = Load(path)
+	if err != nil || len(incidents) != 1 || incidents[0].Service != "API" || incidents[0].Endpoints[0].Key != "health" {
+		t.Errorf("Expected the saved incident, got %+v, %v", incidents, err)
+	}
+}
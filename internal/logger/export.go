@@ -0,0 +1,344 @@
+package logger
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/checker"
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// csvHeader lists the columns of exported CSV history
+var csvHeader = []string{"service", "endpoint", "time", "status", "response_time"}
+
+// statusAliases maps the statuses used by other monitoring tools to check results
+var statusAliases = map[string]string{
+	"up":   chk_result.ALL.String(),
+	"down": chk_result.NONE.String(),
+}
+
+// DetectFormat returns the export format matching the extension of path, or an empty string if there is none
+func DetectFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return logger.FormatCSV
+	case ".jsonl", ".ndjson":
+		return logger.FormatJSONL
+	default:
+		return ""
+	}
+}
+
+// ExportHistory writes the entries of logResult selected by filter to w, ordered by service, endpoint and time.
+// It returns the number of records written.
+func ExportHistory(w io.Writer, logResult logger.Logger, filter logger.RecordFilter, format string) (int, error) {
+	records := toRecords(logResult, filter)
+
+	switch strings.ToLower(format) {
+	case logger.FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return 0, err
+		}
+		for _, record := range records {
+			responseTime := ""
+			if record.ResponseTime > 0 {
+				responseTime = strconv.Itoa(record.ResponseTime)
+			}
+			if err := writer.Write([]string{record.Service, record.Endpoint, record.Time, record.Status, responseTime}); err != nil {
+				return 0, err
+			}
+		}
+		writer.Flush()
+		return len(records), writer.Error()
+	case logger.FormatJSONL:
+		encoder := json.NewEncoder(w)
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return 0, err
+			}
+		}
+		return len(records), nil
+	default:
+		return 0, fmt.Errorf("unknown format %q, expected %s or %s", format, logger.FormatCSV, logger.FormatJSONL)
+	}
+}
+
+// toRecords flattens the entries of logResult selected by filter
+func toRecords(logResult logger.Logger, filter logger.RecordFilter) logger.Records {
+	var serviceNames []string
+	for serviceName := range logResult {
+		if len(filter.Services) == 0 || slices.Contains(filter.Services, serviceName) {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+
+	var records logger.Records
+	addHistory := func(serviceName, endpointKey string, history logger.History) {
+		for _, entry := range history {
+			entryTime, err := time.Parse(time.RFC3339, entry.Time)
+			if err != nil {
+				continue
+			}
+			if (!filter.From.IsZero() && entryTime.Before(filter.From)) || (!filter.To.IsZero() && entryTime.After(filter.To)) {
+				continue
+			}
+			records = append(records, logger.Record{
+				Service:      serviceName,
+				Endpoint:     endpointKey,
+				Time:         entry.Time,
+				Status:       entry.Status,
+				ResponseTime: entry.ResponseTime,
+			})
+		}
+	}
+
+	for _, serviceName := range serviceNames {
+		serviceLog := logResult[serviceName]
+		// the service history is only exported when no endpoint is selected
+		if len(filter.Endpoints) == 0 {
+			addHistory(serviceName, "", serviceLog.ServiceHistory)
+		}
+
+		var endpointKeys []string
+		for endpointKey := range serviceLog.Endpoints {
+			if len(filter.Endpoints) == 0 || slices.Contains(filter.Endpoints, endpointKey) {
+				endpointKeys = append(endpointKeys, endpointKey)
+			}
+		}
+		sort.Strings(endpointKeys)
+		for _, endpointKey := range endpointKeys {
+			addHistory(serviceName, endpointKey, serviceLog.Endpoints[endpointKey])
+		}
+	}
+	return records
+}
+
+// ReadRecords parses history records written in the given format.
+// Statuses are matched case-insensitively, and up and down are accepted for all and none.
+func ReadRecords(r io.Reader, format string) (logger.Records, error) {
+	var records logger.Records
+	var err error
+	switch strings.ToLower(format) {
+	case logger.FormatCSV:
+		records, err = readCSVRecords(r)
+	case logger.FormatJSONL:
+		records, err = readJSONLRecords(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q, expected %s or %s", format, logger.FormatCSV, logger.FormatJSONL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range records {
+		if err := normalizeRecord(&records[i]); err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+	}
+	return records, nil
+}
+
+// readCSVRecords parses CSV records, locating the columns by the names in the header row
+func readCSVRecords(r io.Reader) (logger.Records, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"service", "time", "status"} {
+		if _, exists := columns[required]; !exists {
+			return nil, fmt.Errorf("missing column %q in the CSV header", required)
+		}
+	}
+	field := func(row []string, name string) string {
+		if i, exists := columns[name]; exists && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var records logger.Records
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		record := logger.Record{
+			Service:  field(row, "service"),
+			Endpoint: field(row, "endpoint"),
+			Time:     field(row, "time"),
+			Status:   field(row, "status"),
+		}
+		if responseTime := field(row, "response_time"); responseTime != "" {
+			if record.ResponseTime, err = strconv.Atoi(responseTime); err != nil {
+				line, _ := reader.FieldPos(0)
+				return nil, fmt.Errorf("line %d: invalid response_time %q", line, responseTime)
+			}
+		}
+		records = append(records, record)
+	}
+}
+
+// readJSONLRecords parses one JSON record per line, skipping blank lines
+func readJSONLRecords(r io.Reader) (logger.Records, error) {
+	var records logger.Records
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record logger.Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// normalizeRecord checks a record and rewrites its time and status in the form used by the log
+func normalizeRecord(record *logger.Record) error {
+	if record.Service == "" {
+		return errors.New("missing service")
+	}
+
+	recordTime, err := time.Parse(time.RFC3339, record.Time)
+	if err != nil {
+		return fmt.Errorf("invalid time %q: must be RFC 3339 such as 2025-01-02T15:04:05Z", record.Time)
+	}
+	record.Time = recordTime.Format(time.RFC3339)
+
+	status := strings.ToLower(record.Status)
+	if alias, exists := statusAliases[status]; exists {
+		status = alias
+	}
+	if !chk_result.ParseCheckResult(status).IsValid() {
+		return fmt.Errorf("invalid status %q: expected all, part, none, maintenance, up or down", record.Status)
+	}
+	record.Status = status
+
+	if record.ResponseTime < 0 {
+		return fmt.Errorf("invalid response_time %d", record.ResponseTime)
+	}
+	return nil
+}
+
+// ImportHistory merges records into the history held by store.
+// Entries whose timestamp is already logged are skipped, as are records of services and endpoints
+// that are not configured; records logged under a previous id are moved to the current one.
+// Imported entries older than max_log_days are rolled up. It returns the number of entries added.
+func ImportHistory(records logger.Records, cfg *configure.Configure, store storage.Store) (int, error) {
+	unlock, err := store.Lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	logResult, err := store.Read(time.Time{}, time.Time{})
+	if err != nil {
+		return 0, err
+	}
+
+	// map every current and previous endpoint key to the current key
+	endpointKeys := make(map[string]map[string]string)
+	for _, service := range checker.DescribeServices(cfg) {
+		keys := make(map[string]string)
+		for _, endpoint := range service.Endpoints {
+			for _, previousKey := range endpoint.PreviousKeys {
+				keys[previousKey] = endpoint.Key
+			}
+		}
+		for _, endpoint := range service.Endpoints {
+			keys[endpoint.Key] = endpoint.Key
+		}
+		endpointKeys[service.Name] = keys
+	}
+
+	imported := make(logger.Logger)
+	unknown := 0
+	for _, record := range records {
+		keys, exists := endpointKeys[record.Service]
+		endpointKey, known := keys[record.Endpoint]
+		if !exists || (record.Endpoint != "" && !known) {
+			unknown++
+			continue
+		}
+
+		serviceLog, exists := imported[record.Service]
+		if !exists {
+			serviceLog = logger.Service{Endpoints: make(logger.Endpoints)}
+		}
+		entry := logger.HistoryEntry{Time: record.Time, Status: record.Status, ResponseTime: record.ResponseTime}
+		if record.Endpoint == "" {
+			serviceLog.ServiceHistory = serviceLog.ServiceHistory.AddEntry(entry)
+		} else {
+			serviceLog.Endpoints[endpointKey] = serviceLog.Endpoints[endpointKey].AddEntry(entry)
+		}
+		imported[record.Service] = serviceLog
+	}
+	if unknown > 0 {
+		log.Printf("Skipped %d record(s) of services or endpoints that are not configured", unknown)
+	}
+
+	added, retention, now := 0, getRetention(cfg), time.Now()
+	for serviceName, importedLog := range imported {
+		serviceLog, exists := logResult[serviceName]
+		if !exists {
+			serviceLog = logger.Service{Endpoints: make(logger.Endpoints)}
+		}
+
+		// entries in hours that are already rolled up are duplicates as well
+		var count int
+		serviceLog.ServiceHistory, count = serviceLog.ServiceHistory.MergeUnique(serviceLog.ServiceRollups.Uncovered(importedLog.ServiceHistory))
+		added += count
+		for endpointKey, endpointHistory := range importedLog.Endpoints {
+			endpointHistory = serviceLog.EndpointRollups[endpointKey].Uncovered(endpointHistory)
+			serviceLog.Endpoints[endpointKey], count = serviceLog.Endpoints[endpointKey].MergeUnique(endpointHistory)
+			added += count
+		}
+		logResult[serviceName] = common.RollupExpiredEntries(serviceLog, retention, now)
+	}
+	if duplicates := len(records) - unknown - added; duplicates > 0 {
+		log.Printf("Skipped %d record(s) whose timestamp is already logged", duplicates)
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	if err := store.Write(logResult); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
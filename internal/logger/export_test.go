@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// testConfig returns a configuration with one service and one endpoint that was renamed from its URL
+func testConfig() *configure.Configure {
+	return &configure.Configure{
+		MaxLogDays: 3,
+		Rollups:    &configure.RollupConfig{HourlyWeeks: 4, DailyDays: 365},
+		Services: []configure.Service{{
+			Name: "API",
+			Endpoints: []configure.Endpoint{{
+				URL:         "https://api.example.com/health",
+				ID:          "health",
+				PreviousIDs: []string{"https://api.example.com/health"},
+				Key:         "health",
+			}},
+		}},
+	}
+}
+
+func TestExportHistoryRoundTrip(t *testing.T) {
+	logResult := logger.Logger{
+		"API": {
+			ServiceHistory: logger.History{{Time: "2025-01-01T00:00:00Z", Status: "all"}, {Time: "2025-01-02T00:00:00Z", Status: "none"}},
+			Endpoints: logger.Endpoints{
+				"health": {{Time: "2025-01-01T00:00:00Z", Status: "all", ResponseTime: 42}, {Time: "2025-01-02T00:00:00Z", Status: "none"}},
+			},
+		},
+		"Web": {ServiceHistory: logger.History{{Time: "2025-01-01T00:00:00Z", Status: "all"}}},
+	}
+	filter := logger.RecordFilter{Services: []string{"API"}, From: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+
+	for _, format := range []string{logger.FormatCSV, logger.FormatJSONL} {
+		var buf bytes.Buffer
+		count, err := ExportHistory(&buf, logResult, filter, format)
+		if err != nil {
+			t.Fatalf("%s: expected no error exporting, got %v", format, err)
+		}
+		if count != 2 {
+			t.Errorf("%s: expected 2 records after filtering, got %d:\n%s", format, count, buf.String())
+		}
+
+		records, err := ReadRecords(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: expected no error reading, got %v", format, err)
+		}
+		if len(records) != 2 || records[0].Endpoint != "" || records[1].Endpoint != "health" || records[1].Status != "none" {
+			t.Errorf("%s: expected the service and endpoint records of the second day, got %+v", format, records)
+		}
+	}
+}
+
+func TestReadRecordsNormalizesStatus(t *testing.T) {
+	input := "time,status,service,endpoint\n2025-01-01T08:00:00+08:00,UP,API,health\n"
+	records, err := ReadRecords(strings.NewReader(input), logger.FormatCSV)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(records) != 1 || records[0].Status != "all" || records[0].Endpoint != "health" {
+		t.Errorf("Expected one record with status all, got %+v", records)
+	}
+
+	if _, err := ReadRecords(strings.NewReader("service,time,status\nAPI,yesterday,all\n"), logger.FormatCSV); err == nil {
+		t.Errorf("Expected an error for an invalid time")
+	}
+	if _, err := ReadRecords(strings.NewReader(`{"service":"API","time":"2025-01-01T00:00:00Z","status":"broken"}`), logger.FormatJSONL); err == nil {
+		t.Errorf("Expected an error for an invalid status")
+	}
+}
+
+func TestImportHistorySkipsDuplicates(t *testing.T) {
+	store := storage.NewJSONStore(filepath.Join(t.TempDir(), "ponghub_log.json"))
+	now := time.Now().UTC().Truncate(time.Second)
+	existing := now.Add(-time.Hour).Format(time.RFC3339)
+	if err := store.Write(logger.Logger{"API": {
+		ServiceHistory: logger.History{{Time: existing, Status: "all"}},
+		Endpoints:      logger.Endpoints{"health": {{Time: existing, Status: "all"}}},
+	}}); err != nil {
+		t.Fatalf("Failed to write log: %v", err)
+	}
+
+	expired := now.AddDate(0, 0, -10).Format(time.RFC3339)
+	records := logger.Records{
+		// the same instant with another offset is a duplicate
+		{Service: "API", Time: now.Add(-time.Hour).In(time.FixedZone("UTC+8", 8*3600)).Format(time.RFC3339), Status: "none"},
+		{Service: "API", Endpoint: "https://api.example.com/health", Time: now.Format(time.RFC3339), Status: "all"},
+		{Service: "API", Endpoint: "health", Time: expired, Status: "none"},
+		{Service: "Unknown", Time: now.Format(time.RFC3339), Status: "all"},
+	}
+
+	added, err := ImportHistory(records, testConfig(), store)
+	if err != nil {
+		t.Fatalf("Expected no error importing, got %v", err)
+	}
+	if added != 2 {
+		t.Errorf("Expected 2 records to be added, got %d", added)
+	}
+
+	logResult, err := store.Read(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	if _, exists := logResult["Unknown"]; exists {
+		t.Errorf("Expected records of unknown services to be skipped")
+	}
+	if len(logResult["API"].ServiceHistory) != 1 {
+		t.Errorf("Expected the duplicate service entry to be skipped, got %v", logResult["API"].ServiceHistory)
+	}
+	if len(logResult["API"].Endpoints["health"]) != 2 {
+		t.Errorf("Expected the entry logged under the previous id to be added, got %v", logResult["API"].Endpoints["health"])
+	}
+	if rollups := logResult["API"].EndpointRollups["health"]; len(rollups) != 1 || rollups[0].Count != 1 {
+		t.Errorf("Expected the expired entry to be rolled up, got %+v", rollups)
+	}
+
+	// importing the same records again adds nothing
+	if added, err := ImportHistory(records, testConfig(), store); err != nil || added != 0 {
+		t.Errorf("Expected a second import to add nothing, got %d, %v", added, err)
+	}
+}
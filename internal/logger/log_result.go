@@ -2,18 +2,47 @@ package logger
 
 import (
 	"log"
+	"time"
 
 	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/storage"
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
 	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
 )
 
-// GetLog writes check results to JSON file
-func GetLog(currentCheckResult []checker.Service, maxLogDays int, logPath string) (logger.Logger, error) {
+// readFrom returns the start of the history to read before merging check results: the raw retention,
+// or the oldest entry the store has not rolled up yet if that is older, such as after a gap between runs
+func readFrom(cfg *configure.Configure, store storage.Store) (time.Time, error) {
+	from := time.Now().AddDate(0, 0, -cfg.MaxLogDays)
+	pending, err := store.PendingRollup()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if pending.Before(from) {
+		// a zero time reads the whole history
+		from = pending
+	}
+	return from, nil
+}
+
+// GetLog merges check results into the recent history held by store
+func GetLog(currentCheckResult []checker.Service, cfg *configure.Configure, store storage.Store) (logger.Logger, error) {
+	// Move the history of renamed endpoints, beyond the range read below
+	if err := store.MoveEndpoints(common.EndpointMoves(currentCheckResult)); err != nil {
+		log.Printf("Error moving the history of renamed endpoints: %v", err)
+		return nil, err
+	}
+
 	// Load existing log data
-	previousLog, err := common.ReadLogs(logPath)
+	from, err := readFrom(cfg, store)
+	if err != nil {
+		log.Printf("Error loading log data: %v", err)
+		return nil, err
+	}
+	previousLog, err := store.Read(from, time.Time{})
 	if err != nil {
-		log.Printf("Error loading log data from %s: %v", logPath, err)
+		log.Printf("Error loading log data: %v", err)
 		return nil, err
 	}
 
@@ -21,17 +50,92 @@ func GetLog(currentCheckResult []checker.Service, maxLogDays int, logPath string
 	previousLog = common.FilterLogs(previousLog, currentCheckResult)
 
 	// Merge new check results with existing log data
-	currentLog := common.MergeLogs(previousLog, currentCheckResult, maxLogDays)
+	currentLog := common.MergeLogs(previousLog, currentCheckResult, getRetention(cfg))
+
+	return currentLog, nil
+}
+
+// GetPartialLog merges the check results of a subset of services into the log,
+// keeping the history of the other services that are still part of knownCheckResult
+func GetPartialLog(freshCheckResult, knownCheckResult []checker.Service, cfg *configure.Configure, store storage.Store) (logger.Logger, error) {
+	// Move the history of renamed endpoints, beyond the range read below
+	if err := store.MoveEndpoints(common.EndpointMoves(knownCheckResult)); err != nil {
+		log.Printf("Error moving the history of renamed endpoints: %v", err)
+		return nil, err
+	}
+
+	// Load existing log data
+	from, err := readFrom(cfg, store)
+	if err != nil {
+		log.Printf("Error loading log data: %v", err)
+		return nil, err
+	}
+	previousLog, err := store.Read(from, time.Time{})
+	if err != nil {
+		log.Printf("Error loading log data: %v", err)
+		return nil, err
+	}
+
+	// Filter against every known service, not only the ones checked in this cycle
+	previousLog = common.FilterLogs(previousLog, knownCheckResult)
+
+	// Merge only the fresh results so that unchanged services get no duplicate entries
+	currentLog := common.MergeLogs(previousLog, freshCheckResult, getRetention(cfg))
 
 	return currentLog, nil
 }
 
-// WriteLog writes log data to file
-func WriteLog(currentLog logger.Logger, logPath string) error {
-	// Save updated log data back to file
-	err := common.WriteLogs(currentLog, logPath)
+// UpdateLog merges check results into the history held by store and writes it back,
+// holding the lock of the store so that a concurrent run cannot drop the entries of this one
+func UpdateLog(currentCheckResult []checker.Service, cfg *configure.Configure, store storage.Store) (logger.Logger, error) {
+	return updateLog(store, func() (logger.Logger, error) {
+		return GetLog(currentCheckResult, cfg, store)
+	})
+}
+
+// UpdatePartialLog merges the check results of a subset of services into the history held by store
+// and writes it back under the lock of the store, like UpdateLog
+func UpdatePartialLog(freshCheckResult, knownCheckResult []checker.Service, cfg *configure.Configure, store storage.Store) (logger.Logger, error) {
+	return updateLog(store, func() (logger.Logger, error) {
+		return GetPartialLog(freshCheckResult, knownCheckResult, cfg, store)
+	})
+}
+
+// updateLog writes the history returned by merge back to store, holding the lock of the store throughout
+func updateLog(store storage.Store, merge func() (logger.Logger, error)) (logger.Logger, error) {
+	unlock, err := store.Lock()
+	if err != nil {
+		log.Printf("Error locking log data: %v", err)
+		return nil, err
+	}
+	defer unlock()
+
+	currentLog, err := merge()
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteLog(currentLog, store); err != nil {
+		return nil, err
+	}
+	return currentLog, nil
+}
+
+// getRetention returns how long raw entries and rollups are kept
+func getRetention(cfg *configure.Configure) logger.Retention {
+	retention := logger.Retention{RawDays: cfg.MaxLogDays}
+	if cfg.Rollups != nil {
+		retention.HourlyWeeks = cfg.Rollups.HourlyWeeks
+		retention.DailyDays = cfg.Rollups.DailyDays
+	}
+	return retention
+}
+
+// WriteLog writes log data to store
+func WriteLog(currentLog logger.Logger, store storage.Store) error {
+	// Save updated log data back to the store
+	err := store.Write(currentLog)
 	if err != nil {
-		log.Printf("Error saving log data to %s: %v", logPath, err)
+		log.Printf("Error saving log data: %v", err)
 		return err
 	}
 
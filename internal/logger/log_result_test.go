@@ -0,0 +1,151 @@
+package logger
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+func TestGetLogRollsUpAfterGap(t *testing.T) {
+	cfg := testConfig()
+	dir := t.TempDir()
+	sqliteStore, err := storage.OpenSQLiteStore(filepath.Join(dir, "ponghub.db"), 90, "")
+	if err != nil {
+		t.Fatalf("Expected no error opening the store, got %v", err)
+	}
+	defer sqliteStore.Close()
+	now := time.Now().UTC().Truncate(time.Hour)
+
+	for name, store := range map[string]storage.Store{"json": storage.NewJSONStore(filepath.Join(dir, "log.json")), "sqlite": sqliteStore} {
+		t.Run(name, func(t *testing.T) {
+			// the previous run was a week ago, well past the raw retention
+			var serviceHistory, endpointHistory logger.History
+			for _, daysAgo := range []int{10, 8, 7} {
+				entryTime := now.AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+				serviceHistory = append(serviceHistory, logger.HistoryEntry{Time: entryTime, Status: "all"})
+				endpointHistory = append(endpointHistory, logger.HistoryEntry{Time: entryTime, Status: "all", ResponseTime: 42})
+			}
+			if err := WriteLog(logger.Logger{"API": {ServiceHistory: serviceHistory, Endpoints: logger.Endpoints{"health": endpointHistory}}}, store); err != nil {
+				t.Fatalf("Expected no error writing, got %v", err)
+			}
+
+			checkResult := []checker.Service{{
+				Name:      "API",
+				Status:    chk_result.ALL,
+				StartTime: now.Format(time.RFC3339),
+				Endpoints: []checker.Endpoint{{Key: "health", Status: chk_result.ALL, StartTime: now.Format(time.RFC3339)}},
+			}}
+			logResult, err := UpdateLog(checkResult, cfg, store)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			serviceLog := logResult["API"]
+			if len(serviceLog.ServiceHistory) != 1 || len(serviceLog.Endpoints["health"]) != 1 {
+				t.Errorf("Expected only the new entry to be kept raw, got %+v", serviceLog)
+			}
+			if len(serviceLog.ServiceRollups) != 3 || len(serviceLog.EndpointRollups["health"]) != 3 {
+				t.Errorf("Expected the 3 expired entries to be rolled up, got %+v and %+v", serviceLog.ServiceRollups, serviceLog.EndpointRollups)
+			}
+
+			// once rolled up, the next run reads only the raw retention again
+			if pending, err := store.PendingRollup(); err != nil || (name == "sqlite" && pending.Before(now.AddDate(0, 0, -cfg.MaxLogDays))) {
+				t.Errorf("Expected no entry left to roll up, got %v, %v", pending, err)
+			}
+		})
+	}
+}
+
+// slowStore delays its reads, so that concurrent updates overlap
+type slowStore struct {
+	*storage.JSONStore
+}
+
+func (s slowStore) Read(from, to time.Time) (logger.Logger, error) {
+	time.Sleep(50 * time.Millisecond)
+	return s.JSONStore.Read(from, to)
+}
+
+func TestUpdateLogConcurrently(t *testing.T) {
+	cfg := testConfig()
+	store := slowStore{storage.NewJSONStore(filepath.Join(t.TempDir(), "log.json"))}
+	now := time.Now().UTC().Truncate(time.Minute)
+
+	// two runs, such as a scheduled and a manual one, each add their own check result
+	var wg sync.WaitGroup
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			startTime := now.Add(time.Duration(i) * time.Minute).Format(time.RFC3339)
+			checkResult := []checker.Service{{
+				Name:      "API",
+				Status:    chk_result.ALL,
+				StartTime: startTime,
+				Endpoints: []checker.Endpoint{{Key: "health", Status: chk_result.ALL, StartTime: startTime}},
+			}}
+			if _, err := UpdateLog(checkResult, cfg, store); err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	logResult, err := store.Read(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected no error reading, got %v", err)
+	}
+	if len(logResult["API"].ServiceHistory) != 2 || len(logResult["API"].Endpoints["health"]) != 2 {
+		t.Errorf("Expected the entries of both runs, got %+v", logResult["API"])
+	}
+}
+
+func TestUpdateLogMovesRenamedEndpointOnSQLite(t *testing.T) {
+	cfg := testConfig()
+	store, err := storage.OpenSQLiteStore(filepath.Join(t.TempDir(), "ponghub.db"), 90, "")
+	if err != nil {
+		t.Fatalf("Expected no error opening the store, got %v", err)
+	}
+	defer store.Close()
+	now := time.Now().UTC().Truncate(time.Hour)
+
+	// 30 days of history logged under the URL, before the endpoint was given an id
+	var serviceHistory, endpointHistory logger.History
+	for daysAgo := 30; daysAgo >= 1; daysAgo-- {
+		entryTime := now.AddDate(0, 0, -daysAgo).Format(time.RFC3339)
+		serviceHistory = append(serviceHistory, logger.HistoryEntry{Time: entryTime, Status: "all"})
+		endpointHistory = append(endpointHistory, logger.HistoryEntry{Time: entryTime, Status: "all"})
+	}
+	previousKey := "https://api.example.com/health"
+	if err := WriteLog(logger.Logger{"API": {ServiceHistory: serviceHistory, Endpoints: logger.Endpoints{previousKey: endpointHistory}}}, store); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+
+	checkResult := []checker.Service{{
+		Name:      "API",
+		Status:    chk_result.ALL,
+		StartTime: now.Format(time.RFC3339),
+		Endpoints: []checker.Endpoint{{Key: "health", PreviousKeys: []string{previousKey}, Status: chk_result.ALL, StartTime: now.Format(time.RFC3339)}},
+	}}
+	if _, err := UpdateLog(checkResult, cfg, store); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	logResult, err := store.Read(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected no error reading, got %v", err)
+	}
+	endpoints := logResult["API"].Endpoints
+	if len(endpoints["health"]) != 31 {
+		t.Errorf("Expected the 30 days of history to move to the new key, got %d entries", len(endpoints["health"]))
+	}
+	if _, exists := endpoints[previousKey]; exists {
+		t.Errorf("Expected nothing left under the previous key, got %+v", endpoints[previousKey])
+	}
+}
@@ -0,0 +1,42 @@
+package maintenance
+
+import (
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+// ActiveWindow returns the first of windows that covers t, or nil if none does.
+// The windows must have been validated, so that their parsed fields are set.
+func ActiveWindow(windows []configure.MaintenanceWindow, t time.Time) *configure.MaintenanceWindow {
+	for i := range windows {
+		if isActive(windows[i], t) {
+			return &windows[i]
+		}
+	}
+	return nil
+}
+
+// isActive reports whether a single window covers t
+func isActive(window configure.MaintenanceWindow, t time.Time) bool {
+	if window.Schedule == "" {
+		return !window.ParsedStart.IsZero() && !t.Before(window.ParsedStart) && t.Before(window.ParsedEnd)
+	}
+
+	if window.ParsedSchedule == nil || window.ParsedDuration <= 0 {
+		return false
+	}
+	location := window.ParsedLocation
+	if location == nil {
+		location = time.UTC
+	}
+
+	// look for a start within the duration before t
+	earliest := t.Add(-window.ParsedDuration)
+	for start := t.In(location).Truncate(time.Minute); start.After(earliest); start = start.Add(-time.Minute) {
+		if window.ParsedSchedule.Matches(start) {
+			return true
+		}
+	}
+	return false
+}
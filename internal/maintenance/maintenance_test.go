@@ -0,0 +1,53 @@
+package maintenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/cron"
+)
+
+func TestActiveWindow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %v", err)
+	}
+	daily, _ := cron.ParseSchedule("30 2 * * *")
+	windows := []configure.MaintenanceWindow{
+		{
+			Reason:      "upgrade",
+			ParsedStart: time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC),
+			ParsedEnd:   time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC),
+		},
+		{
+			Reason:         "backup",
+			Schedule:       "30 2 * * *",
+			ParsedSchedule: &daily,
+			ParsedDuration: time.Hour,
+			ParsedLocation: berlin,
+		},
+	}
+
+	tests := []struct {
+		time   time.Time
+		reason string
+	}{
+		{time.Date(2025, 1, 10, 22, 0, 0, 0, time.UTC), "upgrade"},
+		{time.Date(2025, 1, 10, 22, 59, 59, 0, time.UTC), "upgrade"},
+		{time.Date(2025, 1, 10, 23, 0, 0, 0, time.UTC), ""},
+		{time.Date(2025, 1, 11, 1, 30, 0, 0, time.UTC), "backup"}, // 02:30 in Berlin
+		{time.Date(2025, 1, 11, 2, 29, 0, 0, time.UTC), "backup"},
+		{time.Date(2025, 1, 11, 2, 30, 0, 0, time.UTC), ""},
+		{time.Date(2025, 1, 11, 2, 29, 0, 0, berlin), ""}, // just before the daily window starts
+	}
+	for _, test := range tests {
+		window := ActiveWindow(windows, test.time)
+		switch {
+		case test.reason == "" && window != nil:
+			t.Errorf("Expected no window at %s, got %q", test.time, window.Reason)
+		case test.reason != "" && (window == nil || window.Reason != test.reason):
+			t.Errorf("Expected window %q at %s, got %+v", test.reason, test.time, window)
+		}
+	}
+}
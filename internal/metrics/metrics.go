@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// ContentType is the content type of the Prometheus text exposition format
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricFamily describes a single metric in the exposition output
+type metricFamily struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// sample is a single value of a metric with its labels
+type sample struct {
+	labels [][2]string
+	value  float64
+}
+
+// Write writes the check results and availability in the Prometheus text exposition format
+func Write(w io.Writer, checkResult []checker.Service, reportResult reporter.Reporter) error {
+	bw := bufio.NewWriter(w)
+	for _, family := range collect(checkResult, reportResult) {
+		if len(family.samples) == 0 {
+			continue
+		}
+		writeFamily(bw, family)
+	}
+	return bw.Flush()
+}
+
+// WriteFile writes the metrics to path for the node_exporter textfile collector.
+// The file is replaced atomically so the collector never reads a partial file.
+func WriteFile(path string, checkResult []checker.Service, reportResult reporter.Reporter) error {
+	var buf bytes.Buffer
+	if err := Write(&buf, checkResult, reportResult); err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, buf.Bytes(), 0644)
+}
+
+// collect converts check results and report data into metric families
+func collect(checkResult []checker.Service, reportResult reporter.Reporter) []metricFamily {
+	endpointUp := metricFamily{name: "ponghub_endpoint_up", help: "Whether the last check of the endpoint succeeded (1) or not (0)."}
+	inMaintenance := metricFamily{name: "ponghub_endpoint_maintenance", help: "Whether the last check of the endpoint failed during a maintenance window of its service (1) or not (0)."}
+	statusCode := metricFamily{name: "ponghub_endpoint_status_code", help: "HTTP status code returned by the last check of the endpoint."}
+	responseTime := metricFamily{name: "ponghub_endpoint_response_time_seconds", help: "Response time of the last successful check of the endpoint."}
+	certDays := metricFamily{name: "ponghub_endpoint_cert_remaining_days", help: "Days until the TLS certificate of the endpoint expires."}
+	certExpired := metricFamily{name: "ponghub_endpoint_cert_expired", help: "Whether the TLS certificate of the endpoint has expired (1) or not (0)."}
+	lastCheck := metricFamily{name: "ponghub_endpoint_last_check_timestamp_seconds", help: "Unix time at which the endpoint was last checked."}
+	serviceStatus := metricFamily{name: "ponghub_service_status", help: "Current status of the service, one series per possible status set to 1 for the active one."}
+	availability := metricFamily{name: "ponghub_service_availability", help: "Ratio of checks in the retained history where every endpoint of the service was up."}
+
+	for _, serviceResult := range checkResult {
+		for _, status := range []chk_result.CheckResult{chk_result.ALL, chk_result.PART, chk_result.NONE, chk_result.MAINTENANCE} {
+			serviceStatus.samples = append(serviceStatus.samples, sample{
+				labels: [][2]string{{"service", serviceResult.Name}, {"status", status.String()}},
+				value:  boolValue(serviceResult.Status == status),
+			})
+		}
+
+		for _, endpoint := range serviceResult.Endpoints {
+			labels := [][2]string{{"service", serviceResult.Name}, {"url", endpoint.URL}, {"method", endpoint.Method}}
+
+			// a failure during maintenance is still a failed probe, only flagged as expected
+			up := endpoint.Status != chk_result.NONE && endpoint.Status != chk_result.MAINTENANCE
+			endpointUp.samples = append(endpointUp.samples, sample{labels: labels, value: boolValue(up)})
+			inMaintenance.samples = append(inMaintenance.samples, sample{labels: labels, value: boolValue(endpoint.Status == chk_result.MAINTENANCE)})
+			if endpoint.StatusCode > 0 {
+				statusCode.samples = append(statusCode.samples, sample{labels: labels, value: float64(endpoint.StatusCode)})
+			}
+			if up {
+				responseTime.samples = append(responseTime.samples, sample{labels: labels, value: endpoint.ResponseTime.Seconds()})
+			}
+			if endpoint.IsHTTPS {
+				certDays.samples = append(certDays.samples, sample{labels: labels, value: float64(endpoint.CertRemainingDays)})
+				certExpired.samples = append(certExpired.samples, sample{labels: labels, value: boolValue(endpoint.IsCertExpired)})
+			}
+			if startTime, err := time.Parse(time.RFC3339, endpoint.StartTime); err == nil {
+				lastCheck.samples = append(lastCheck.samples, sample{labels: labels, value: float64(startTime.Unix())})
+			}
+		}
+	}
+
+	for _, service := range reportResult {
+		availability.samples = append(availability.samples, sample{
+			labels: [][2]string{{"service", service.Name}},
+			value:  service.Availability,
+		})
+	}
+
+	return []metricFamily{endpointUp, inMaintenance, statusCode, responseTime, certDays, certExpired, lastCheck, serviceStatus, availability}
+}
+
+// writeFamily writes the HELP and TYPE lines of a metric followed by its samples
+func writeFamily(w *bufio.Writer, family metricFamily) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n", family.name, family.help)
+	_, _ = fmt.Fprintf(w, "# TYPE %s gauge\n", family.name)
+	for _, s := range family.samples {
+		_, _ = w.WriteString(family.name)
+		if len(s.labels) > 0 {
+			pairs := make([]string, 0, len(s.labels))
+			for _, label := range s.labels {
+				pairs = append(pairs, fmt.Sprintf(`%s="%s"`, label[0], escapeLabelValue(label[1])))
+			}
+			_, _ = fmt.Fprintf(w, "{%s}", strings.Join(pairs, ","))
+		}
+		_, _ = fmt.Fprintf(w, " %g\n", s.value)
+	}
+}
+
+// escapeLabelValue escapes backslashes, double quotes and line feeds in a label value
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// boolValue converts a boolean to a metric value
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package metrics
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+func TestWrite(t *testing.T) {
+	checkResult := []checker.Service{
+		{
+			Name:   "API",
+			Status: chk_result.PART,
+			Endpoints: []checker.Endpoint{
+				{
+					URL:               "https://api.example.com/health",
+					Method:            "GET",
+					Status:            chk_result.ALL,
+					StatusCode:        200,
+					StartTime:         "2025-01-01T00:00:00Z",
+					ResponseTime:      250 * time.Millisecond,
+					IsHTTPS:           true,
+					CertRemainingDays: 42,
+				},
+				{
+					URL:        `http://api.example.com/search?q="x"`,
+					Method:     "POST",
+					Status:     chk_result.NONE,
+					StatusCode: 503,
+					StartTime:  "2025-01-01T00:00:00Z",
+				},
+				{
+					URL:          "https://api.example.com/admin",
+					Method:       "GET",
+					Status:       chk_result.MAINTENANCE,
+					StatusCode:   502,
+					StartTime:    "2025-01-01T00:00:00Z",
+					ResponseTime: 30 * time.Second,
+				},
+			},
+		},
+	}
+	reportResult := reporter.Reporter{{Name: "API", Availability: 0.75}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, checkResult, reportResult); err != nil {
+		t.Fatalf("Write() returned error: %v", err)
+	}
+	output := buf.String()
+
+	expectedLines := []string{
+		"# TYPE ponghub_endpoint_up gauge",
+		`ponghub_endpoint_up{service="API",url="https://api.example.com/health",method="GET"} 1`,
+		`ponghub_endpoint_up{service="API",url="http://api.example.com/search?q=\"x\"",method="POST"} 0`,
+		`ponghub_endpoint_up{service="API",url="https://api.example.com/admin",method="GET"} 0`,
+		`ponghub_endpoint_maintenance{service="API",url="https://api.example.com/admin",method="GET"} 1`,
+		`ponghub_endpoint_maintenance{service="API",url="https://api.example.com/health",method="GET"} 0`,
+		`ponghub_endpoint_status_code{service="API",url="http://api.example.com/search?q=\"x\"",method="POST"} 503`,
+		`ponghub_endpoint_response_time_seconds{service="API",url="https://api.example.com/health",method="GET"} 0.25`,
+		`ponghub_endpoint_cert_remaining_days{service="API",url="https://api.example.com/health",method="GET"} 42`,
+		`ponghub_endpoint_last_check_timestamp_seconds{service="API",url="https://api.example.com/health",method="GET"} 1.7356896e+09`,
+		`ponghub_service_status{service="API",status="part"} 1`,
+		`ponghub_service_status{service="API",status="all"} 0`,
+		`ponghub_service_availability{service="API"} 0.75`,
+	}
+	for _, line := range expectedLines {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("Expected output to contain line %q, got:\n%s", line, output)
+		}
+	}
+
+	// endpoints that are down have no meaningful response time, and plain HTTP has no certificate
+	if strings.Contains(output, `ponghub_endpoint_response_time_seconds{service="API",url="http://`) {
+		t.Error("Response time should not be exported for endpoints that are down")
+	}
+	if strings.Contains(output, `ponghub_endpoint_response_time_seconds{service="API",url="https://api.example.com/admin"`) {
+		t.Error("Response time should not be exported for endpoints failing during maintenance")
+	}
+	if strings.Contains(output, `ponghub_endpoint_cert_remaining_days{service="API",url="http://`) {
+		t.Error("Certificate metrics should not be exported for plain HTTP endpoints")
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ponghub.prom")
+	checkResult := []checker.Service{{Name: "API", Status: chk_result.ALL}}
+
+	if err := WriteFile(path, checkResult, nil); err != nil {
+		t.Fatalf("WriteFile() returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the metrics file to be written, got %v", err)
+	}
+	if !strings.Contains(string(data), `ponghub_service_status{service="API",status="all"} 1`) {
+		t.Errorf("Expected the service status in the metrics file, got:\n%s", data)
+	}
+
+	// the collector only reads *.prom files, so no temporary file may be left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("Expected only the metrics file in the directory, got %d entries", len(entries))
+	}
+}
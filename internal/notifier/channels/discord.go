@@ -2,7 +2,6 @@ package channels
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
@@ -11,18 +10,19 @@ import (
 // DiscordNotifier implements Discord webhook notifications
 type DiscordNotifier struct {
 	config *configure.DiscordConfig
+	env    Env
 }
 
 // NewDiscordNotifier creates a new Discord notifier
-func NewDiscordNotifier(config *configure.DiscordConfig) *DiscordNotifier {
-	return &DiscordNotifier{config: config}
+func NewDiscordNotifier(config *configure.DiscordConfig, env Env) *DiscordNotifier {
+	return &DiscordNotifier{config: config, env: env}
 }
 
 // Send sends a Discord webhook notification with enhanced features
 func (d *DiscordNotifier) Send(title, message string) error {
 	webhookURL := d.config.WebhookURL
 	if webhookURL == "" {
-		webhookURL = os.Getenv("DISCORD_WEBHOOK_URL")
+		webhookURL = d.env.Get("DISCORD_WEBHOOK_URL")
 	}
 
 	if webhookURL == "" {
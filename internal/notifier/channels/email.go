@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"net/smtp"
-	"os"
 	"time"
 
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
@@ -14,18 +13,19 @@ import (
 // EmailNotifier implements email notifications
 type EmailNotifier struct {
 	config *configure.EmailConfig
+	env    Env
 }
 
 // NewEmailNotifier creates a new email notifier
-func NewEmailNotifier(config *configure.EmailConfig) *EmailNotifier {
-	return &EmailNotifier{config: config}
+func NewEmailNotifier(config *configure.EmailConfig, env Env) *EmailNotifier {
+	return &EmailNotifier{config: config, env: env}
 }
 
 // Send sends an email notification with secure SMTP connection
 func (e *EmailNotifier) Send(title, message string) error {
 	// Get SMTP credentials from environment variables
-	username := os.Getenv("SMTP_USERNAME")
-	password := os.Getenv("SMTP_PASSWORD")
+	username := e.env.Get("SMTP_USERNAME")
+	password := e.env.Get("SMTP_PASSWORD")
 
 	if username == "" || password == "" {
 		return fmt.Errorf("SMTP credentials not found in environment variables")
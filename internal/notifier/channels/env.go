@@ -0,0 +1,41 @@
+package channels
+
+import (
+	"os"
+	"strings"
+)
+
+// Env looks up the secrets of a notification channel in the environment.
+// The variables of a named channel instance carry its name as a suffix, such as SLACK_WEBHOOK_URL_DB_TEAM
+// for the instance db-team; the plain variable is used when the suffixed one is not set.
+type Env struct {
+	suffix string
+}
+
+// NewEnv returns the environment of the channel instance called name, or of the single channel of a type if name is empty
+func NewEnv(name string) Env {
+	if name == "" {
+		return Env{}
+	}
+	suffix := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return Env{suffix: "_" + suffix}
+}
+
+// Get returns the value of the variable key for the channel
+func (e Env) Get(key string) string {
+	if e.suffix != "" {
+		if value := os.Getenv(key + e.suffix); value != "" {
+			return value
+		}
+	}
+	return os.Getenv(key)
+}
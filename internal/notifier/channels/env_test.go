@@ -0,0 +1,23 @@
+package channels
+
+import "testing"
+
+func TestEnvGet(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "plain")
+	t.Setenv("SLACK_WEBHOOK_URL_DB_TEAM", "db-team")
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"", "plain"},
+		{"db-team", "db-team"},
+		{"DB Team", "db-team"},
+		{"ops", "plain"},
+	}
+	for _, test := range tests {
+		if got := NewEnv(test.name).Get("SLACK_WEBHOOK_URL"); got != test.expected {
+			t.Errorf("Expected %q for channel %q, got %q", test.expected, test.name, got)
+		}
+	}
+}
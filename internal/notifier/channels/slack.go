@@ -2,7 +2,6 @@ package channels
 
 import (
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
@@ -11,18 +10,19 @@ import (
 // SlackNotifier implements Slack webhook notifications
 type SlackNotifier struct {
 	config *configure.SlackConfig
+	env    Env
 }
 
 // NewSlackNotifier creates a new Slack notifier
-func NewSlackNotifier(config *configure.SlackConfig) *SlackNotifier {
-	return &SlackNotifier{config: config}
+func NewSlackNotifier(config *configure.SlackConfig, env Env) *SlackNotifier {
+	return &SlackNotifier{config: config, env: env}
 }
 
 // Send sends a Slack webhook notification with enhanced features
 func (s *SlackNotifier) Send(title, message string) error {
 	webhookURL := s.config.WebhookURL
 	if webhookURL == "" {
-		webhookURL = os.Getenv("SLACK_WEBHOOK_URL")
+		webhookURL = s.env.Get("SLACK_WEBHOOK_URL")
 	}
 
 	if webhookURL == "" {
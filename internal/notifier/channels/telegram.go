@@ -2,7 +2,6 @@ package channels
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
@@ -12,23 +11,24 @@ import (
 // TelegramNotifier implements Telegram bot notifications
 type TelegramNotifier struct {
 	config *configure.TelegramConfig
+	env    Env
 }
 
 // NewTelegramNotifier creates a new Telegram notifier
-func NewTelegramNotifier(config *configure.TelegramConfig) *TelegramNotifier {
-	return &TelegramNotifier{config: config}
+func NewTelegramNotifier(config *configure.TelegramConfig, env Env) *TelegramNotifier {
+	return &TelegramNotifier{config: config, env: env}
 }
 
 // Send sends a Telegram bot notification with enhanced features
 func (t *TelegramNotifier) Send(title, message string) error {
 	botToken := t.config.BotToken
 	if botToken == "" {
-		botToken = os.Getenv("TELEGRAM_BOT_TOKEN")
+		botToken = t.env.Get("TELEGRAM_BOT_TOKEN")
 	}
 
 	chatID := t.config.ChatID
 	if chatID == "" {
-		chatID = os.Getenv("TELEGRAM_CHAT_ID")
+		chatID = t.env.Get("TELEGRAM_CHAT_ID")
 	}
 
 	if botToken == "" || chatID == "" {
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"strings"
 	"text/template"
 	"time"
@@ -17,18 +16,19 @@ import (
 // WebhookNotifier implements generic webhook notifications
 type WebhookNotifier struct {
 	config *configure.WebhookConfig
+	env    Env
 }
 
 // NewWebhookNotifier creates a new generic webhook notifier
-func NewWebhookNotifier(config *configure.WebhookConfig) *WebhookNotifier {
-	return &WebhookNotifier{config: config}
+func NewWebhookNotifier(config *configure.WebhookConfig, env Env) *WebhookNotifier {
+	return &WebhookNotifier{config: config, env: env}
 }
 
 // Send sends a generic webhook notification with enhanced configuration support
 func (w *WebhookNotifier) Send(title, message string) error {
 	url := w.config.URL
 	if url == "" {
-		url = os.Getenv("WEBHOOK_URL")
+		url = w.env.Get("WEBHOOK_URL")
 	}
 
 	if url == "" {
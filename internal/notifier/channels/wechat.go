@@ -2,7 +2,6 @@ package channels
 
 import (
 	"fmt"
-	"os"
 	"regexp"
 	"time"
 
@@ -12,18 +11,19 @@ import (
 // WeChatNotifier implements WeChat Work webhook notifications
 type WeChatNotifier struct {
 	config *configure.WeChatConfig
+	env    Env
 }
 
 // NewWeChatNotifier creates a new WeChat notifier
-func NewWeChatNotifier(config *configure.WeChatConfig) *WeChatNotifier {
-	return &WeChatNotifier{config: config}
+func NewWeChatNotifier(config *configure.WeChatConfig, env Env) *WeChatNotifier {
+	return &WeChatNotifier{config: config, env: env}
 }
 
 // Send sends a WeChat Work webhook notification with enhanced features
 func (w *WeChatNotifier) Send(title, message string) error {
 	webhookURL := w.config.WebhookURL
 	if webhookURL == "" {
-		webhookURL = os.Getenv("WECHAT_WEBHOOK_URL")
+		webhookURL = w.env.Get("WECHAT_WEBHOOK_URL")
 	}
 
 	if webhookURL == "" {
@@ -142,7 +142,7 @@ func (w *WeChatNotifier) sendWithRetry(webhookURL string, payload map[string]int
 
 // ValidateConfig validates the WeChat configuration
 func (w *WeChatNotifier) ValidateConfig() error {
-	if w.config.WebhookURL == "" && os.Getenv("WECHAT_WEBHOOK_URL") == "" {
+	if w.config.WebhookURL == "" && w.env.Get("WECHAT_WEBHOOK_URL") == "" {
 		return fmt.Errorf("WeChat webhook URL not configured")
 	}
 
@@ -0,0 +1,260 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// acksLockTimeout is how long a change to the acknowledgements file waits for another process changing it
+const acksLockTimeout = 10 * time.Second
+
+// Acknowledge adds an acknowledgement to the file at path, where it waits for the next check of its service.
+// The acknowledgement is dated now if it has no time.
+func Acknowledge(path string, ack notifier.Acknowledgement) error {
+	if ack.Service == "" {
+		return fmt.Errorf("acknowledgement has no service")
+	}
+	if ack.Time == "" {
+		ack.Time = time.Now().Format(time.RFC3339)
+	} else if _, err := time.Parse(time.RFC3339, ack.Time); err != nil {
+		return fmt.Errorf("invalid acknowledgement time %q: expected an RFC 3339 time", ack.Time)
+	}
+
+	// the ack command, the API and the checks may change the file from different processes
+	unlock, err := common.LockFile(path, acksLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	acks, err := loadAcks(path)
+	if err != nil {
+		return err
+	}
+	return saveAcks(path, append(acks, ack))
+}
+
+// loadAcks reads the acknowledgements waiting in the file at path; a missing file holds none
+func loadAcks(path string) ([]notifier.Acknowledgement, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var acks []notifier.Acknowledgement
+	if err := json.Unmarshal(data, &acks); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return acks, nil
+}
+
+// saveAcks writes the acknowledgements waiting to be applied to the file at path
+func saveAcks(path string, acks []notifier.Acknowledgement) error {
+	if acks == nil {
+		acks = []notifier.Acknowledgement{}
+	}
+	data, err := json.MarshalIndent(acks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, data, 0644)
+}
+
+// getEscalationPolicies returns the first escalation policy matching each configured service, if any
+func getEscalationPolicies(cfg *configure.Configure) map[string]*configure.EscalationConfig {
+	policies := make(map[string]*configure.EscalationConfig)
+	if cfg.Notifications == nil {
+		return policies
+	}
+	for _, service := range cfg.Services {
+		for i, escalation := range cfg.Notifications.Escalations {
+			conditions := configure.RouteConfig{Services: escalation.Services, Tags: escalation.Tags, Severities: escalation.Severities}
+			if matchRoute(conditions, notifier.Alert{Service: service.Name}, service) {
+				policies[service.Name] = &cfg.Notifications.Escalations[i]
+				break
+			}
+		}
+	}
+	return policies
+}
+
+// Escalate follows the escalation policies of the endpoints that are down in the check results. It applies the
+// acknowledgements of the checked services to their open outages, then returns alerts along with the escalations
+// and reminders due at the time of the check. The down alerts among alerts also go to the steps due at once.
+// The acknowledgements of the services that were not checked are returned to be applied later.
+func Escalate(alerts []notifier.Alert, checkResult []checker.Service, state notifier.State, acks []notifier.Acknowledgement, cfg *configure.Configure) ([]notifier.Alert, []notifier.Acknowledgement) {
+	policies := getEscalationPolicies(cfg)
+	checked := make(map[string]bool, len(checkResult))
+
+	for _, serviceResult := range checkResult {
+		checked[serviceResult.Name] = true
+		policy := policies[serviceResult.Name]
+		endpoints := state[serviceResult.Name]
+		for _, endpoint := range serviceResult.Endpoints {
+			current, exists := endpoints[endpoint.Key]
+			if !exists || endpoint.Status == chk_result.MAINTENANCE {
+				continue
+			}
+			if policy == nil || current.Status != notifier.StateDown || current.Flapping {
+				current.Escalation = nil
+				endpoints[endpoint.Key] = current
+				continue
+			}
+
+			// copy the escalation so that the previous state is left untouched
+			escalation := notifier.EscalationState{Policy: policy.Name, LastNotified: endpoint.StartTime}
+			if current.Escalation != nil && current.Escalation.Policy == policy.Name {
+				escalation = *current.Escalation
+			}
+			applyAcks(&escalation, acks, serviceResult.Name, endpoint, current.Since)
+
+			if escalation.AcknowledgedAt == "" {
+				alerts = escalateEndpoint(alerts, &escalation, policy, serviceResult.Name, endpoint, current.Since)
+			}
+			current.Escalation = &escalation
+			endpoints[endpoint.Key] = current
+		}
+	}
+
+	var remaining []notifier.Acknowledgement
+	for _, ack := range acks {
+		if !checked[ack.Service] {
+			remaining = append(remaining, ack)
+		}
+	}
+	return alerts, remaining
+}
+
+// escalateWithAcks escalates the outages of the checked services with the acknowledgements waiting in the file
+// at acksPath, then removes the acknowledgements that were used from the file
+func escalateWithAcks(alerts []notifier.Alert, checkResult []checker.Service, state notifier.State, cfg *configure.Configure, acksPath string) []notifier.Alert {
+	unlock, err := common.LockFile(acksPath, acksLockTimeout)
+	if err != nil {
+		log.Println("Error locking acknowledgements, skipping escalations until the next check:", err)
+		holdEscalations(checkResult, state)
+		return alerts
+	}
+	defer unlock()
+
+	acks, err := loadAcks(acksPath)
+	if err != nil {
+		log.Println("Error loading acknowledgements, skipping escalations until the next check:", err)
+		holdEscalations(checkResult, state)
+		return alerts
+	}
+
+	alerts, remaining := Escalate(alerts, checkResult, state, acks, cfg)
+	if len(remaining) != len(acks) {
+		if err := saveAcks(acksPath, remaining); err != nil {
+			log.Println("Error saving acknowledgements:", err)
+		}
+	}
+	return alerts
+}
+
+// holdEscalations keeps the escalations of the outages in state as they were, for a run that cannot tell whether
+// they were acknowledged. Only the escalations of the outages that ended are dropped, so that a later outage starts
+// its own.
+func holdEscalations(checkResult []checker.Service, state notifier.State) {
+	for _, serviceResult := range checkResult {
+		endpoints := state[serviceResult.Name]
+		for _, endpoint := range serviceResult.Endpoints {
+			current, exists := endpoints[endpoint.Key]
+			if !exists || endpoint.Status == chk_result.MAINTENANCE {
+				continue
+			}
+			if current.Status != notifier.StateDown || current.Flapping {
+				current.Escalation = nil
+				endpoints[endpoint.Key] = current
+			}
+		}
+	}
+}
+
+// applyAcks marks the escalation of an endpoint as acknowledged by the first acknowledgement made since its outage began
+func applyAcks(escalation *notifier.EscalationState, acks []notifier.Acknowledgement, serviceName string, endpoint checker.Endpoint, since string) {
+	if escalation.AcknowledgedAt != "" {
+		return
+	}
+	for _, ack := range acks {
+		if ack.Service != serviceName || (ack.Endpoint != "" && ack.Endpoint != endpoint.URL && ack.Endpoint != endpoint.Key) {
+			continue
+		}
+		if elapsed(since, ack.Time) < 0 {
+			// made before this outage began
+			continue
+		}
+		escalation.AcknowledgedBy = ack.By
+		escalation.AcknowledgedAt = ack.Time
+		escalation.Note = ack.Note
+		return
+	}
+}
+
+// escalateEndpoint notifies the steps of policy that have come due for an unacknowledged outage, or sends a reminder
+// once its repeat interval has passed since the last notification. A down alert sent for the endpoint in the same run
+// takes the channels of the steps due at once, instead of a separate escalation.
+func escalateEndpoint(alerts []notifier.Alert, escalation *notifier.EscalationState, policy *configure.EscalationConfig, serviceName string, endpoint checker.Endpoint, since string) []notifier.Alert {
+	checkTime := endpoint.StartTime
+	downFor := elapsed(since, checkTime)
+	downAlert := slices.IndexFunc(alerts, func(alert notifier.Alert) bool {
+		return alert.Type == notifier.AlertDown && alert.Service == serviceName && alert.Endpoint.Key == endpoint.Key
+	})
+
+	var channels []string
+	for escalation.Steps < len(policy.Steps) && policy.Steps[escalation.Steps].ParsedAfter <= downFor {
+		channels = append(channels, policy.Steps[escalation.Steps].Channels...)
+		escalation.Steps++
+	}
+
+	switch {
+	case downAlert >= 0:
+		alerts[downAlert].Policy = policy.Name
+		alerts[downAlert].Step = escalation.Steps
+		alerts[downAlert].Channels = append(alerts[downAlert].Channels, channels...)
+		escalation.LastNotified = checkTime
+	case len(channels) > 0:
+		alerts = append(alerts, notifier.Alert{
+			Type:     notifier.AlertEscalated,
+			Service:  serviceName,
+			Endpoint: endpoint,
+			Since:    since,
+			Duration: downFor,
+			Policy:   policy.Name,
+			Step:     escalation.Steps,
+			Channels: channels,
+		})
+		escalation.LastNotified = checkTime
+	case policy.ParsedRepeat > 0 && elapsed(escalation.LastNotified, checkTime) >= policy.ParsedRepeat:
+		// remind the channels of every step notified so far, on top of the routed ones
+		var notified []string
+		for _, step := range policy.Steps[:escalation.Steps] {
+			notified = append(notified, step.Channels...)
+		}
+		alerts = append(alerts, notifier.Alert{
+			Type:     notifier.AlertReminder,
+			Service:  serviceName,
+			Endpoint: endpoint,
+			Since:    since,
+			Duration: downFor,
+			Policy:   policy.Name,
+			Step:     escalation.Steps,
+			Channels: notified,
+		})
+		escalation.LastNotified = checkTime
+	}
+	return alerts
+}
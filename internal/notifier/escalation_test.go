@@ -0,0 +1,213 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// testEscalationConfig returns a configuration escalating the outages of the critical API service
+// to team, then oncall after 15 minutes and manager after an hour, with a reminder every 30 minutes
+func testEscalationConfig() *configure.Configure {
+	cfg := testAlertConfig(1, nil)
+	cfg.Services[0].Severity = "critical"
+	cfg.Notifications = &configure.NotificationConfig{
+		Escalations: []configure.EscalationConfig{
+			{Name: "other", Services: []string{"db-*"}, Steps: []configure.EscalationStep{{Channels: []string{"db"}}}},
+			{
+				Name:       "critical",
+				Severities: []string{"critical"},
+				Steps: []configure.EscalationStep{
+					{Channels: []string{"team"}},
+					{ParsedAfter: 15 * time.Minute, Channels: []string{"oncall"}},
+					{ParsedAfter: time.Hour, Channels: []string{"manager"}},
+				},
+				ParsedRepeat: 30 * time.Minute,
+			},
+		},
+	}
+	return cfg
+}
+
+// escalateAt checks the API service with the given status and escalates its outage
+func escalateAt(status chk_result.CheckResult, checkTime string, state notifier.State, acks []notifier.Acknowledgement, cfg *configure.Configure) ([]notifier.Alert, notifier.State) {
+	checkResult := checkAt(status, checkTime)
+	alerts, state := DetectAlerts(checkResult, state, nil, cfg)
+	alerts, _ = Escalate(alerts, checkResult, state, acks, cfg)
+	return alerts, state
+}
+
+func TestEscalate(t *testing.T) {
+	cfg := testEscalationConfig()
+	state := make(notifier.State)
+
+	alerts, state := escalateAt(chk_result.ALL, "2025-01-01T00:00:00Z", state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Fatalf("Expected no alert while up, got %+v", alerts)
+	}
+
+	alerts, state = escalateAt(chk_result.NONE, "2025-01-01T00:05:00Z", state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertDown || alerts[0].Policy != "critical" || alerts[0].Step != 1 {
+		t.Fatalf("Expected a down alert reaching the first step, got %+v", alerts)
+	}
+	if len(alerts[0].Channels) != 1 || alerts[0].Channels[0] != "team" {
+		t.Errorf("Expected the down alert to go to team, got %v", alerts[0].Channels)
+	}
+
+	alerts, state = escalateAt(chk_result.NONE, "2025-01-01T00:15:00Z", state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert before the second step is due, got %+v", alerts)
+	}
+
+	alerts, state = escalateAt(chk_result.NONE, "2025-01-01T00:20:00Z", state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertEscalated || alerts[0].Step != 2 || alerts[0].Duration != 15*time.Minute {
+		t.Fatalf("Expected an escalation to the second step after 15m, got %+v", alerts)
+	}
+	if len(alerts[0].Channels) != 1 || alerts[0].Channels[0] != "oncall" {
+		t.Errorf("Expected the escalation to go to oncall, got %v", alerts[0].Channels)
+	}
+
+	alerts, state = escalateAt(chk_result.NONE, "2025-01-01T00:50:00Z", state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertReminder {
+		t.Fatalf("Expected a reminder 30m after the escalation, got %+v", alerts)
+	}
+	if len(alerts[0].Channels) != 2 || alerts[0].Channels[0] != "team" || alerts[0].Channels[1] != "oncall" {
+		t.Errorf("Expected the reminder to go to the notified steps, got %v", alerts[0].Channels)
+	}
+
+	alerts, state = escalateAt(chk_result.NONE, "2025-01-01T01:05:00Z", state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertEscalated || alerts[0].Step != 3 || alerts[0].Channels[0] != "manager" {
+		t.Fatalf("Expected an escalation to manager after an hour, got %+v", alerts)
+	}
+
+	alerts, state = escalateAt(chk_result.ALL, "2025-01-01T01:10:00Z", state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertRecovered {
+		t.Fatalf("Expected a recovery alert, got %+v", alerts)
+	}
+	if state["API"]["health"].Escalation != nil {
+		t.Errorf("Expected the escalation to end with the outage, got %+v", state["API"]["health"].Escalation)
+	}
+}
+
+func TestEscalateAcknowledged(t *testing.T) {
+	cfg := testEscalationConfig()
+	state := make(notifier.State)
+	_, state = escalateAt(chk_result.ALL, "2025-01-01T00:00:00Z", state, nil, cfg)
+	_, state = escalateAt(chk_result.NONE, "2025-01-01T00:05:00Z", state, nil, cfg)
+
+	acks := []notifier.Acknowledgement{
+		// made before the outage, so it is ignored
+		{Service: "API", By: "bob", Time: "2025-01-01T00:01:00Z"},
+		{Service: "API", Endpoint: "https://api.example.com/health", By: "alice", Note: "looking", Time: "2025-01-01T00:10:00Z"},
+	}
+	alerts, state := escalateAt(chk_result.NONE, "2025-01-01T00:30:00Z", state, acks, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no escalation once acknowledged, got %+v", alerts)
+	}
+	escalation := state["API"]["health"].Escalation
+	if escalation == nil || escalation.AcknowledgedBy != "alice" || escalation.Note != "looking" {
+		t.Fatalf("Expected the outage to be acknowledged by alice, got %+v", escalation)
+	}
+
+	alerts, _ = escalateAt(chk_result.NONE, "2025-01-01T02:00:00Z", state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no reminder once acknowledged, got %+v", alerts)
+	}
+}
+
+func TestEscalateKeepsAcksOfUncheckedServices(t *testing.T) {
+	acks := []notifier.Acknowledgement{
+		{Service: "API", Time: "2025-01-01T00:10:00Z"},
+		{Service: "Website", Time: "2025-01-01T00:10:00Z"},
+	}
+	_, remaining := Escalate(nil, checkAt(chk_result.ALL, "2025-01-01T00:30:00Z"), make(notifier.State), acks, testEscalationConfig())
+	if len(remaining) != 1 || remaining[0].Service != "Website" {
+		t.Errorf("Expected only the acknowledgement of the unchecked service to remain, got %+v", remaining)
+	}
+}
+
+func TestEscalateWithUnreadableAcks(t *testing.T) {
+	cfg := testEscalationConfig()
+	acksPath := filepath.Join(t.TempDir(), "acknowledgements.json")
+	state := make(notifier.State)
+	_, state = escalateAt(chk_result.ALL, "2025-01-01T00:00:00Z", state, nil, cfg)
+	_, state = escalateAt(chk_result.NONE, "2025-01-01T00:05:00Z", state, nil, cfg)
+
+	// the outage may have been acknowledged in the file that cannot be read
+	if err := os.WriteFile(acksPath, []byte("not json"), 0644); err != nil {
+		t.Fatalf("Expected no error writing, got %v", err)
+	}
+	checkResult := checkAt(chk_result.NONE, "2025-01-01T00:30:00Z")
+	alerts, state := DetectAlerts(checkResult, state, nil, cfg)
+	if alerts = escalateWithAcks(alerts, checkResult, state, cfg, acksPath); len(alerts) != 0 {
+		t.Errorf("Expected no escalation or reminder without the acknowledgements, got %+v", alerts)
+	}
+	if escalation := state["API"]["health"].Escalation; escalation == nil || escalation.Steps != 1 || escalation.LastNotified != "2025-01-01T00:05:00Z" {
+		t.Errorf("Expected the escalation to be kept as it was, got %+v", escalation)
+	}
+
+	// the escalation of an outage that ended is dropped all the same
+	checkResult = checkAt(chk_result.ALL, "2025-01-01T01:00:00Z")
+	alerts, state = DetectAlerts(checkResult, state, nil, cfg)
+	escalateWithAcks(alerts, checkResult, state, cfg, acksPath)
+	if escalation := state["API"]["health"].Escalation; escalation != nil {
+		t.Errorf("Expected no escalation once recovered, got %+v", escalation)
+	}
+}
+
+func TestAcknowledge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acknowledgements.json")
+	if err := Acknowledge(path, notifier.Acknowledgement{Service: "API", By: "alice"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := Acknowledge(path, notifier.Acknowledgement{Service: "Website", Time: "2025-01-01T00:00:00Z"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := Acknowledge(path, notifier.Acknowledgement{}); err == nil {
+		t.Error("Expected an error for an acknowledgement without service")
+	}
+
+	acks, err := loadAcks(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(acks) != 2 || acks[0].Service != "API" || acks[1].Time != "2025-01-01T00:00:00Z" {
+		t.Fatalf("Expected both acknowledgements, got %+v", acks)
+	}
+	if _, err := time.Parse(time.RFC3339, acks[0].Time); err != nil {
+		t.Errorf("Expected the acknowledgement to be dated, got %q", acks[0].Time)
+	}
+}
+
+func TestAcknowledgeWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "acknowledgements.json")
+
+	// another process, such as the daemon applying acknowledgements, holds the file
+	unlock, err := common.LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock to be taken, got %v", err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- Acknowledge(path, notifier.Acknowledgement{Service: "API"})
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the acknowledgement to wait for the lock, got %v", err)
+	}
+	unlock()
+
+	if err := <-done; err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if acks, err := loadAcks(path); err != nil || len(acks) != 1 {
+		t.Errorf("Expected the acknowledgement to be saved once the lock was released, got %+v, %v", acks, err)
+	}
+}
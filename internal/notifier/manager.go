@@ -3,24 +3,35 @@ package notifier
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/wcy-dt/ponghub/internal/notifier/channels"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
 )
 
+// namedChannel is a notification service along with the name alerts are routed to it by
+type namedChannel struct {
+	name        string
+	channelType string
+	service     NotificationService
+	message     messageTemplates
+}
+
 // NotificationManager manages multiple notification services
 type NotificationManager struct {
-	services []NotificationService
+	channels []namedChannel
+	catchAll []string // names of the channels receiving the alerts no route matches
+	routes   []configure.RouteConfig
 	config   *configure.NotificationConfig
 }
 
-// NewNotificationManager creates a new notification manager
+// NewNotificationManager creates a new notification manager.
+// The methods are named after their type and the channel instances by their own name.
 func NewNotificationManager(config *configure.NotificationConfig) *NotificationManager {
-	manager := &NotificationManager{
-		config:   config,
-		services: make([]NotificationService, 0),
-	}
+	manager := &NotificationManager{config: config}
 
 	// If no notification config is provided, use default method
 	if config == nil {
@@ -31,7 +42,8 @@ func NewNotificationManager(config *configure.NotificationConfig) *NotificationM
 			Methods: []string{"default"},
 			Default: defaultConfig,
 		}
-		manager.services = append(manager.services, channels.NewDefaultNotifier(defaultConfig))
+		manager.addChannel("default", "default", channels.NewDefaultNotifier(defaultConfig), nil)
+		manager.catchAll = []string{"default"}
 		return manager
 	}
 
@@ -40,92 +52,216 @@ func NewNotificationManager(config *configure.NotificationConfig) *NotificationM
 		return &NotificationManager{}
 	}
 
-	// If no methods are specified but notifications are enabled, use default
-	if len(config.Methods) == 0 {
+	// If no methods or channels are specified but notifications are enabled, use default
+	if len(config.Methods) == 0 && len(config.Channels) == 0 {
 		log.Println("Notifications enabled but no methods specified, using default GitHub Actions notification")
 		if config.Default == nil {
 			config.Default = &configure.DefaultConfig{Enabled: true}
 		}
 		config.Methods = []string{"default"}
-		manager.services = append(manager.services, channels.NewDefaultNotifier(config.Default))
+		manager.addChannel("default", "default", channels.NewDefaultNotifier(config.Default), nil)
+		manager.catchAll = []string{"default"}
 		return manager
 	}
 
 	// Initialize notification services based on configured methods
 	for _, method := range config.Methods {
-		switch strings.ToLower(method) {
-		case "default":
-			if config.Default == nil {
-				config.Default = &configure.DefaultConfig{Enabled: true}
-			}
-			manager.services = append(manager.services, channels.NewDefaultNotifier(config.Default))
-		case "email":
-			if config.Email != nil {
-				manager.services = append(manager.services, channels.NewEmailNotifier(config.Email))
-			}
-		case "discord":
-			if config.Discord != nil {
-				manager.services = append(manager.services, channels.NewDiscordNotifier(config.Discord))
-			}
-		case "slack":
-			if config.Slack != nil {
-				manager.services = append(manager.services, channels.NewSlackNotifier(config.Slack))
-			}
-		case "telegram":
-			if config.Telegram != nil {
-				manager.services = append(manager.services, channels.NewTelegramNotifier(config.Telegram))
-			}
-		case "wechat":
-			if config.WeChat != nil {
-				manager.services = append(manager.services, channels.NewWeChatNotifier(config.WeChat))
-			}
-		case "webhook":
-			if config.Webhook != nil {
-				manager.services = append(manager.services, channels.NewWebhookNotifier(config.Webhook))
-			}
-		default:
-			log.Printf("Unknown notification method: %s", method)
+		if slices.ContainsFunc(config.Channels, func(channel configure.ChannelConfig) bool { return channel.Name == method }) {
+			// a channel instance used as a method, created below
+			continue
+		}
+		method = strings.ToLower(method)
+		if method == "default" && config.Default == nil {
+			config.Default = &configure.DefaultConfig{Enabled: true}
+		}
+		service := newNotificationService(configure.ChannelConfig{
+			Type:     method,
+			Default:  config.Default,
+			Discord:  config.Discord,
+			Email:    config.Email,
+			Slack:    config.Slack,
+			Telegram: config.Telegram,
+			WeChat:   config.WeChat,
+			Webhook:  config.Webhook,
+		})
+		if service != nil {
+			manager.addChannel(method, method, service, nil)
+		}
+	}
+
+	// Initialize the named channel instances
+	for _, channel := range config.Channels {
+		if service := newNotificationService(channel); service != nil {
+			manager.addChannel(channel.Name, strings.ToLower(channel.Type), service, channel.Message)
+		}
+	}
+
+	// Without routes every alert goes everywhere; with routes, only the methods receive the unmatched alerts
+	manager.routes = config.Routes
+	for _, channel := range manager.channels {
+		if len(config.Routes) == 0 || slices.ContainsFunc(config.Methods, func(method string) bool { return strings.EqualFold(method, channel.name) }) {
+			manager.catchAll = append(manager.catchAll, channel.name)
 		}
 	}
 
 	return manager
 }
 
-// SendNotification sends notification through all configured services
-func (nm *NotificationManager) SendNotification(title, message string) {
-	if nm.config == nil || !nm.config.Enabled || len(nm.services) == 0 {
+// newNotificationService creates the notification service of a channel, or returns nil if the settings of its type are missing.
+// Channel instances read their secrets from the environment variables suffixed with their name,
+// the single channels of NotificationConfig, which have no name, from the plain ones.
+func newNotificationService(channel configure.ChannelConfig) NotificationService {
+	env := channels.NewEnv(channel.Name)
+	switch strings.ToLower(channel.Type) {
+	case "default":
+		if channel.Default == nil {
+			channel.Default = &configure.DefaultConfig{Enabled: true}
+		}
+		return channels.NewDefaultNotifier(channel.Default)
+	case "email":
+		if channel.Email != nil {
+			return channels.NewEmailNotifier(channel.Email, env)
+		}
+	case "discord":
+		if channel.Discord != nil {
+			return channels.NewDiscordNotifier(channel.Discord, env)
+		}
+	case "slack":
+		if channel.Slack != nil {
+			return channels.NewSlackNotifier(channel.Slack, env)
+		}
+	case "telegram":
+		if channel.Telegram != nil {
+			return channels.NewTelegramNotifier(channel.Telegram, env)
+		}
+	case "wechat":
+		if channel.WeChat != nil {
+			return channels.NewWeChatNotifier(channel.WeChat, env)
+		}
+	case "webhook":
+		if channel.Webhook != nil {
+			return channels.NewWebhookNotifier(channel.Webhook, env)
+		}
+	default:
+		log.Printf("Unknown notification method: %s", channel.Type)
+		return nil
+	}
+	if channel.Name == "" {
+		log.Printf("No %s settings for the %s method, skipping it", channel.Type, channel.Type)
+	} else {
+		log.Printf("No %s settings for notification channel %s, skipping it", channel.Type, channel.Name)
+	}
+	return nil
+}
+
+// addChannel registers a notification service of the given type under name, rendering its notifications
+// with its own message templates or the shared ones
+func (nm *NotificationManager) addChannel(name, channelType string, service NotificationService, message *configure.MessageTemplate) {
+	nm.channels = append(nm.channels, namedChannel{
+		name:        name,
+		channelType: channelType,
+		service:     service,
+		message:     newMessageTemplates(name, message, nm.config.Message),
+	})
+}
+
+// SendNotification sends notification through all configured services, or only through the channels
+// called channelNames if any are given. It returns the outcome of every delivery, along with an error
+// naming every channel that failed to deliver the notification.
+func (nm *NotificationManager) SendNotification(title, message string, channelNames ...string) ([]notifier.Delivery, error) {
+	if nm.config == nil || !nm.config.Enabled || len(nm.channels) == 0 {
 		log.Println("Notifications are disabled or no services configured")
-		return
+		return nil, nil
+	}
+
+	selected, err := nm.selectChannels(channelNames)
+	if err != nil {
+		return nil, err
 	}
 
-	log.Printf("Sending notifications through %d service(s)", len(nm.services))
+	log.Printf("Sending notifications through %d service(s)", len(selected))
 
-	var failedServices []string
-	for i, service := range nm.services {
-		if err := service.Send(title, message); err != nil {
-			serviceName := nm.getServiceName(i)
-			log.Printf("Failed to send notification via %s: %v", serviceName, err)
-			failedServices = append(failedServices, serviceName)
-		} else {
-			serviceName := nm.getServiceName(i)
-			log.Printf("Successfully sent notification via %s", serviceName)
+	var deliveries []notifier.Delivery
+	for _, channel := range selected {
+		deliveries = append(deliveries, nm.send(channel, title, message, 0))
+	}
+	return deliveries, deliveryError(deliveries)
+}
+
+// selectChannels returns the channels called channelNames, or every channel if none are given
+func (nm *NotificationManager) selectChannels(channelNames []string) ([]namedChannel, error) {
+	if len(channelNames) == 0 {
+		return nm.channels, nil
+	}
+	var selected []namedChannel
+	for _, channelName := range channelNames {
+		name, exists := nm.channelName(channelName)
+		if !exists {
+			return nil, fmt.Errorf("unknown notification channel %q", channelName)
+		}
+		selected = append(selected, nm.channels[slices.IndexFunc(nm.channels, func(channel namedChannel) bool { return channel.name == name })])
+	}
+	return selected, nil
+}
+
+// SendAlerts sends every channel a notification holding the alerts routed to it, rendered with its message templates.
+// It returns the outcome of every delivery, along with an error naming every channel that failed to deliver its notification.
+func (nm *NotificationManager) SendAlerts(alerts []notifier.Alert, services []configure.Service) ([]notifier.Delivery, error) {
+	if !nm.IsEnabled() {
+		log.Println("Notifications are disabled or no services configured")
+		return nil, nil
+	}
+
+	routed := nm.routeAlerts(alerts, services)
+	now := time.Now()
+	var deliveries []notifier.Delivery
+	for _, channel := range nm.channels {
+		channelAlerts := routed[channel.name]
+		if len(channelAlerts) == 0 {
+			continue
 		}
+		title, message := channel.message.render(newMessageData(channel.name, nm.config.ReportURL, channelAlerts, now))
+		deliveries = append(deliveries, nm.send(channel, title, message, len(channelAlerts)))
 	}
+	return deliveries, deliveryError(deliveries)
+}
 
-	if len(failedServices) > 0 {
-		log.Printf("Failed to send notifications via: %s", strings.Join(failedServices, ", "))
+// send delivers a notification holding alertNum alerts through a single channel
+func (nm *NotificationManager) send(channel namedChannel, title, message string, alertNum int) notifier.Delivery {
+	start := time.Now()
+	err := channel.service.Send(title, message)
+	delivery := notifier.Delivery{
+		Channel: channel.name,
+		Type:    channel.channelType,
+		Alerts:  alertNum,
+		Elapsed: time.Since(start),
+		Err:     err,
 	}
+
+	if err != nil {
+		log.Printf("Failed to send notification via %s (%s): %v", channel.name, channel.channelType, err)
+	} else {
+		log.Printf("Successfully sent notification via %s (%s)", channel.name, channel.channelType)
+	}
+	return delivery
 }
 
-// getServiceName returns the name of the service at the given index
-func (nm *NotificationManager) getServiceName(index int) string {
-	if index < len(nm.config.Methods) {
-		return nm.config.Methods[index]
+// deliveryError returns an error naming the channels that failed to deliver their notification, or nil if there are none
+func deliveryError(deliveries []notifier.Delivery) error {
+	var failedServices []string
+	for _, delivery := range deliveries {
+		if delivery.Err != nil {
+			failedServices = append(failedServices, delivery.Channel)
+		}
+	}
+	if len(failedServices) == 0 {
+		return nil
 	}
-	return fmt.Sprintf("service_%d", index)
+	log.Printf("Failed to send notifications via: %s", strings.Join(failedServices, ", "))
+	return fmt.Errorf("failed to send notifications via: %s", strings.Join(failedServices, ", "))
 }
 
 // IsEnabled returns whether notifications are enabled
 func (nm *NotificationManager) IsEnabled() bool {
-	return nm.config != nil && nm.config.Enabled && len(nm.services) > 0
+	return nm.config != nil && nm.config.Enabled && len(nm.channels) > 0
 }
@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// messageFuncs are the functions the message templates may call besides the built-in ones of text/template
+var messageFuncs = template.FuncMap{
+	"duration": common.FormatDuration,
+	"join":     strings.Join,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"ofType": func(alerts []notifier.Alert, alertTypes ...string) []notifier.Alert {
+		var matching []notifier.Alert
+		for _, alert := range alerts {
+			if slices.Contains(alertTypes, alert.Type) {
+				matching = append(matching, alert)
+			}
+		}
+		return matching
+	},
+	"lastError": func(endpoint checker.Endpoint) string {
+		if len(endpoint.FailureDetails) == 0 {
+			return ""
+		}
+		return endpoint.FailureDetails[len(endpoint.FailureDetails)-1]
+	},
+}
+
+// messageTemplates holds the parsed templates of the title and the body of the notifications of a channel;
+// a nil template keeps the built-in title or body
+type messageTemplates struct {
+	title, body *template.Template
+}
+
+// parseMessageTemplate parses the text of a message template along with the functions it may call
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(messageFuncs).Parse(text)
+}
+
+// CheckMessageTemplate parses a message template and executes it with sample alerts,
+// so that misspelled fields are found before a real alert is sent
+func CheckMessageTemplate(text string) error {
+	tmpl, err := parseMessageTemplate("message", text)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	return tmpl.Execute(io.Discard, newMessageData("example", "https://status.example.com", sampleAlerts(now), now))
+}
+
+// newMessageTemplates parses the message templates of a channel, taking the shared templates for those it leaves empty.
+// A template that cannot be parsed is logged and replaced by the built-in one.
+func newMessageTemplates(channelName string, own, shared *configure.MessageTemplate) messageTemplates {
+	pick := func(get func(*configure.MessageTemplate) string) string {
+		if own != nil && get(own) != "" {
+			return get(own)
+		}
+		if shared != nil {
+			return get(shared)
+		}
+		return ""
+	}
+	parse := func(part, text string) *template.Template {
+		if text == "" {
+			return nil
+		}
+		tmpl, err := parseMessageTemplate(channelName+" "+part, text)
+		if err != nil {
+			log.Printf("Error parsing the %s template of notification channel %s, using the built-in one: %v", part, channelName, err)
+			return nil
+		}
+		return tmpl
+	}
+	return messageTemplates{
+		title: parse("title", pick(func(m *configure.MessageTemplate) string { return m.Title })),
+		body:  parse("body", pick(func(m *configure.MessageTemplate) string { return m.Body })),
+	}
+}
+
+// newMessageData gathers the data the message templates of a channel are executed with
+func newMessageData(channelName, reportURL string, alerts []notifier.Alert, now time.Time) notifier.MessageData {
+	data := notifier.MessageData{
+		Channel:   channelName,
+		Time:      now.Format(time.RFC3339),
+		ReportURL: reportURL,
+		Alerts:    alerts,
+		Counts:    countAlerts(alerts),
+	}
+	for _, alert := range alerts {
+		i := slices.IndexFunc(data.Services, func(service notifier.ServiceAlerts) bool { return service.Name == alert.Service })
+		if i < 0 {
+			data.Services = append(data.Services, notifier.ServiceAlerts{Name: alert.Service})
+			i = len(data.Services) - 1
+		}
+		data.Services[i].Alerts = append(data.Services[i].Alerts, alert)
+	}
+	return data
+}
+
+// render returns the title and the body of the notification describing data.Alerts.
+// A template that fails to execute is logged and replaced by the built-in one.
+func (t messageTemplates) render(data notifier.MessageData) (string, string) {
+	title := execute(t.title, data)
+	if title == "" {
+		title = generateAlertTitle(data.Alerts)
+	}
+	body := execute(t.body, data)
+	if body == "" {
+		body = generateAlertMessage(data.Alerts, data.ReportURL)
+	}
+	return title, body
+}
+
+// execute executes a message template with data, returning an empty string without a template or on error
+func execute(tmpl *template.Template, data notifier.MessageData) string {
+	if tmpl == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		log.Printf("Error executing the %s template, using the built-in one: %v", tmpl.Name(), err)
+		return ""
+	}
+	return strings.TrimSpace(buf.String())
+}
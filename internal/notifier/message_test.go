@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// webhookPayload decodes the title and the message of a default webhook payload
+func webhookPayload(t *testing.T, body string) (string, string) {
+	t.Helper()
+	var payload struct {
+		Title   string `json:"title"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		t.Fatalf("Expected a JSON payload, got %s: %v", body, err)
+	}
+	return payload.Title, payload.Message
+}
+
+func TestSendAlertsMessageTemplates(t *testing.T) {
+	server := newRecordingServer(t)
+	chat := webhookChannel(server, "chat")
+	chat.Message = &configure.MessageTemplate{Title: "[{{.Channel}}] {{len .Alerts}} alert(s)"}
+	broken := webhookChannel(server, "broken")
+	broken.Message = &configure.MessageTemplate{Body: "{{.Missing}}"}
+	cfg := &configure.NotificationConfig{
+		Enabled:   true,
+		Methods:   []string{"chat", "broken", "plain"},
+		ReportURL: "https://status.example.com",
+		Message: &configure.MessageTemplate{
+			Body: "{{range .Services}}{{.Name}}:{{range .Alerts}} {{lower .Type}} {{lastError .Endpoint}}{{end}}{{end}}",
+		},
+		Channels: []configure.ChannelConfig{chat, broken, webhookChannel(server, "plain")},
+	}
+	alerts := []notifier.Alert{{
+		Type:     notifier.AlertDown,
+		Service:  "API",
+		Endpoint: checker.Endpoint{URL: "https://api.example.com", FailureDetails: []string{"timeout"}},
+	}}
+
+	if _, err := NewNotificationManager(cfg).SendAlerts(alerts, []configure.Service{{Name: "API"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// the channel title overrides the built-in one, the body comes from the shared template
+	title, message := webhookPayload(t, server.received["/chat"][0])
+	if title != "[chat] 1 alert(s)" || message != "API: "+strings.ToLower(notifier.AlertDown)+" timeout" {
+		t.Errorf("Expected the channel title and the shared body, got %q, %q", title, message)
+	}
+
+	// a body failing to execute falls back to the built-in one, including the report link
+	title, message = webhookPayload(t, server.received["/broken"][0])
+	if title != generateAlertTitle(alerts) || message != generateAlertMessage(alerts, cfg.ReportURL) {
+		t.Errorf("Expected the built-in title and body, got %q, %q", title, message)
+	}
+	if !strings.Contains(message, "https://status.example.com") {
+		t.Errorf("Expected the report link in the built-in body, got %q", message)
+	}
+
+	// a channel without its own templates takes the shared ones
+	if _, message = webhookPayload(t, server.received["/plain"][0]); !strings.HasPrefix(message, "API: ") {
+		t.Errorf("Expected the shared body, got %q", message)
+	}
+}
+
+func TestCheckMessageTemplate(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		valid bool
+	}{
+		{"fields", "{{.Channel}} {{.Time}} {{.ReportURL}} {{.Counts.down}}", true},
+		{"alerts", `{{range ofType .Alerts "down" "recovered"}}{{.Service}} {{.Endpoint.URL}} {{duration .Duration}}{{end}}`, true},
+		{"syntax error", "{{range .Alerts}}", false},
+		{"unknown field", "{{.Service}}", false},
+		{"unknown function", "{{shout .Channel}}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckMessageTemplate(tt.text); (err == nil) != tt.valid {
+				t.Errorf("Expected valid=%v, got %v", tt.valid, err)
+			}
+		})
+	}
+}
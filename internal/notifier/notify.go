@@ -7,10 +7,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/wcy-dt/ponghub/internal/common"
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
 	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
-	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
 )
 
 // NotificationService defines the interface for notification services
@@ -18,18 +20,16 @@ type NotificationService interface {
 	Send(title, message string) error
 }
 
-// WriteNotifications sends notifications based on the service check results
-func WriteNotifications(checkResult []checker.Service, certNotifyDays int) {
+// WriteNotifications writes the notification file at notifyPath based on the latest check results of every service.
+// The file is removed when no endpoint has an issue, so that it never describes an outage that is over.
+func WriteNotifications(checkResult []checker.Service, certNotifyDays int, notifyPath string) {
 	statusNoneEndpoints := collectUnavailableEndpoints(checkResult)
 	certProblemEndpoints := collectCertProblemEndpoints(checkResult, certNotifyDays)
 
-	if len(statusNoneEndpoints) == 0 && len(certProblemEndpoints) == 0 {
-		// if no endpoints have issues, do nothing
+	if err := removeExistingNotifyFile(notifyPath); err != nil {
 		return
 	}
-
-	notifyPath := default_config.GetNotifyPath()
-	if err := removeExistingNotifyFile(notifyPath); err != nil {
+	if len(statusNoneEndpoints) == 0 && len(certProblemEndpoints) == 0 {
 		return
 	}
 
@@ -47,91 +47,297 @@ func WriteNotifications(checkResult []checker.Service, certNotifyDays int) {
 	writeNotificationReport(f, statusNoneEndpoints, certProblemEndpoints)
 }
 
-// SendNotifications sends notifications through various channels using the notification manager
-func SendNotifications(checkResult []checker.Service, certNotifyDays int, notificationConfig *configure.NotificationConfig) {
-	statusNoneEndpoints := collectUnavailableEndpoints(checkResult)
-	certProblemEndpoints := collectCertProblemEndpoints(checkResult, certNotifyDays)
+// SendNotifications sends an alert for every endpoint that went down, recovered or whose certificate started expiring
+// since the previous run, instead of repeating the same alert on every run, along with the escalations and reminders
+// of the unacknowledged outages. The state of every endpoint is kept in the file at statePath; endpoints without
+// a state yet take their previous status from logResult. The acknowledgements waiting in the file at acksPath
+// are applied to the outages of the checked services and removed from it.
+func SendNotifications(checkResult []checker.Service, logResult logger.Logger, cfg *configure.Configure, statePath, acksPath string) {
+	alerts, err := updateState(checkResult, logResult, cfg, statePath, acksPath)
+	if err != nil {
+		log.Println("Error locking alert state, skipping notifications until the next check:", err)
+		return
+	}
 
-	if len(statusNoneEndpoints) == 0 && len(certProblemEndpoints) == 0 {
-		log.Println("No service issues found, skipping notifications")
+	if len(alerts) == 0 {
+		log.Println("No endpoint changed state, skipping notifications")
 		return
 	}
 
 	// Create notification manager
-	manager := NewNotificationManager(notificationConfig)
+	manager := NewNotificationManager(cfg.Notifications)
 	if !manager.IsEnabled() {
 		log.Println("Notification manager is not enabled or no services configured")
 		return
 	}
 
-	// Generate notification content
-	title := "🚨 PongHub Service Status Alert"
-	message := generateNotificationMessage(statusNoneEndpoints, certProblemEndpoints)
+	// Send every channel the alerts routed to it
+	if _, err := manager.SendAlerts(alerts, cfg.Services); err != nil {
+		log.Println("Error sending notifications:", err)
+	}
+}
+
+// updateState detects the alerts of checkResult against the alerting state in the file at statePath and saves
+// the new state. The file is locked from loading to saving, so that a run sharing the data directory with the daemon
+// neither loses the changes of the other nor repeats its alerts.
+func updateState(checkResult []checker.Service, logResult logger.Logger, cfg *configure.Configure, statePath, acksPath string) ([]notifier.Alert, error) {
+	unlock, err := common.LockFile(statePath, stateLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	state, err := LoadState(statePath)
+	if err != nil {
+		log.Println("Error loading alert state, using the log instead:", err)
+	}
 
-	// Send notifications
-	manager.SendNotification(title, message)
+	alerts, newState := DetectAlerts(checkResult, state, logResult, cfg)
+	alerts = escalateWithAcks(alerts, checkResult, newState, cfg, acksPath)
+
+	var serviceNames []string
+	for _, service := range cfg.Services {
+		serviceNames = append(serviceNames, service.Name)
+	}
+	PruneState(newState, serviceNames)
+	if err := SaveState(statePath, newState); err != nil {
+		log.Println("Error saving alert state:", err)
+	}
+	return alerts, nil
+}
+
+// SendTestNotification sends sample alerts through every configured channel, or only through the channels
+// called channelNames if any are given, so that the channel settings and message templates can be verified
+// without waiting for a real outage. It returns the outcome of the delivery through each channel.
+func SendTestNotification(notificationConfig *configure.NotificationConfig, channelNames ...string) ([]notifier.Delivery, error) {
+	manager := NewNotificationManager(notificationConfig)
+	if !manager.IsEnabled() {
+		return nil, fmt.Errorf("notifications are disabled or no services configured")
+	}
+
+	now := time.Now()
+	alerts := sampleAlerts(now)
+
+	selected, err := manager.selectChannels(channelNames)
+	if err != nil {
+		return nil, err
+	}
+	var deliveries []notifier.Delivery
+	for _, channel := range selected {
+		title, message := channel.message.render(newMessageData(channel.name, manager.config.ReportURL, alerts, now))
+		if channel.message.title == nil {
+			title = "🧪 PongHub Test Notification"
+		}
+		deliveries = append(deliveries, manager.send(channel, title, message, len(alerts)))
+	}
+	return deliveries, deliveryError(deliveries)
 }
 
-// generateNotificationMessage creates a formatted message for notifications
-func generateNotificationMessage(statusNoneEndpoints, certProblemEndpoints map[string][]checker.Endpoint) string {
+// sampleAlerts returns a down, a recovered and a certificate alert about an example service, as of now
+func sampleAlerts(now time.Time) []notifier.Alert {
+	return []notifier.Alert{
+		{
+			Type:    notifier.AlertDown,
+			Service: "Example Service",
+			Endpoint: checker.Endpoint{
+				URL:            "https://example.com/health",
+				Method:         "GET",
+				StatusCode:     503,
+				Status:         chk_result.NONE,
+				AttemptNum:     3,
+				SuccessNum:     0,
+				FailureDetails: []string{"This is a test notification sent by ponghub notify-test"},
+			},
+			Since: now.Format(time.RFC3339),
+		},
+		{
+			Type:     notifier.AlertRecovered,
+			Service:  "Example Service",
+			Endpoint: checker.Endpoint{URL: "https://example.com/login", Method: "GET", Status: chk_result.ALL},
+			Since:    now.Add(-95 * time.Minute).Format(time.RFC3339),
+			Duration: 95 * time.Minute,
+		},
+		{
+			Type:    notifier.AlertCert,
+			Service: "Example Service",
+			Endpoint: checker.Endpoint{
+				URL:               "https://example.com",
+				Method:            "GET",
+				IsHTTPS:           true,
+				CertRemainingDays: 3,
+			},
+		},
+	}
+}
+
+// generateAlertTitle summarizes the alerts in a single line, such as "🔴 PongHub: 2 endpoint(s) down, 1 recovered"
+func generateAlertTitle(alerts []notifier.Alert) string {
+	counts := countAlerts(alerts)
+	var parts []string
+	addPart := func(alertType, format string) {
+		if counts[alertType] == 0 {
+			return
+		}
+		// only the first part names the endpoints
+		if len(parts) == 0 {
+			format = "%d endpoint(s) " + format
+		} else {
+			format = "%d " + format
+		}
+		parts = append(parts, fmt.Sprintf(format, counts[alertType]))
+	}
+	addPart(notifier.AlertDown, "down")
+	addPart(notifier.AlertEscalated, "escalated")
+	addPart(notifier.AlertReminder, "still down")
+	addPart(notifier.AlertFlapping, "flapping")
+	addPart(notifier.AlertRecovered, "recovered")
+	addPart(notifier.AlertStable, "stable again")
+	if counts[notifier.AlertCert] > 0 {
+		parts = append(parts, fmt.Sprintf("%d certificate issue(s)", counts[notifier.AlertCert]))
+	}
+
+	icon := "🔐"
+	switch {
+	case counts[notifier.AlertDown] > 0 || counts[notifier.AlertEscalated] > 0 || counts[notifier.AlertReminder] > 0:
+		icon = "🔴"
+	case counts[notifier.AlertFlapping] > 0:
+		icon = "🟠"
+	case counts[notifier.AlertRecovered] > 0 || counts[notifier.AlertStable] > 0:
+		icon = "✅"
+	}
+	return fmt.Sprintf("%s PongHub: %s", icon, strings.Join(parts, ", "))
+}
+
+// generateAlertMessage creates a formatted message describing the alerts, grouped by type and service,
+// ending with a link to the report if reportURL is set
+func generateAlertMessage(alerts []notifier.Alert, reportURL string) string {
 	var message strings.Builder
 
 	currentTime := time.Now().Format("2006-01-02 15:04:05")
-	message.WriteString(fmt.Sprintf("Generated at: %s\n\n", currentTime))
-
-	// Add unavailable services section
-	if len(statusNoneEndpoints) > 0 {
-		message.WriteString("🔴 UNAVAILABLE SERVICES:\n")
-		message.WriteString(strings.Repeat("=", 30) + "\n")
-
-		for serviceName, endpoints := range statusNoneEndpoints {
-			message.WriteString(fmt.Sprintf("\n📋 Service: %s\n", serviceName))
-			for _, endpoint := range endpoints {
-				message.WriteString(fmt.Sprintf("  • URL: %s\n", endpoint.URL))
-				message.WriteString(fmt.Sprintf("    Method: %s\n", endpoint.Method))
-				if endpoint.StatusCode > 0 {
-					message.WriteString(fmt.Sprintf("    Status Code: %d\n", endpoint.StatusCode))
-				}
-				message.WriteString(fmt.Sprintf("    Attempts: %d/%d successful\n", endpoint.SuccessNum, endpoint.AttemptNum))
-				if len(endpoint.FailureDetails) > 0 {
-					message.WriteString(fmt.Sprintf("    Last Error: %s\n", endpoint.FailureDetails[len(endpoint.FailureDetails)-1]))
-				}
+	message.WriteString(fmt.Sprintf("Generated at: %s\n", currentTime))
+
+	sections := []struct {
+		alertType string
+		heading   string
+		write     func(alert notifier.Alert)
+	}{
+		{notifier.AlertDown, "🔴 DOWN:", func(alert notifier.Alert) {
+			endpoint := alert.Endpoint
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Method: %s\n", endpoint.Method))
+			if endpoint.StatusCode > 0 {
+				message.WriteString(fmt.Sprintf("    Status Code: %d\n", endpoint.StatusCode))
 			}
-		}
+			message.WriteString(fmt.Sprintf("    Attempts: %d/%d successful\n", endpoint.SuccessNum, endpoint.AttemptNum))
+			if len(endpoint.FailureDetails) > 0 {
+				message.WriteString(fmt.Sprintf("    Last Error: %s\n", endpoint.FailureDetails[len(endpoint.FailureDetails)-1]))
+			}
+			if alert.Failures > 1 {
+				message.WriteString(fmt.Sprintf("    Consecutive Failures: %d\n", alert.Failures))
+			}
+			message.WriteString(fmt.Sprintf("    Down Since: %s\n", alert.Since))
+			if alert.Policy != "" {
+				writeEscalation(&message, alert)
+			}
+		}},
+		{notifier.AlertEscalated, "🚨 ESCALATED:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Down For: %s (since %s)\n", common.FormatDuration(alert.Duration), alert.Since))
+			writeEscalation(&message, alert)
+		}},
+		{notifier.AlertReminder, "⏰ STILL DOWN:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Down For: %s (since %s)\n", common.FormatDuration(alert.Duration), alert.Since))
+			writeEscalation(&message, alert)
+		}},
+		{notifier.AlertFlapping, "🟠 FLAPPING:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Status Changes: %d since %s\n", alert.Changes, alert.Since))
+			message.WriteString(fmt.Sprintf("    Current Status: %s\n", getEndpointState(alert.Endpoint.Status)))
+			message.WriteString("    Further changes are not reported until the endpoint is stable\n")
+		}},
+		{notifier.AlertRecovered, "✅ RECOVERED:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Down For: %s (since %s)\n", common.FormatDuration(alert.Duration), alert.Since))
+		}},
+		{notifier.AlertStable, "✅ STABLE AGAIN:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			message.WriteString(fmt.Sprintf("    Flapped For: %s (since %s)\n", common.FormatDuration(alert.Duration), alert.Since))
+		}},
+		{notifier.AlertCert, "🔐 CERTIFICATE ISSUES:", func(alert notifier.Alert) {
+			message.WriteString(fmt.Sprintf("  • URL: %s\n", alert.Endpoint.URL))
+			if alert.Endpoint.IsCertExpired {
+				message.WriteString("    ❌ Certificate Status: EXPIRED\n")
+			} else {
+				message.WriteString("    ⚠️ Certificate Status: EXPIRES SOON\n")
+			}
+			message.WriteString(fmt.Sprintf("    Days Remaining: %d\n", alert.Endpoint.CertRemainingDays))
+		}},
 	}
 
-	// Add certificate issues section
-	if len(certProblemEndpoints) > 0 {
-		message.WriteString("\n🔐 CERTIFICATE ISSUES:\n")
-		message.WriteString(strings.Repeat("=", 30) + "\n")
-
-		for serviceName, endpoints := range certProblemEndpoints {
-			message.WriteString(fmt.Sprintf("\n📋 Service: %s\n", serviceName))
-			for _, endpoint := range endpoints {
-				message.WriteString(fmt.Sprintf("  • URL: %s\n", endpoint.URL))
-				if endpoint.IsCertExpired {
-					message.WriteString("    ❌ Certificate Status: EXPIRED\n")
-				} else {
-					message.WriteString("    ⚠️ Certificate Status: EXPIRES SOON\n")
-				}
-				message.WriteString(fmt.Sprintf("    Days Remaining: %d\n", endpoint.CertRemainingDays))
+	for _, section := range sections {
+		lastService := ""
+		for _, alert := range alerts {
+			if alert.Type != section.alertType {
+				continue
+			}
+			if lastService == "" {
+				message.WriteString("\n" + section.heading + "\n")
+				message.WriteString(strings.Repeat("=", 30) + "\n")
 			}
+			if alert.Service != lastService {
+				message.WriteString(fmt.Sprintf("\n📋 Service: %s\n", alert.Service))
+				lastService = alert.Service
+			}
+			section.write(alert)
 		}
 	}
 
 	// Add summary
-	unavailableCount := countEndpoints(statusNoneEndpoints)
-	certIssueCount := countEndpoints(certProblemEndpoints)
-
+	counts := countAlerts(alerts)
 	message.WriteString("\n📊 SUMMARY:\n")
 	message.WriteString(strings.Repeat("=", 30) + "\n")
-	message.WriteString(fmt.Sprintf("Unavailable Endpoints: %d\n", unavailableCount))
-	message.WriteString(fmt.Sprintf("Certificate Issues: %d\n", certIssueCount))
-	message.WriteString(fmt.Sprintf("Total Issues: %d\n", unavailableCount+certIssueCount))
+	message.WriteString(fmt.Sprintf("Down Endpoints: %d\n", counts[notifier.AlertDown]))
+	if counts[notifier.AlertEscalated] > 0 {
+		message.WriteString(fmt.Sprintf("Escalated Endpoints: %d\n", counts[notifier.AlertEscalated]))
+	}
+	if counts[notifier.AlertReminder] > 0 {
+		message.WriteString(fmt.Sprintf("Still Down: %d\n", counts[notifier.AlertReminder]))
+	}
+	if counts[notifier.AlertFlapping] > 0 {
+		message.WriteString(fmt.Sprintf("Flapping Endpoints: %d\n", counts[notifier.AlertFlapping]))
+	}
+	message.WriteString(fmt.Sprintf("Recovered Endpoints: %d\n", counts[notifier.AlertRecovered]))
+	if counts[notifier.AlertStable] > 0 {
+		message.WriteString(fmt.Sprintf("Stable Again: %d\n", counts[notifier.AlertStable]))
+	}
+	message.WriteString(fmt.Sprintf("Certificate Issues: %d\n", counts[notifier.AlertCert]))
+	if reportURL != "" {
+		message.WriteString(fmt.Sprintf("\n🔗 Report: %s\n", reportURL))
+	}
 
 	return message.String()
 }
 
+// writeEscalation writes the escalation policy of an alert and how to acknowledge the outage
+func writeEscalation(message *strings.Builder, alert notifier.Alert) {
+	if alert.Step > 0 {
+		message.WriteString(fmt.Sprintf("    Escalation: step %d of policy %s\n", alert.Step, alert.Policy))
+	} else {
+		message.WriteString(fmt.Sprintf("    Escalation: policy %s\n", alert.Policy))
+	}
+	message.WriteString(fmt.Sprintf("    Acknowledge: ponghub ack -service %q -endpoint %s\n", alert.Service, alert.Endpoint.URL))
+}
+
+// countAlerts counts the alerts of each type
+func countAlerts(alerts []notifier.Alert) map[string]int {
+	counts := make(map[string]int)
+	for _, alert := range alerts {
+		counts[alert.Type]++
+	}
+	return counts
+}
+
 // collectUnavailableEndpoints finds all endpoints with status NONE
 func collectUnavailableEndpoints(checkResult []checker.Service) map[string][]checker.Endpoint {
 	statusNoneEndpoints := make(map[string][]checker.Endpoint)
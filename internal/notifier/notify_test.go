@@ -1,6 +1,9 @@
 package notifier
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -8,6 +11,7 @@ import (
 	"time"
 
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
 	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
 )
 
@@ -163,6 +167,23 @@ func TestRemoveExistingNotifyFile(t *testing.T) {
 	}
 }
 
+func TestWriteNotifications(t *testing.T) {
+	notifyPath := filepath.Join(t.TempDir(), "notify.txt")
+	down := []checker.Service{{Name: "API", Endpoints: []checker.Endpoint{{URL: "https://api.example.com", Status: chk_result.NONE}}}}
+	up := []checker.Service{{Name: "API", Endpoints: []checker.Endpoint{{URL: "https://api.example.com", Status: chk_result.ALL}}}}
+
+	WriteNotifications(down, 0, notifyPath)
+	if content, err := os.ReadFile(notifyPath); err != nil || !strings.Contains(string(content), "https://api.example.com") {
+		t.Fatalf("Expected the unavailable endpoint in the notify file, got %q, %v", content, err)
+	}
+
+	// once the outage is over, the file must not describe it any more
+	WriteNotifications(up, 0, notifyPath)
+	if _, err := os.Stat(notifyPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the notify file to be removed without issues, got %v", err)
+	}
+}
+
 func TestWriteToFile(t *testing.T) {
 	tempDir := t.TempDir()
 	testFile := filepath.Join(tempDir, "test_write.txt")
@@ -466,3 +487,39 @@ func TestWriteResponseBody(t *testing.T) {
 		t.Error("Expected no content for empty response body")
 	}
 }
+
+func TestSendTestNotification(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, string(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &configure.NotificationConfig{
+		Enabled: true,
+		Methods: []string{"webhook"},
+		Webhook: &configure.WebhookConfig{URL: server.URL},
+	}
+	if _, err := SendTestNotification(cfg); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(received) != 1 {
+		t.Fatalf("Expected 1 webhook request, got %d", len(received))
+	}
+	if !strings.Contains(received[0], "PongHub Test Notification") {
+		t.Errorf("Expected test notification title in payload, got %s", received[0])
+	}
+
+	// a failing channel must be reported
+	cfg.Webhook.URL = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	if _, err := SendTestNotification(cfg); err == nil {
+		t.Error("Expected an error when the webhook fails, got nil")
+	}
+
+	if _, err := SendTestNotification(&configure.NotificationConfig{Enabled: false}); err == nil {
+		t.Error("Expected an error when notifications are disabled, got nil")
+	}
+}
@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// routeAlerts groups the alerts by the name of the channels they are sent to.
+// An alert goes to the channels of every route it matches, or to the catch-all channels if it matches none,
+// and to the channels it names itself. Escalations only go to the channels they name.
+func (nm *NotificationManager) routeAlerts(alerts []notifier.Alert, services []configure.Service) map[string][]notifier.Alert {
+	servicesByName := make(map[string]configure.Service, len(services))
+	for _, service := range services {
+		servicesByName[service.Name] = service
+	}
+
+	routed := make(map[string][]notifier.Alert)
+	for _, alert := range alerts {
+		var channelNames []string
+		addChannel := func(reference string) {
+			if name, exists := nm.channelName(reference); exists && !slices.Contains(channelNames, name) {
+				channelNames = append(channelNames, name)
+			}
+		}
+		if alert.Type != notifier.AlertEscalated {
+			for _, route := range nm.routes {
+				if matchRoute(route, alert, servicesByName[alert.Service]) {
+					for _, reference := range route.Channels {
+						addChannel(reference)
+					}
+				}
+			}
+			if len(channelNames) == 0 {
+				channelNames = slices.Clone(nm.catchAll)
+			}
+		}
+		for _, reference := range alert.Channels {
+			addChannel(reference)
+		}
+
+		for _, name := range channelNames {
+			routed[name] = append(routed[name], alert)
+		}
+	}
+	return routed
+}
+
+// channelName returns the name of the channel a route refers to; methods are matched regardless of case
+func (nm *NotificationManager) channelName(reference string) (string, bool) {
+	for _, channel := range nm.channels {
+		if channel.name == reference {
+			return channel.name, true
+		}
+	}
+	for _, channel := range nm.channels {
+		if strings.EqualFold(channel.name, reference) {
+			return channel.name, true
+		}
+	}
+	return "", false
+}
+
+// matchRoute reports whether an alert about service meets every condition of route
+func matchRoute(route configure.RouteConfig, alert notifier.Alert, service configure.Service) bool {
+	if len(route.Services) > 0 && !slices.ContainsFunc(route.Services, func(pattern string) bool {
+		matched, err := path.Match(pattern, alert.Service)
+		return err == nil && matched
+	}) {
+		return false
+	}
+	if len(route.Tags) > 0 && !slices.ContainsFunc(route.Tags, func(tag string) bool {
+		return slices.Contains(service.Tags, tag)
+	}) {
+		return false
+	}
+	if len(route.Severities) > 0 && !slices.ContainsFunc(route.Severities, func(severity string) bool {
+		return strings.EqualFold(severity, service.Severity)
+	}) {
+		return false
+	}
+	if len(route.Alerts) > 0 && !slices.ContainsFunc(route.Alerts, func(alertType string) bool {
+		return strings.EqualFold(alertType, alert.Type)
+	}) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+)
+
+// recordingServer collects the bodies of the requests made to each path
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	received map[string][]string
+}
+
+// newRecordingServer starts a server answering every request with 200
+func newRecordingServer(t *testing.T) *recordingServer {
+	server := &recordingServer{received: make(map[string][]string)}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		server.mu.Lock()
+		server.received[r.URL.Path] = append(server.received[r.URL.Path], string(body))
+		server.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// webhookChannel returns a named webhook channel posting to path on server
+func webhookChannel(server *recordingServer, name string) configure.ChannelConfig {
+	return configure.ChannelConfig{Name: name, Type: "webhook", Webhook: &configure.WebhookConfig{URL: server.URL + "/" + name}}
+}
+
+func TestSendAlertsRoutes(t *testing.T) {
+	server := newRecordingServer(t)
+	cfg := &configure.NotificationConfig{
+		Enabled: true,
+		Methods: []string{"ops"},
+		Channels: []configure.ChannelConfig{
+			webhookChannel(server, "ops"),
+			webhookChannel(server, "db-team"),
+			webhookChannel(server, "frontend"),
+			webhookChannel(server, "pager"),
+		},
+		Routes: []configure.RouteConfig{
+			{Services: []string{"db-*"}, Channels: []string{"db-team"}},
+			{Tags: []string{"web"}, Channels: []string{"frontend"}},
+			{Severities: []string{"critical"}, Alerts: []string{notifier.AlertDown}, Channels: []string{"pager"}},
+		},
+	}
+	services := []configure.Service{
+		{Name: "db-main", Severity: "critical"},
+		{Name: "Website", Tags: []string{"web"}, Severity: "warning"},
+		{Name: "Batch", Severity: "info"},
+	}
+	alert := func(alertType, service string) notifier.Alert {
+		return notifier.Alert{Type: alertType, Service: service, Endpoint: checker.Endpoint{URL: "https://" + service + ".example.com"}}
+	}
+	alerts := []notifier.Alert{
+		alert(notifier.AlertDown, "db-main"),
+		alert(notifier.AlertCert, "db-main"),
+		alert(notifier.AlertDown, "Website"),
+		alert(notifier.AlertDown, "Batch"),
+	}
+
+	if _, err := NewNotificationManager(cfg).SendAlerts(alerts, services); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string][]string{
+		"/db-team":  {"db-main.example.com"},
+		"/pager":    {"db-main.example.com"},
+		"/frontend": {"Website.example.com"},
+		"/ops":      {"Batch.example.com"},
+	}
+	unexpected := map[string][]string{
+		"/db-team":  {"Website", "Batch"},
+		"/pager":    {"Website", "Batch", "CERTIFICATE ISSUES"},
+		"/frontend": {"db-main", "Batch"},
+		"/ops":      {"db-main", "Website"},
+	}
+	for path, contents := range expected {
+		if len(server.received[path]) != 1 {
+			t.Errorf("Expected a single notification on %s, got %d", path, len(server.received[path]))
+			continue
+		}
+		for _, content := range contents {
+			if !strings.Contains(server.received[path][0], content) {
+				t.Errorf("Expected the notification on %s to mention %s, got %s", path, content, server.received[path][0])
+			}
+		}
+		for _, content := range unexpected[path] {
+			if strings.Contains(server.received[path][0], content) {
+				t.Errorf("Expected the notification on %s not to mention %s", path, content)
+			}
+		}
+	}
+}
+
+func TestSendAlertsWithoutRoutes(t *testing.T) {
+	server := newRecordingServer(t)
+	cfg := &configure.NotificationConfig{
+		Enabled:  true,
+		Channels: []configure.ChannelConfig{webhookChannel(server, "first"), webhookChannel(server, "second")},
+	}
+	alerts := []notifier.Alert{{Type: notifier.AlertDown, Service: "API", Endpoint: checker.Endpoint{URL: "https://api.example.com"}}}
+
+	if _, err := NewNotificationManager(cfg).SendAlerts(alerts, []configure.Service{{Name: "API"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(server.received["/first"]) != 1 || len(server.received["/second"]) != 1 {
+		t.Errorf("Expected every channel to get the alert without routes, got %v", server.received)
+	}
+}
+
+func TestSendNotificationDeliveries(t *testing.T) {
+	server := newRecordingServer(t)
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	t.Setenv("WEBHOOK_URL_SECOND_HOOK", server.URL+"/from-env")
+	cfg := &configure.NotificationConfig{
+		Enabled: true,
+		Channels: []configure.ChannelConfig{
+			webhookChannel(server, "first"),
+			{Name: "second-hook", Type: "webhook", Webhook: &configure.WebhookConfig{}},
+			{Name: "broken", Type: "webhook", Webhook: &configure.WebhookConfig{URL: failing.URL}},
+		},
+	}
+	manager := NewNotificationManager(cfg)
+
+	deliveries, err := manager.SendNotification("Title", "Message")
+	if err == nil || !strings.Contains(err.Error(), "broken") || strings.Contains(err.Error(), "first") {
+		t.Errorf("Expected an error naming only the broken channel, got %v", err)
+	}
+	if len(deliveries) != 3 {
+		t.Fatalf("Expected 3 deliveries, got %d", len(deliveries))
+	}
+	for i, name := range []string{"first", "second-hook", "broken"} {
+		if deliveries[i].Channel != name || deliveries[i].Type != "webhook" {
+			t.Errorf("Expected delivery %d to be through %s (webhook), got %s (%s)", i, name, deliveries[i].Channel, deliveries[i].Type)
+		}
+		if failed := deliveries[i].Err != nil; failed != (name == "broken") {
+			t.Errorf("Expected the delivery through %s to fail: %v, got error %v", name, name == "broken", deliveries[i].Err)
+		}
+	}
+	if len(server.received["/from-env"]) != 1 {
+		t.Errorf("Expected second-hook to post to the URL of its suffixed variable, got %v", server.received)
+	}
+
+	deliveries, err = manager.SendNotification("Title", "Message", "first")
+	if err != nil || len(deliveries) != 1 || deliveries[0].Channel != "first" {
+		t.Errorf("Expected a single delivery through first, got %v, %v", deliveries, err)
+	}
+	if _, err := manager.SendNotification("Title", "Message", "missing"); err == nil {
+		t.Error("Expected an error for an unknown channel")
+	}
+}
+
+func TestSendAlertsEscalations(t *testing.T) {
+	server := newRecordingServer(t)
+	cfg := &configure.NotificationConfig{
+		Enabled:  true,
+		Channels: []configure.ChannelConfig{webhookChannel(server, "team"), webhookChannel(server, "oncall")},
+		Routes:   []configure.RouteConfig{{Channels: []string{"team"}}},
+	}
+	alerts := []notifier.Alert{{
+		Type:     notifier.AlertEscalated,
+		Service:  "API",
+		Endpoint: checker.Endpoint{URL: "https://api.example.com"},
+		Policy:   "critical",
+		Step:     2,
+		Channels: []string{"oncall"},
+	}}
+
+	if _, err := NewNotificationManager(cfg).SendAlerts(alerts, []configure.Service{{Name: "API"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(server.received["/team"]) != 0 {
+		t.Errorf("Expected the escalation not to be routed, got %v", server.received["/team"])
+	}
+	if len(server.received["/oncall"]) != 1 || !strings.Contains(server.received["/oncall"][0], "step 2 of policy critical") {
+		t.Errorf("Expected the escalation on oncall, got %v", server.received["/oncall"])
+	}
+}
@@ -0,0 +1,271 @@
+package notifier
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// stateLockTimeout is how long a run waits for another process updating the alerting state
+const stateLockTimeout = 10 * time.Second
+
+// LoadState reads the alerting state kept at path; a missing file is an empty state
+func LoadState(path string) (notifier.State, error) {
+	state := make(notifier.State)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return make(notifier.State), fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// SaveState writes the alerting state to path
+func SaveState(path string, state notifier.State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return common.WriteFileAtomic(path, data, 0644)
+}
+
+// alertPolicy defines when the state changes of the endpoints of a service are reported
+type alertPolicy struct {
+	alertAfter      int           // consecutive failed checks before an endpoint is reported down
+	flappingChanges int           // status changes within flappingWindow above which an endpoint is flapping, 0 to disable
+	flappingWindow  time.Duration // period the status changes are counted over
+}
+
+// getAlertPolicies returns the alert policy of every configured service
+func getAlertPolicies(cfg *configure.Configure) map[string]alertPolicy {
+	policies := make(map[string]alertPolicy, len(cfg.Services))
+	for _, service := range cfg.Services {
+		policy := alertPolicy{alertAfter: service.AlertAfter}
+		if service.Flapping != nil {
+			policy.flappingChanges = service.Flapping.Changes
+			policy.flappingWindow = service.Flapping.ParsedWindow
+		}
+		policies[service.Name] = policy
+	}
+	return policies
+}
+
+// DetectAlerts compares the check results with the previous state of every endpoint and returns the alerts
+// for the endpoints whose state changed, along with the new state. Endpoints without a previous state take it
+// from their entries in logResult before this check. Services that were not checked keep their state,
+// as do endpoints that failed during a maintenance window.
+func DetectAlerts(checkResult []checker.Service, state notifier.State, logResult logger.Logger, cfg *configure.Configure) ([]notifier.Alert, notifier.State) {
+	newState := make(notifier.State, len(state))
+	for serviceName, endpoints := range state {
+		newState[serviceName] = endpoints
+	}
+	policies := getAlertPolicies(cfg)
+
+	var alerts []notifier.Alert
+	for _, serviceResult := range checkResult {
+		policy := policies[serviceResult.Name]
+		previousEndpoints := state[serviceResult.Name]
+		currentEndpoints := make(map[string]notifier.EndpointState, len(serviceResult.Endpoints))
+		for _, endpoint := range serviceResult.Endpoints {
+			if _, exists := currentEndpoints[endpoint.Key]; exists {
+				continue
+			}
+
+			previous, exists := previousEndpoints[endpoint.Key]
+			if endpoint.Status == chk_result.MAINTENANCE {
+				// failures during maintenance are expected: the state is kept until the window ends
+				if exists {
+					currentEndpoints[endpoint.Key] = previous
+				}
+				continue
+			}
+			if !exists {
+				previous, exists = stateFromHistory(logResult[serviceResult.Name].Endpoints[endpoint.Key], endpoint.StartTime, policy)
+			}
+
+			current, alert := nextState(previous, exists, getEndpointState(endpoint.Status), endpoint.StartTime, policy)
+			if alert != nil {
+				alert.Service = serviceResult.Name
+				alert.Endpoint = endpoint
+				alerts = append(alerts, *alert)
+			}
+
+			current.CertStatus = getCertStatus(endpoint, cfg.CertNotifyDays)
+			if current.CertStatus != "" && current.CertStatus != previous.CertStatus {
+				alerts = append(alerts, notifier.Alert{Type: notifier.AlertCert, Service: serviceResult.Name, Endpoint: endpoint})
+			}
+
+			currentEndpoints[endpoint.Key] = current
+		}
+		newState[serviceResult.Name] = currentEndpoints
+	}
+	return alerts, newState
+}
+
+// nextState applies a check with the given status to the state of an endpoint and returns the new state,
+// along with the alert to send if the reported state changed. known tells whether previous holds a state at all.
+//
+// An endpoint is reported down after policy.alertAfter consecutive failed checks, and recovered by the next
+// successful check. While it changes status more than policy.flappingChanges times within the flapping window,
+// it is reported once as flapping and its other changes are not reported.
+func nextState(previous notifier.EndpointState, known bool, status, checkTime string, policy alertPolicy) (notifier.EndpointState, *notifier.Alert) {
+	next := previous
+	if !known {
+		next = notifier.EndpointState{Status: notifier.StateUp, Since: checkTime}
+	}
+	next.Changes = append([]string(nil), previous.Changes...)
+
+	// count the status changes within the flapping window
+	if policy.flappingChanges > 0 && known && next.LastStatus != "" && next.LastStatus != status {
+		next.Changes = append(next.Changes, checkTime)
+	}
+	next.Changes = changesWithin(next.Changes, checkTime, policy.flappingWindow)
+	if policy.flappingChanges <= 0 {
+		next.Changes = nil
+	}
+	next.LastStatus = status
+
+	// count the consecutive failed checks
+	if status == notifier.StateDown {
+		if next.Failures == 0 {
+			next.FailingSince = checkTime
+		}
+		next.Failures++
+	} else {
+		next.Failures = 0
+		next.FailingSince = ""
+	}
+
+	var transition *notifier.Alert
+	switch {
+	case next.Status == notifier.StateUp && next.Failures >= max(policy.alertAfter, 1):
+		next.Status = notifier.StateDown
+		next.Since = next.FailingSince
+		transition = &notifier.Alert{Type: notifier.AlertDown, Since: next.Since, Failures: next.Failures}
+	case next.Status == notifier.StateDown && status == notifier.StateUp:
+		transition = &notifier.Alert{Type: notifier.AlertRecovered, Since: next.Since, Duration: elapsed(next.Since, checkTime)}
+		next.Status = notifier.StateUp
+		next.Since = checkTime
+	}
+
+	switch {
+	case !next.Flapping && policy.flappingChanges > 0 && len(next.Changes) > policy.flappingChanges:
+		next.Flapping = true
+		next.FlappingSince = next.Changes[0]
+		return next, &notifier.Alert{Type: notifier.AlertFlapping, Since: next.FlappingSince, Changes: len(next.Changes)}
+	case next.Flapping && len(next.Changes) == 0:
+		// no change for a whole window: report where the endpoint settled
+		flappingSince := next.FlappingSince
+		next.Flapping = false
+		next.FlappingSince = ""
+		if next.Status == notifier.StateDown {
+			return next, &notifier.Alert{Type: notifier.AlertDown, Since: next.Since, Failures: next.Failures}
+		}
+		return next, &notifier.Alert{Type: notifier.AlertStable, Since: flappingSince, Duration: elapsed(flappingSince, checkTime)}
+	case next.Flapping:
+		return next, nil
+	default:
+		return next, transition
+	}
+}
+
+// changesWithin returns the change times that lie within window before checkTime
+func changesWithin(changes []string, checkTime string, window time.Duration) []string {
+	currentTime, err := time.Parse(time.RFC3339, checkTime)
+	if err != nil {
+		return changes
+	}
+
+	var kept []string
+	for _, change := range changes {
+		changeTime, err := time.Parse(time.RFC3339, change)
+		if err != nil || changeTime.Before(currentTime.Add(-window)) {
+			continue
+		}
+		kept = append(kept, change)
+	}
+	return kept
+}
+
+// PruneState drops the state of the services that are no longer configured
+func PruneState(state notifier.State, serviceNames []string) {
+	configured := make(map[string]bool, len(serviceNames))
+	for _, serviceName := range serviceNames {
+		configured[serviceName] = true
+	}
+	for serviceName := range state {
+		if !configured[serviceName] {
+			delete(state, serviceName)
+		}
+	}
+}
+
+// stateFromHistory derives the state of an endpoint by replaying its history entries logged before checkTime.
+// It reports false if there are none.
+func stateFromHistory(history logger.History, checkTime string, policy alertPolicy) (notifier.EndpointState, bool) {
+	currentTime, err := time.Parse(time.RFC3339, checkTime)
+	if err != nil {
+		return notifier.EndpointState{}, false
+	}
+
+	var state notifier.EndpointState
+	found := false
+	for _, entry := range history {
+		entryTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil || !entryTime.Before(currentTime) || chk_result.IsMaintenance(entry.Status) {
+			continue
+		}
+		state, _ = nextState(state, found, getEndpointState(chk_result.ParseCheckResult(entry.Status)), entry.Time, policy)
+		found = true
+	}
+	return state, found
+}
+
+// getEndpointState maps the result of a check to StateUp or StateDown
+func getEndpointState(status chk_result.CheckResult) string {
+	if status == chk_result.NONE {
+		return notifier.StateDown
+	}
+	return notifier.StateUp
+}
+
+// getCertStatus returns whether the certificate of an endpoint has expired or expires within certNotifyDays
+func getCertStatus(endpoint checker.Endpoint, certNotifyDays int) string {
+	switch {
+	case !endpoint.IsHTTPS:
+		return ""
+	case endpoint.IsCertExpired:
+		return notifier.CertExpired
+	case endpoint.CertRemainingDays <= certNotifyDays:
+		return notifier.CertExpiring
+	default:
+		return ""
+	}
+}
+
+// elapsed returns the time between two RFC 3339 timestamps, or 0 if either cannot be parsed
+func elapsed(from, to string) time.Duration {
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return 0
+	}
+	toTime, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return 0
+	}
+	return toTime.Sub(fromTime)
+}
@@ -0,0 +1,279 @@
+package notifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// checkAt returns the result of checking a single endpoint of the API service at the given time
+func checkAt(status chk_result.CheckResult, checkTime string) []checker.Service {
+	return []checker.Service{{
+		Name: "API",
+		Endpoints: []checker.Endpoint{{
+			Key:       "health",
+			URL:       "https://api.example.com/health",
+			Status:    status,
+			StartTime: checkTime,
+		}},
+	}}
+}
+
+// testAlertConfig returns a configuration whose API service uses the given alert settings
+func testAlertConfig(alertAfter int, flapping *configure.FlappingConfig) *configure.Configure {
+	return &configure.Configure{
+		CertNotifyDays: 7,
+		Services:       []configure.Service{{Name: "API", AlertAfter: alertAfter, Flapping: flapping}},
+	}
+}
+
+func TestDetectAlertsTransitions(t *testing.T) {
+	state := make(notifier.State)
+
+	alerts, state := DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T00:00:00Z"), state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert for a new endpoint that is up, got %+v", alerts)
+	}
+
+	alerts, state = DetectAlerts(checkAt(chk_result.NONE, "2025-01-01T00:30:00Z"), state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertDown || alerts[0].Since != "2025-01-01T00:30:00Z" {
+		t.Fatalf("Expected a down alert, got %+v", alerts)
+	}
+
+	alerts, state = DetectAlerts(checkAt(chk_result.NONE, "2025-01-01T01:00:00Z"), state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert while the endpoint stays down, got %+v", alerts)
+	}
+
+	// a partial result still counts as up
+	alerts, state = DetectAlerts(checkAt(chk_result.PART, "2025-01-01T02:00:00Z"), state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertRecovered {
+		t.Fatalf("Expected a recovery alert, got %+v", alerts)
+	}
+	if alerts[0].Duration != 90*time.Minute || alerts[0].Since != "2025-01-01T00:30:00Z" {
+		t.Errorf("Expected an outage of 1h30m since 00:30, got %v since %s", alerts[0].Duration, alerts[0].Since)
+	}
+	if state["API"]["health"].Since != "2025-01-01T02:00:00Z" {
+		t.Errorf("Expected the up state to start at the recovery, got %+v", state["API"]["health"])
+	}
+}
+
+func TestDetectAlertsUsesHistoryWithoutState(t *testing.T) {
+	logResult := logger.Logger{
+		"API": {Endpoints: logger.Endpoints{
+			"health": {
+				{Time: "2025-01-01T00:00:00Z", Status: "all"},
+				{Time: "2025-01-01T00:30:00Z", Status: "none"},
+				{Time: "2025-01-01T01:00:00Z", Status: "none"},
+				// the entry of the current check is ignored
+				{Time: "2025-01-01T01:30:00Z", Status: "none"},
+			},
+		}},
+	}
+
+	alerts, _ := DetectAlerts(checkAt(chk_result.NONE, "2025-01-01T01:30:00Z"), make(notifier.State), logResult, testAlertConfig(1, nil))
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert for an outage that was already logged, got %+v", alerts)
+	}
+
+	alerts, _ = DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T01:30:00Z"), make(notifier.State), logResult, testAlertConfig(1, nil))
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertRecovered || alerts[0].Duration != time.Hour {
+		t.Errorf("Expected a recovery after an outage of 1h, got %+v", alerts)
+	}
+}
+
+func TestDetectAlertsCertificates(t *testing.T) {
+	checkResult := checkAt(chk_result.ALL, "2025-01-01T00:00:00Z")
+	checkResult[0].Endpoints[0].IsHTTPS = true
+	checkResult[0].Endpoints[0].CertRemainingDays = 5
+
+	alerts, state := DetectAlerts(checkResult, make(notifier.State), nil, testAlertConfig(1, nil))
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertCert {
+		t.Fatalf("Expected a certificate alert, got %+v", alerts)
+	}
+	alerts, state = DetectAlerts(checkResult, state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 0 {
+		t.Errorf("Expected the certificate alert not to repeat, got %+v", alerts)
+	}
+
+	checkResult[0].Endpoints[0].IsCertExpired = true
+	alerts, _ = DetectAlerts(checkResult, state, nil, testAlertConfig(1, nil))
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertCert {
+		t.Errorf("Expected a new certificate alert once it expires, got %+v", alerts)
+	}
+}
+
+func TestDetectAlertsAlertAfter(t *testing.T) {
+	cfg := testAlertConfig(3, nil)
+	state := make(notifier.State)
+
+	// a single failure is not reported
+	alerts, state := DetectAlerts(checkAt(chk_result.NONE, "2025-01-01T00:00:00Z"), state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert after one failure, got %+v", alerts)
+	}
+	alerts, state = DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T00:30:00Z"), state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no recovery for an outage that was never reported, got %+v", alerts)
+	}
+
+	var times = []string{"2025-01-01T01:00:00Z", "2025-01-01T01:30:00Z", "2025-01-01T02:00:00Z"}
+	for i, checkTime := range times {
+		alerts, state = DetectAlerts(checkAt(chk_result.NONE, checkTime), state, nil, cfg)
+		if i < 2 && len(alerts) != 0 {
+			t.Errorf("Expected no alert after %d failures, got %+v", i+1, alerts)
+		}
+	}
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertDown || alerts[0].Failures != 3 || alerts[0].Since != times[0] {
+		t.Fatalf("Expected a down alert after 3 failures since the first one, got %+v", alerts)
+	}
+
+	alerts, _ = DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T03:00:00Z"), state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertRecovered || alerts[0].Duration != 2*time.Hour {
+		t.Errorf("Expected a recovery after 2h counted from the first failure, got %+v", alerts)
+	}
+}
+
+func TestDetectAlertsFlapping(t *testing.T) {
+	cfg := testAlertConfig(1, &configure.FlappingConfig{Changes: 3, ParsedWindow: time.Hour})
+	state := make(notifier.State)
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var all []notifier.Alert
+	for i := range 8 {
+		status := chk_result.ALL
+		if i%2 == 1 {
+			status = chk_result.NONE
+		}
+		var alerts []notifier.Alert
+		alerts, state = DetectAlerts(checkAt(status, start.Add(time.Duration(i)*5*time.Minute).Format(time.RFC3339)), state, nil, cfg)
+		all = append(all, alerts...)
+	}
+
+	// down, recovered and down again are reported before the fourth change marks the endpoint as flapping
+	var types []string
+	for _, alert := range all {
+		types = append(types, alert.Type)
+	}
+	if strings.Join(types, ",") != "down,recovered,down,flapping" {
+		t.Fatalf("Expected down, recovered, down and a single flapping alert, got %v", types)
+	}
+
+	// staying up for a whole window ends the flapping
+	alerts, state := DetectAlerts(checkAt(chk_result.ALL, start.Add(40*time.Minute).Format(time.RFC3339)), state, nil, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert while changes remain in the window, got %+v", alerts)
+	}
+	alerts, _ = DetectAlerts(checkAt(chk_result.ALL, start.Add(2*time.Hour).Format(time.RFC3339)), state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertStable {
+		t.Errorf("Expected a stable alert once the window has no changes, got %+v", alerts)
+	}
+}
+
+func TestDetectAlertsMaintenance(t *testing.T) {
+	cfg := testAlertConfig(1, nil)
+	alerts, state := DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T00:00:00Z"), make(notifier.State), nil, cfg)
+
+	// failures during maintenance are not reported and keep the state
+	alerts, state = DetectAlerts(checkAt(chk_result.MAINTENANCE, "2025-01-01T00:30:00Z"), state, nil, cfg)
+	if len(alerts) != 0 || state["API"]["health"].Status != notifier.StateUp {
+		t.Fatalf("Expected no alert and an unchanged state during maintenance, got %+v and %+v", alerts, state)
+	}
+
+	// an endpoint still failing after the window is reported
+	alerts, _ = DetectAlerts(checkAt(chk_result.NONE, "2025-01-01T01:00:00Z"), state, nil, cfg)
+	if len(alerts) != 1 || alerts[0].Type != notifier.AlertDown {
+		t.Errorf("Expected a down alert once the window is over, got %+v", alerts)
+	}
+
+	// history bootstrapping skips maintenance entries as well
+	logResult := logger.Logger{"API": {Endpoints: logger.Endpoints{"health": {
+		{Time: "2025-01-01T00:00:00Z", Status: "all"},
+		{Time: "2025-01-01T00:30:00Z", Status: "maintenance"},
+	}}}}
+	alerts, _ = DetectAlerts(checkAt(chk_result.ALL, "2025-01-01T01:00:00Z"), make(notifier.State), logResult, cfg)
+	if len(alerts) != 0 {
+		t.Errorf("Expected no alert after a maintenance entry in the history, got %+v", alerts)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "alert_state.json")
+	state, err := LoadState(path)
+	if err != nil || len(state) != 0 {
+		t.Fatalf("Expected an empty state for a missing file, got %v, %v", state, err)
+	}
+
+	state = notifier.State{
+		"API": {"health": {Status: notifier.StateDown, Since: "2025-01-01T00:00:00Z"}},
+		"Old": {"gone": {Status: notifier.StateUp, Since: "2025-01-01T00:00:00Z"}},
+	}
+	PruneState(state, []string{"API"})
+	if err := SaveState(path, state); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+	if _, exists := loaded["Old"]; exists {
+		t.Errorf("Expected the state of removed services to be pruned")
+	}
+	if loaded["API"]["health"].Status != notifier.StateDown {
+		t.Errorf("Expected the saved state to be loaded, got %+v", loaded)
+	}
+}
+
+func TestSendNotificationsWaitsForStateLock(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "alert_state.json")
+
+	// another run sharing the data directory is updating the state
+	unlock, err := common.LockFile(path, time.Second)
+	if err != nil {
+		t.Fatalf("Expected the lock to be taken, got %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		SendNotifications(checkAt(chk_result.ALL, "2025-01-01T00:00:00Z"), nil, testAlertConfig(1, nil), path, filepath.Join(dir, "acknowledgements.json"))
+		close(done)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected the state to wait for the lock, got %v", err)
+	}
+	unlock()
+	<-done
+
+	if state, err := LoadState(path); err != nil || state["API"]["health"].Status != notifier.StateUp {
+		t.Errorf("Expected the state to be saved once the lock was released, got %+v, %v", state, err)
+	}
+}
+
+func TestGenerateAlertMessage(t *testing.T) {
+	alerts := []notifier.Alert{
+		{Type: notifier.AlertDown, Service: "API", Endpoint: checker.Endpoint{URL: "https://api.example.com/a"}, Since: "2025-01-01T00:00:00Z"},
+		{Type: notifier.AlertRecovered, Service: "API", Endpoint: checker.Endpoint{URL: "https://api.example.com/b"}, Duration: 26*time.Hour + 5*time.Minute},
+	}
+
+	if title := generateAlertTitle(alerts); title != "🔴 PongHub: 1 endpoint(s) down, 1 recovered" {
+		t.Errorf("Unexpected title %q", title)
+	}
+	message := generateAlertMessage(alerts, "")
+	for _, want := range []string{"🔴 DOWN:", "✅ RECOVERED:", "Down For: 1d 2h 5m", "Recovered Endpoints: 1"} {
+		if !strings.Contains(message, want) {
+			t.Errorf("Expected message to contain %q, got:\n%s", want, message)
+		}
+	}
+}
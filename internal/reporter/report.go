@@ -5,21 +5,36 @@ import (
 	"html/template"
 	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/wcy-dt/ponghub/internal/announcement"
 	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/incident"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	announcementStructure "github.com/wcy-dt/ponghub/internal/types/structures/announcement"
 	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
 	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	incidentStructure "github.com/wcy-dt/ponghub/internal/types/structures/incident"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
 	"github.com/wcy-dt/ponghub/internal/types/structures/reporter"
 	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
-	"github.com/wcy-dt/ponghub/internal/types/types/default_config"
 )
 
-// GetReport generates a report based on the check results and log data
-func GetReport(currentCheckResult []checker.Service, logPath string, cfg *configure.Configure) (reporter.Reporter, error) {
+// maxReportedIncidents is how many of the most recent incidents the report shows
+const maxReportedIncidents = 20
+
+// availabilityWindowDays lists the periods, in days, the long-term availability is reported over
+var availabilityWindowDays = []int{7, 30, 90, 365}
+
+// GetReport generates a report based on the check results, the last max_log_days days of history held by store
+// and the rollups of older checks
+func GetReport(currentCheckResult []checker.Service, store storage.Store, cfg *configure.Configure) (reporter.Reporter, error) {
 	// Load existing log data
-	previousLog, err := common.ReadLogs(logPath)
+	previousLog, err := store.Read(time.Now().AddDate(0, 0, -cfg.MaxLogDays), time.Time{})
 	if err != nil {
-		log.Printf("Error loading log data from %s: %v", logPath, err)
+		log.Printf("Error loading log data: %v", err)
 		return nil, err
 	}
 
@@ -37,6 +52,7 @@ func GetReport(currentCheckResult []checker.Service, logPath string, cfg *config
 
 	// calculate availability
 	reportResult = getAvailability(reportResult)
+	reportResult = getAvailabilityWindows(reportResult, previousLog, time.Now())
 
 	// calculate cert status
 	reportResult = getCertStatus(reportResult, currentCheckResult)
@@ -44,25 +60,103 @@ func GetReport(currentCheckResult []checker.Service, logPath string, cfg *config
 	return reportResult, nil
 }
 
-// getAvailability calculates and updates the availability for each service in the report
+// getAvailability calculates and updates the availability for each service in the report.
+// Checks during maintenance windows are left out.
 func getAvailability(reportResult reporter.Reporter) reporter.Reporter {
 	for i := range reportResult {
-		if len(reportResult[i].ServiceHistory) == 0 {
-			continue
-		}
-		statusAllEntryNum := 0
+		statusAllEntryNum, countedEntryNum := 0, 0
 		for _, entry := range reportResult[i].ServiceHistory {
+			if chk_result.IsMaintenance(entry.Status) {
+				continue
+			}
+			countedEntryNum++
 			if chk_result.IsALL(entry.Status) {
 				statusAllEntryNum++
 			}
 		}
-		availability := float64(statusAllEntryNum) / float64(len(reportResult[i].ServiceHistory))
+		if countedEntryNum == 0 {
+			// nothing but maintenance is not an outage
+			if len(reportResult[i].ServiceHistory) > 0 {
+				reportResult[i].Availability = 1
+			}
+			continue
+		}
+		availability := float64(statusAllEntryNum) / float64(countedEntryNum)
 		reportResult[i].Availability = availability
 	}
 
 	return reportResult
 }
 
+// getAvailabilityWindows calculates the availability of each service over the windows of availabilityWindowDays,
+// leaving out the checks during maintenance windows. A window is only shown once the history reaches past the previous one.
+func getAvailabilityWindows(reportResult reporter.Reporter, logResult logger.Logger, now time.Time) reporter.Reporter {
+	for i := range reportResult {
+		serviceLog, exists := logResult[reportResult[i].Name]
+		if !exists {
+			continue
+		}
+
+		// rollups only count the checks older than the raw history, so nothing is counted twice
+		var oldest, firstRaw time.Time
+		for _, entry := range serviceLog.ServiceHistory {
+			if entryTime, err := time.Parse(time.RFC3339, entry.Time); err == nil && (firstRaw.IsZero() || entryTime.Before(firstRaw)) {
+				firstRaw = entryTime
+			}
+		}
+		var rollups logger.Rollups
+		for _, rollup := range serviceLog.ServiceRollups {
+			start, err := time.Parse(time.RFC3339, rollup.Time)
+			if err != nil || (!firstRaw.IsZero() && !start.Before(firstRaw)) {
+				continue
+			}
+			rollups = append(rollups, rollup)
+			if oldest.IsZero() || start.Before(oldest) {
+				oldest = start
+			}
+		}
+		if oldest.IsZero() {
+			oldest = firstRaw
+		}
+
+		var windows []reporter.AvailabilityWindow
+		for j, days := range availabilityWindowDays {
+			if j > 0 && !oldest.Before(now.AddDate(0, 0, -availabilityWindowDays[j-1])) {
+				break
+			}
+			window := reporter.AvailabilityWindow{Days: days}
+			cutoff := now.AddDate(0, 0, -days)
+			upSum := 0.0
+			for _, entry := range serviceLog.ServiceHistory {
+				entryTime, err := time.Parse(time.RFC3339, entry.Time)
+				if err != nil || entryTime.Before(cutoff) || chk_result.IsMaintenance(entry.Status) {
+					continue
+				}
+				window.Count++
+				if chk_result.IsALL(entry.Status) {
+					upSum++
+				}
+			}
+			for _, rollup := range rollups {
+				start, err := time.Parse(time.RFC3339, rollup.Time)
+				if err != nil || start.Before(cutoff) {
+					continue
+				}
+				window.Count += rollup.Count
+				upSum += rollup.Uptime * float64(rollup.Count)
+			}
+			if window.Count == 0 {
+				continue
+			}
+			window.Availability = upSum / float64(window.Count)
+			windows = append(windows, window)
+		}
+		reportResult[i].AvailabilityWindows = windows
+	}
+
+	return reportResult
+}
+
 // getCertStatus updates the report with certificate status from the current check results
 func getCertStatus(reportResult reporter.Reporter, currentCheckResult []checker.Service) reporter.Reporter {
 	for _, serviceResult := range currentCheckResult {
@@ -72,11 +166,9 @@ func getCertStatus(reportResult reporter.Reporter, currentCheckResult []checker.
 		for i := range reportResult {
 			if reportResult[i].Name == serviceName {
 				for _, endpointResult := range serviceResult.Endpoints {
-					url := endpointResult.URL
-
 					// Find the endpoint in the ordered slice and update it
 					for j := range reportResult[i].Endpoints {
-						if reportResult[i].Endpoints[j].URL == url {
+						if reportResult[i].Endpoints[j].Key == endpointResult.Key {
 							reportResult[i].Endpoints[j].IsHTTPS = endpointResult.IsHTTPS
 							reportResult[i].Endpoints[j].CertRemainingDays = endpointResult.CertRemainingDays
 							reportResult[i].Endpoints[j].IsCertExpired = endpointResult.IsCertExpired
@@ -94,12 +186,13 @@ func getCertStatus(reportResult reporter.Reporter, currentCheckResult []checker.
 	return reportResult
 }
 
-// WriteReport generates an HTML report from the provided log data
-func WriteReport(reportResult reporter.Reporter, reportPath string, displayNum int) error {
+// WriteReport generates an HTML report from the provided log data, the most recent incidents and the announcements
+// still shown on the status page using the template at templatePath
+func WriteReport(reportResult reporter.Reporter, incidents incidentStructure.Incidents, announcements announcementStructure.Announcements, reportPath, templatePath string, displayNum int) error {
 	// Parse the HTML template
-	tmpl, err := template.New("report.html").
+	tmpl, err := template.New(filepath.Base(templatePath)).
 		Funcs(createTemplateFunc()).
-		ParseFiles(default_config.GetTemplatePath())
+		ParseFiles(templatePath)
 	if err != nil {
 		return fmt.Errorf("template parsing failed: %w", err)
 	}
@@ -116,10 +209,14 @@ func WriteReport(reportResult reporter.Reporter, reportPath string, displayNum i
 	}(reportFile)
 
 	// Execute the template with the log data
+	visible := announcement.Visible(announcements, time.Now())
 	if err := tmpl.Execute(reportFile, map[string]any{
-		"ReportResult": reportResult,
-		"UpdateTime":   getLatestTime(reportResult),
-		"DisplayNum":   displayNum,
+		"ReportResult":         reportResult,
+		"UpdateTime":           getLatestTime(reportResult),
+		"DisplayNum":           displayNum,
+		"Incidents":            incidents[:min(len(incidents), maxReportedIncidents)],
+		"Announcements":        visible,
+		"ServiceAnnouncements": announcement.ByService(visible),
 	}); err != nil {
 		return fmt.Errorf("template execution failed: %w", err)
 	}
@@ -156,6 +253,15 @@ func createTemplateFunc() template.FuncMap {
 			}
 			return float64(a) / float64(b)
 		},
+		"markdown": announcement.RenderMarkdown,
+		"newestFirst": func(updates []announcementStructure.Update) []announcementStructure.Update {
+			reversed := slices.Clone(updates)
+			slices.Reverse(reversed)
+			return reversed
+		},
+		"incidentDuration": func(inc incidentStructure.Incident) string {
+			return common.FormatDuration(incident.Duration(inc, time.Now()))
+		},
 		"until": func(n int) []int {
 			result := make([]int, n)
 			for i := range n {
@@ -0,0 +1,298 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/metrics"
+	"github.com/wcy-dt/ponghub/internal/notifier"
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	notifierStructure "github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+	"github.com/wcy-dt/ponghub/internal/types/structures/server"
+)
+
+// shutdownTimeout is how long in-flight requests may take once the server is stopping
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the rendered report, the static assets, a read-only JSON API and Prometheus metrics
+type Server struct {
+	addr       string
+	reportPath string
+	staticDir  string
+	store      storage.Store
+	acksPath   string
+	ackToken   string
+
+	mu          sync.RWMutex
+	checkResult []checker.Service
+	report      reporter.Reporter
+}
+
+// New creates a server listening on addr.
+// History ranges are read from store, which may be nil to serve only the latest report data.
+func New(addr, reportPath, staticDir string, store storage.Store) *Server {
+	return &Server{
+		addr:       addr,
+		reportPath: reportPath,
+		staticDir:  staticDir,
+		store:      store,
+	}
+}
+
+// EnableAcks lets the API acknowledge outages by adding acknowledgements to the file at path,
+// for requests carrying token as a bearer token
+func (s *Server) EnableAcks(path, token string) {
+	s.acksPath = path
+	s.ackToken = token
+}
+
+// Update replaces the check results and report data served by the JSON API and the metrics endpoint
+func (s *Server) Update(checkResult []checker.Service, report reporter.Reporter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkResult = checkResult
+	s.report = report
+}
+
+// getReport returns the report data currently served by the JSON API
+func (s *Server) getReport() reporter.Reporter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
+
+// getCheckResult returns the check results currently served by the metrics endpoint
+func (s *Server) getCheckResult() []checker.Service {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.checkResult
+}
+
+// Handler returns the HTTP handler with all routes registered
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleReport)
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServer(http.Dir(s.staticDir))))
+	mux.HandleFunc("GET /api/services", s.handleServices)
+	mux.HandleFunc("GET /api/services/{name}/history", s.handleServiceHistory)
+	mux.HandleFunc("POST /api/services/{name}/ack", s.handleAck)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	return mux
+}
+
+// ListenAndServe serves HTTP requests until ctx is cancelled
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	httpServer := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Println("HTTP server listening on", s.addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return err
+		}
+		if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		log.Println("HTTP server stopped")
+		return nil
+	}
+}
+
+// handleReport serves the rendered HTML report
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	http.ServeFile(w, r, s.reportPath)
+}
+
+// handleServices lists the current status of every service
+func (s *Server) handleServices(w http.ResponseWriter, _ *http.Request) {
+	report := s.getReport()
+	summaries := make([]server.ServiceSummary, 0, len(report))
+	for _, service := range report {
+		summaries = append(summaries, summarizeService(service))
+	}
+	writeJSON(w, http.StatusOK, summaries)
+}
+
+// handleServiceHistory returns the history of a single service and its endpoints.
+// With a from or to query parameter the full history in that range is read from the store.
+func (s *Server) handleServiceHistory(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if r.URL.Query().Has("from") || r.URL.Query().Has("to") {
+		s.handleServiceHistoryRange(w, r, name)
+		return
+	}
+	for _, service := range s.getReport() {
+		if service.Name == name {
+			writeJSON(w, http.StatusOK, service)
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, server.ErrorResponse{Error: "service not found: " + name})
+}
+
+// handleServiceHistoryRange returns the history of a service recorded between the from and to query parameters
+func (s *Server) handleServiceHistoryRange(w http.ResponseWriter, r *http.Request, name string) {
+	if s.store == nil {
+		writeJSON(w, http.StatusNotImplemented, server.ErrorResponse{Error: "history ranges are not available"})
+		return
+	}
+
+	var from, to time.Time
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := r.URL.Query().Get(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, server.ErrorResponse{Error: "invalid " + param.name + ": expected an RFC 3339 time such as 2025-01-01T00:00:00Z"})
+			return
+		}
+		*param.value = parsed
+	}
+
+	logResult, err := s.store.Read(from, to)
+	if err != nil {
+		log.Println("Error reading history:", err)
+		writeJSON(w, http.StatusInternalServerError, server.ErrorResponse{Error: "failed to read history"})
+		return
+	}
+	serviceLog, exists := logResult[name]
+	if !exists {
+		writeJSON(w, http.StatusNotFound, server.ErrorResponse{Error: "service not found: " + name})
+		return
+	}
+	writeJSON(w, http.StatusOK, serviceLog)
+}
+
+// handleAck acknowledges the outages of a service, or of the endpoint given in the request body by URL or key,
+// stopping their escalation from the next check of the service on
+func (s *Server) handleAck(w http.ResponseWriter, r *http.Request) {
+	if s.acksPath == "" || s.ackToken == "" {
+		writeJSON(w, http.StatusNotImplemented, server.ErrorResponse{Error: "acknowledgements are not available"})
+		return
+	}
+	if !hasBearerToken(r, s.ackToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, server.ErrorResponse{Error: "missing or invalid bearer token"})
+		return
+	}
+
+	var ack notifierStructure.Acknowledgement
+	if err := json.NewDecoder(r.Body).Decode(&ack); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, server.ErrorResponse{Error: "invalid acknowledgement: " + err.Error()})
+		return
+	}
+	ack.Service = r.PathValue("name")
+	ack.Time = time.Now().Format(time.RFC3339)
+
+	index := slices.IndexFunc(s.getReport(), func(service reporter.Service) bool { return service.Name == ack.Service })
+	if index < 0 {
+		writeJSON(w, http.StatusNotFound, server.ErrorResponse{Error: "service not found: " + ack.Service})
+		return
+	}
+	if ack.Endpoint != "" && !slices.ContainsFunc(s.getReport()[index].Endpoints, func(endpoint reporter.Endpoint) bool {
+		return endpoint.URL == ack.Endpoint || endpoint.Key == ack.Endpoint
+	}) {
+		writeJSON(w, http.StatusNotFound, server.ErrorResponse{Error: "endpoint not found: " + ack.Endpoint})
+		return
+	}
+
+	if err := notifier.Acknowledge(s.acksPath, ack); err != nil {
+		log.Println("Error saving acknowledgement:", err)
+		writeJSON(w, http.StatusInternalServerError, server.ErrorResponse{Error: "failed to save acknowledgement"})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, ack)
+}
+
+// hasBearerToken reports whether r carries token as its bearer token
+func hasBearerToken(r *http.Request, token string) bool {
+	given, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// handleMetrics exposes the latest check results in the Prometheus text format
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+	if err := metrics.Write(w, s.getCheckResult(), s.getReport()); err != nil {
+		log.Println("Error writing metrics:", err)
+	}
+}
+
+// summarizeService converts a report service into its API summary
+func summarizeService(service reporter.Service) server.ServiceSummary {
+	summary := server.ServiceSummary{
+		Name:                service.Name,
+		Availability:        service.Availability,
+		AvailabilityWindows: service.AvailabilityWindows,
+		Endpoints:           make([]server.EndpointSummary, 0, len(service.Endpoints)),
+	}
+	if last, ok := lastEntry(service.ServiceHistory); ok {
+		summary.Status = last.Status
+		summary.LastCheck = last.Time
+	}
+
+	for _, endpoint := range service.Endpoints {
+		endpointSummary := server.EndpointSummary{
+			Key:               endpoint.Key,
+			URL:               endpoint.URL,
+			DisplayURL:        endpoint.DisplayURL,
+			IsHTTPS:           endpoint.IsHTTPS,
+			IsCertExpired:     endpoint.IsCertExpired,
+			CertRemainingDays: endpoint.CertRemainingDays,
+		}
+		if last, ok := lastEntry(endpoint.EndpointHistory); ok {
+			endpointSummary.Status = last.Status
+			endpointSummary.ResponseTime = last.ResponseTime
+			endpointSummary.LastCheck = last.Time
+		}
+		summary.Endpoints = append(summary.Endpoints, endpointSummary)
+	}
+
+	return summary
+}
+
+// lastEntry returns the most recent entry of a history sorted by time
+func lastEntry(history reporter.History) (reporter.HistoryEntry, bool) {
+	if len(history) == 0 {
+		return reporter.HistoryEntry{}, false
+	}
+	return history[len(history)-1], true
+}
+
+// writeJSON writes v as a JSON response with the given status code
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Println("Error writing JSON response:", err)
+	}
+}
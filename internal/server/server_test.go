@@ -0,0 +1,186 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wcy-dt/ponghub/internal/storage"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+	"github.com/wcy-dt/ponghub/internal/types/structures/notifier"
+	"github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+	"github.com/wcy-dt/ponghub/internal/types/structures/server"
+)
+
+func newTestServer() *Server {
+	s := New("", "", "", nil)
+	s.Update(nil, reporter.Reporter{
+		{
+			Name: "API",
+			ServiceHistory: reporter.History{
+				{Time: "2025-01-01T00:00:00Z", Status: "none"},
+				{Time: "2025-01-01T00:30:00Z", Status: "all"},
+			},
+			Availability: 0.5,
+			Endpoints: reporter.Endpoints{
+				{
+					URL: "https://api.example.com/health",
+					EndpointHistory: reporter.History{
+						{Time: "2025-01-01T00:30:00Z", Status: "all", ResponseTime: 42},
+					},
+					IsHTTPS:           true,
+					CertRemainingDays: 30,
+				},
+			},
+		},
+	})
+	return s
+}
+
+func TestHandleServices(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestServer().Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var summaries []server.ServiceSummary
+	if err := json.Unmarshal(rec.Body.Bytes(), &summaries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(summaries) != 1 {
+		t.Fatalf("Expected 1 service, got %d", len(summaries))
+	}
+
+	summary := summaries[0]
+	if summary.Status != "all" || summary.LastCheck != "2025-01-01T00:30:00Z" {
+		t.Errorf("Expected latest status all at 00:30, got %s at %s", summary.Status, summary.LastCheck)
+	}
+	if len(summary.Endpoints) != 1 || summary.Endpoints[0].ResponseTime != 42 {
+		t.Errorf("Expected 1 endpoint with a response time of 42ms, got %+v", summary.Endpoints)
+	}
+}
+
+func TestHandleServiceHistory(t *testing.T) {
+	handler := newTestServer().Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/API/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var service reporter.Service
+	if err := json.Unmarshal(rec.Body.Bytes(), &service); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(service.ServiceHistory) != 2 {
+		t.Errorf("Expected 2 history entries, got %d", len(service.ServiceHistory))
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/missing/history", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown service, got %d", rec.Code)
+	}
+}
+
+func TestHandleServiceHistoryRange(t *testing.T) {
+	store := storage.NewJSONStore(filepath.Join(t.TempDir(), "ponghub_log.json"))
+	err := store.Write(logger.Logger{
+		"API": {
+			ServiceHistory: logger.History{
+				{Time: "2025-01-01T00:00:00Z", Status: "NONE"},
+				{Time: "2025-02-01T00:00:00Z", Status: "ALL"},
+			},
+			Endpoints: logger.Endpoints{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to write history: %v", err)
+	}
+	handler := New("", "", "", store).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/API/history?from=2025-01-15T00:00:00Z", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	var service logger.Service
+	if err := json.Unmarshal(rec.Body.Bytes(), &service); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(service.ServiceHistory) != 1 || service.ServiceHistory[0].Status != "ALL" {
+		t.Errorf("Expected only the entry after from, got %+v", service.ServiceHistory)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/services/API/history?to=yesterday", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid time, got %d", rec.Code)
+	}
+}
+
+func TestHandleAck(t *testing.T) {
+	token := "secret"
+	post := func(s *Server, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		s.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := post(newTestServer(), "/api/services/API/ack", ""); rec.Code != http.StatusNotImplemented {
+		t.Errorf("Expected status 501 without an acknowledgements file, got %d", rec.Code)
+	}
+
+	s := newTestServer()
+	acksPath := filepath.Join(t.TempDir(), "acknowledgements.json")
+	s.EnableAcks(acksPath, "secret")
+
+	for _, header := range []string{"", "Bearer wrong", "secret"} {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/services/API/ack", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		s.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for authorization %q, got %d", header, rec.Code)
+		}
+	}
+	if _, err := os.Stat(acksPath); !os.IsNotExist(err) {
+		t.Errorf("Expected no acknowledgement to be saved without the token, got %v", err)
+	}
+
+	rec := post(s, "/api/services/API/ack", `{"endpoint": "https://api.example.com/health", "by": "alice", "time": "2000-01-01T00:00:00Z"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var ack notifier.Acknowledgement
+	if err := json.Unmarshal(rec.Body.Bytes(), &ack); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if ack.Service != "API" || ack.By != "alice" || strings.HasPrefix(ack.Time, "2000") {
+		t.Errorf("Expected an acknowledgement of API by alice dated now, got %+v", ack)
+	}
+	if _, err := os.Stat(acksPath); err != nil {
+		t.Errorf("Expected the acknowledgement to be saved, got %v", err)
+	}
+
+	if rec := post(s, "/api/services/API/ack", "{"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid body, got %d", rec.Code)
+	}
+	if rec := post(s, "/api/services/missing/ack", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown service, got %d", rec.Code)
+	}
+	if rec := post(s, "/api/services/API/ack", `{"endpoint": "https://other.example.com"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown endpoint, got %d", rec.Code)
+	}
+}
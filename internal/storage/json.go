@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// JSONStore keeps the whole history in a single JSON file that is rewritten on every write
+type JSONStore struct {
+	path string
+}
+
+// NewJSONStore creates a store backed by the JSON file at path
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{path: path}
+}
+
+// Read loads the JSON file and returns the history recorded between from and to along with all rollups
+func (s *JSONStore) Read(from, to time.Time) (logger.Logger, error) {
+	logResult, err := common.ReadLogs(s.path)
+	if err != nil {
+		return nil, err
+	}
+	if from.IsZero() && to.IsZero() {
+		return logResult, nil
+	}
+
+	for serviceName, serviceLog := range logResult {
+		serviceLog.ServiceHistory = filterRange(serviceLog.ServiceHistory, from, to)
+		for endpointKey, endpointHistory := range serviceLog.Endpoints {
+			serviceLog.Endpoints[endpointKey] = filterRange(endpointHistory, from, to)
+		}
+		logResult[serviceName] = serviceLog
+	}
+	return logResult, nil
+}
+
+// Write replaces the JSON file with logResult
+func (s *JSONStore) Write(logResult logger.Logger) error {
+	return common.WriteLogs(logResult, s.path)
+}
+
+// PendingRollup returns a zero time: the JSON file is rewritten on every write,
+// so it is read in full to keep the entries that expired since the previous run
+func (s *JSONStore) PendingRollup() (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// MoveEndpoints does nothing: the JSON file is read in full,
+// so the history of renamed endpoints is moved in memory before the file is rewritten
+func (s *JSONStore) MoveEndpoints(_ []logger.EndpointMove) error {
+	return nil
+}
+
+// Lock locks the JSON file
+func (s *JSONStore) Lock() (func(), error) {
+	return common.LockLogs(s.path)
+}
+
+// Close does nothing, as the JSON file is not kept open
+func (s *JSONStore) Close() error {
+	return nil
+}
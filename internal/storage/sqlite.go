@@ -0,0 +1,413 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the table holding one row per history entry.
+// Service history entries are stored with an empty endpoint.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS history (
+	service       TEXT    NOT NULL,
+	endpoint      TEXT    NOT NULL,
+	time          TEXT    NOT NULL,
+	unix          INTEGER NOT NULL,
+	status        TEXT    NOT NULL,
+	response_time INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (service, endpoint, time)
+);
+CREATE INDEX IF NOT EXISTS history_unix ON history (unix);
+CREATE TABLE IF NOT EXISTS rollups (
+	service           TEXT    NOT NULL,
+	endpoint          TEXT    NOT NULL,
+	period            TEXT    NOT NULL,
+	time              TEXT    NOT NULL,
+	count             INTEGER NOT NULL,
+	uptime            REAL    NOT NULL,
+	min_response_time INTEGER NOT NULL DEFAULT 0,
+	avg_response_time INTEGER NOT NULL DEFAULT 0,
+	p95_response_time INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (service, endpoint, period, time)
+);
+`
+
+// SQLiteStore keeps the history in an embedded SQLite database.
+// Writes only insert the new entries, so months of history can be kept cheaply.
+type SQLiteStore struct {
+	db            *sql.DB
+	path          string
+	retentionDays int
+}
+
+// OpenSQLiteStore opens or creates the database at path, keeping retentionDays days of history.
+// A new database is seeded from the JSON log at jsonPath if it exists.
+func OpenSQLiteStore(path string, retentionDays int, jsonPath string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, err
+	}
+	// a single connection serializes the writes of the daemon and the HTTP server
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create the history table in %s: %w", path, err)
+	}
+
+	store := &SQLiteStore{db: db, path: path, retentionDays: retentionDays}
+	if err := store.importJSON(jsonPath); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// importJSON copies the history of the JSON log into an empty database
+func (s *SQLiteStore) importJSON(jsonPath string) error {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM history").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 || jsonPath == "" {
+		return nil
+	}
+	if _, err := os.Stat(jsonPath); err != nil {
+		return nil
+	}
+
+	logResult, err := common.ReadLogs(jsonPath)
+	if err != nil {
+		return fmt.Errorf("failed to import the JSON log %s: %w", jsonPath, err)
+	}
+	if err := s.Write(logResult); err != nil {
+		return fmt.Errorf("failed to import the JSON log %s: %w", jsonPath, err)
+	}
+	log.Printf("Imported the history of %d service(s) from %s", len(logResult), jsonPath)
+	return nil
+}
+
+// Read returns the history recorded between from and to, ordered by time, along with all rollups
+func (s *SQLiteStore) Read(from, to time.Time) (logger.Logger, error) {
+	query := "SELECT service, endpoint, time, status, response_time FROM history WHERE 1 = 1"
+	var args []any
+	if !from.IsZero() {
+		query += " AND unix >= ?"
+		args = append(args, from.Unix())
+	}
+	if !to.IsZero() {
+		query += " AND unix <= ?"
+		args = append(args, to.Unix())
+	}
+	query += " ORDER BY service, endpoint, unix"
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	logResult := make(logger.Logger)
+	for rows.Next() {
+		var serviceName, endpointKey string
+		var entry logger.HistoryEntry
+		if err := rows.Scan(&serviceName, &endpointKey, &entry.Time, &entry.Status, &entry.ResponseTime); err != nil {
+			return nil, err
+		}
+
+		serviceLog, exists := logResult[serviceName]
+		if !exists {
+			serviceLog = logger.Service{Endpoints: make(logger.Endpoints)}
+		}
+		if endpointKey == "" {
+			serviceLog.ServiceHistory = serviceLog.ServiceHistory.AddEntry(entry)
+		} else {
+			serviceLog.Endpoints[endpointKey] = serviceLog.Endpoints[endpointKey].AddEntry(entry)
+		}
+		logResult[serviceName] = serviceLog
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.readRollups(logResult); err != nil {
+		return nil, err
+	}
+	return logResult, nil
+}
+
+// readRollups adds the stored rollups to logResult
+func (s *SQLiteStore) readRollups(logResult logger.Logger) error {
+	rows, err := s.db.Query("SELECT service, endpoint, period, time, count, uptime, min_response_time, avg_response_time, p95_response_time FROM rollups ORDER BY service, endpoint, time")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serviceName, endpointKey string
+		var rollup logger.Rollup
+		if err := rows.Scan(&serviceName, &endpointKey, &rollup.Period, &rollup.Time, &rollup.Count, &rollup.Uptime,
+			&rollup.MinResponseTime, &rollup.AvgResponseTime, &rollup.P95ResponseTime); err != nil {
+			return err
+		}
+
+		serviceLog, exists := logResult[serviceName]
+		if !exists {
+			serviceLog = logger.Service{Endpoints: make(logger.Endpoints)}
+		}
+		if endpointKey == "" {
+			serviceLog.ServiceRollups = append(serviceLog.ServiceRollups, rollup)
+		} else {
+			if serviceLog.EndpointRollups == nil {
+				serviceLog.EndpointRollups = make(map[string]logger.Rollups)
+			}
+			serviceLog.EndpointRollups[endpointKey] = append(serviceLog.EndpointRollups[endpointKey], rollup)
+		}
+		logResult[serviceName] = serviceLog
+	}
+	return rows.Err()
+}
+
+// Write inserts the entries of logResult that are not stored yet, deletes the services and endpoints
+// logResult does not contain and drops the entries older than the retention period.
+// The rollups are replaced by those of logResult.
+func (s *SQLiteStore) Write(logResult logger.Logger) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	insert, err := tx.Prepare("INSERT OR IGNORE INTO history (service, endpoint, time, unix, status, response_time) VALUES (?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	insertHistory := func(serviceName, endpointKey string, history logger.History) error {
+		for _, entry := range history {
+			entryTime, err := time.Parse(time.RFC3339, entry.Time)
+			if err != nil {
+				continue
+			}
+			if _, err := insert.Exec(serviceName, endpointKey, entry.Time, entryTime.Unix(), entry.Status, entry.ResponseTime); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for serviceName, serviceLog := range logResult {
+		if err := insertHistory(serviceName, "", serviceLog.ServiceHistory); err != nil {
+			return err
+		}
+		for endpointKey, endpointHistory := range serviceLog.Endpoints {
+			if err := insertHistory(serviceName, endpointKey, endpointHistory); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := deleteRemoved(tx, logResult); err != nil {
+		return err
+	}
+
+	if err := writeRollups(tx, logResult); err != nil {
+		return err
+	}
+
+	if s.retentionDays > 0 {
+		cutoff := time.Now().AddDate(0, 0, -s.retentionDays).Unix()
+		if _, err := tx.Exec("DELETE FROM history WHERE unix < ?", cutoff); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// writeRollups replaces the stored rollups with those of logResult
+func writeRollups(tx *sql.Tx, logResult logger.Logger) error {
+	if _, err := tx.Exec("DELETE FROM rollups"); err != nil {
+		return err
+	}
+
+	insert, err := tx.Prepare("INSERT OR REPLACE INTO rollups (service, endpoint, period, time, count, uptime, min_response_time, avg_response_time, p95_response_time) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	insertRollups := func(serviceName, endpointKey string, rollups logger.Rollups) error {
+		for _, rollup := range rollups {
+			if _, err := insert.Exec(serviceName, endpointKey, rollup.Period, rollup.Time, rollup.Count, rollup.Uptime,
+				rollup.MinResponseTime, rollup.AvgResponseTime, rollup.P95ResponseTime); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for serviceName, serviceLog := range logResult {
+		if err := insertRollups(serviceName, "", serviceLog.ServiceRollups); err != nil {
+			return err
+		}
+		for endpointKey, endpointRollups := range serviceLog.EndpointRollups {
+			if err := insertRollups(serviceName, endpointKey, endpointRollups); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deleteRemoved deletes the history of the services and endpoints that logResult does not contain
+func deleteRemoved(tx *sql.Tx, logResult logger.Logger) error {
+	rows, err := tx.Query("SELECT DISTINCT service, endpoint FROM history")
+	if err != nil {
+		return err
+	}
+	var removed [][2]string
+	for rows.Next() {
+		var serviceName, endpointKey string
+		if err := rows.Scan(&serviceName, &endpointKey); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		serviceLog, exists := logResult[serviceName]
+		if !exists {
+			removed = append(removed, [2]string{serviceName, endpointKey})
+			continue
+		}
+		if _, exists := serviceLog.Endpoints[endpointKey]; endpointKey != "" && !exists {
+			removed = append(removed, [2]string{serviceName, endpointKey})
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return err
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, key := range removed {
+		if _, err := tx.Exec("DELETE FROM history WHERE service = ? AND endpoint = ?", key[0], key[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingRollup returns the time of the oldest row recorded after the end of the last rollup of its service or endpoint,
+// or now if every row is covered
+func (s *SQLiteStore) PendingRollup() (time.Time, error) {
+	rows, err := s.db.Query("SELECT service, endpoint, period, MAX(time) FROM rollups GROUP BY service, endpoint")
+	if err != nil {
+		return time.Time{}, err
+	}
+	rolledUpTo := make(map[[2]string]int64)
+	for rows.Next() {
+		var serviceName, endpointKey, period, start string
+		if err := rows.Scan(&serviceName, &endpointKey, &period, &start); err != nil {
+			_ = rows.Close()
+			return time.Time{}, err
+		}
+		startTime, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			continue
+		}
+		length := time.Hour
+		if period == logger.PeriodDay {
+			length = 24 * time.Hour
+		}
+		rolledUpTo[[2]string{serviceName, endpointKey}] = startTime.Add(length).Unix()
+	}
+	if err := rows.Close(); err != nil {
+		return time.Time{}, err
+	}
+	if err := rows.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	keys, err := s.db.Query("SELECT DISTINCT service, endpoint FROM history")
+	if err != nil {
+		return time.Time{}, err
+	}
+	var pairs [][2]string
+	for keys.Next() {
+		var pair [2]string
+		if err := keys.Scan(&pair[0], &pair[1]); err != nil {
+			_ = keys.Close()
+			return time.Time{}, err
+		}
+		pairs = append(pairs, pair)
+	}
+	if err := keys.Close(); err != nil {
+		return time.Time{}, err
+	}
+	if err := keys.Err(); err != nil {
+		return time.Time{}, err
+	}
+
+	pending := time.Now()
+	for _, pair := range pairs {
+		var oldest sql.NullInt64
+		if err := s.db.QueryRow("SELECT MIN(unix) FROM history WHERE service = ? AND endpoint = ? AND unix >= ?",
+			pair[0], pair[1], rolledUpTo[pair]).Scan(&oldest); err != nil {
+			return time.Time{}, err
+		}
+		if oldest.Valid && time.Unix(oldest.Int64, 0).Before(pending) {
+			pending = time.Unix(oldest.Int64, 0)
+		}
+	}
+	return pending, nil
+}
+
+// MoveEndpoints moves the rows of the previous key of each endpoint to its current key, including those older
+// than the range read before a write. The rows whose time is already stored under the current key are dropped.
+func (s *SQLiteStore) MoveEndpoints(moves []logger.EndpointMove) error {
+	if len(moves) == 0 {
+		return nil
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, move := range moves {
+		result, err := tx.Exec("UPDATE OR IGNORE history SET endpoint = ? WHERE service = ? AND endpoint = ?", move.To, move.Service, move.From)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("UPDATE OR IGNORE rollups SET endpoint = ? WHERE service = ? AND endpoint = ?", move.To, move.Service, move.From); err != nil {
+			return err
+		}
+		for _, table := range []string{"history", "rollups"} {
+			if _, err := tx.Exec("DELETE FROM "+table+" WHERE service = ? AND endpoint = ?", move.Service, move.From); err != nil {
+				return err
+			}
+		}
+		if moved, err := result.RowsAffected(); err == nil && moved > 0 {
+			log.Printf("Migrated %d entries of endpoint %s in service %s to %s", moved, move.From, move.Service, move.To)
+		}
+	}
+	return tx.Commit()
+}
+
+// Lock locks the database, whose rollups are replaced on every write
+func (s *SQLiteStore) Lock() (func(), error) {
+	return common.LockLogs(s.path)
+}
+
+// Close closes the database
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// Store persists the check history of every service and endpoint
+type Store interface {
+	// Read returns the history recorded between from and to along with all rollups;
+	// a zero time leaves that end of the range open
+	Read(from, to time.Time) (logger.Logger, error)
+	// Write saves logResult as the current history, dropping the services and endpoints it does not contain
+	Write(logResult logger.Logger) error
+	// PendingRollup returns the time of the oldest entry that is not covered by a rollup of its service or endpoint yet,
+	// from which the history is read before merging check results; a zero time stands for the whole history
+	PendingRollup() (time.Time, error)
+	// MoveEndpoints moves the history and rollups stored under the previous key of each endpoint to its current key,
+	// keeping the entries already stored under the current key
+	MoveEndpoints(moves []logger.EndpointMove) error
+	// Lock keeps other processes from updating the history until the returned function is called;
+	// it is held from a Read to the Write of the merged history
+	Lock() (func(), error)
+	// Close releases the resources held by the store
+	Close() error
+}
+
+// Open opens the store selected by the storage configuration
+func Open(cfg *configure.StorageConfig, paths configure.Paths) (Store, error) {
+	storageType, retentionDays := "json", 0
+	if cfg != nil {
+		storageType, retentionDays = strings.ToLower(cfg.Type), cfg.RetentionDays
+	}
+
+	switch storageType {
+	case "", "json":
+		return NewJSONStore(paths.Log), nil
+	case "sqlite":
+		return OpenSQLiteStore(paths.Database, retentionDays, paths.Log)
+	default:
+		return nil, fmt.Errorf("unknown storage type %q", storageType)
+	}
+}
+
+// filterRange returns the entries of history recorded between from and to
+func filterRange(history logger.History, from, to time.Time) logger.History {
+	if from.IsZero() && to.IsZero() {
+		return history
+	}
+
+	var filtered logger.History
+	for _, entry := range history {
+		entryTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil {
+			continue
+		}
+		if inRange(entryTime, from, to) {
+			filtered = append(filtered, entry)
+		}
+	}
+	return filtered
+}
+
+// inRange reports whether t lies between from and to, where a zero time is unbounded
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
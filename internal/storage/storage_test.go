@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/common"
+	"github.com/wcy-dt/ponghub/internal/types/structures/logger"
+)
+
+// sampleLog returns a log with two service entries a day apart and one endpoint
+func sampleLog(now time.Time) logger.Logger {
+	older := now.Add(-24 * time.Hour).Format(time.RFC3339)
+	newer := now.Format(time.RFC3339)
+	return logger.Logger{
+		"API": {
+			ServiceHistory: logger.History{{Time: older, Status: "NONE"}, {Time: newer, Status: "ALL"}},
+			Endpoints: logger.Endpoints{
+				"https://api.example.com/health": {{Time: older, Status: "NONE"}, {Time: newer, Status: "ALL", ResponseTime: 42}},
+			},
+		},
+	}
+}
+
+// testStores returns a JSON and a SQLite store in a temporary directory
+func testStores(t *testing.T) map[string]Store {
+	t.Helper()
+	dir := t.TempDir()
+	sqliteStore, err := OpenSQLiteStore(filepath.Join(dir, "ponghub.db"), 90, "")
+	if err != nil {
+		t.Fatalf("Failed to open SQLite store: %v", err)
+	}
+	t.Cleanup(func() { _ = sqliteStore.Close() })
+	return map[string]Store{
+		"json":   NewJSONStore(filepath.Join(dir, "ponghub_log.json")),
+		"sqlite": sqliteStore,
+	}
+}
+
+func TestStoreReadRange(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	for name, store := range testStores(t) {
+		if err := store.Write(sampleLog(now)); err != nil {
+			t.Fatalf("%s: expected no error writing, got %v", name, err)
+		}
+
+		all, err := store.Read(time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("%s: expected no error reading, got %v", name, err)
+		}
+		if len(all["API"].ServiceHistory) != 2 || len(all["API"].Endpoints["https://api.example.com/health"]) != 2 {
+			t.Errorf("%s: expected the full history, got %+v", name, all)
+		}
+
+		recent, err := store.Read(now.Add(-time.Hour), time.Time{})
+		if err != nil {
+			t.Fatalf("%s: expected no error reading, got %v", name, err)
+		}
+		endpointHistory := recent["API"].Endpoints["https://api.example.com/health"]
+		if len(endpointHistory) != 1 || endpointHistory[0].ResponseTime != 42 {
+			t.Errorf("%s: expected only the latest entry, got %+v", name, endpointHistory)
+		}
+	}
+}
+
+func TestStoreWriteDropsRemovedEndpoints(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	for name, store := range testStores(t) {
+		if err := store.Write(sampleLog(now)); err != nil {
+			t.Fatalf("%s: expected no error writing, got %v", name, err)
+		}
+
+		renamed := sampleLog(now)
+		service := renamed["API"]
+		service.Endpoints = logger.Endpoints{"health": service.Endpoints["https://api.example.com/health"]}
+		renamed["API"] = service
+		if err := store.Write(renamed); err != nil {
+			t.Fatalf("%s: expected no error writing, got %v", name, err)
+		}
+
+		logResult, err := store.Read(time.Time{}, time.Time{})
+		if err != nil {
+			t.Fatalf("%s: expected no error reading, got %v", name, err)
+		}
+		if len(logResult["API"].Endpoints) != 1 || len(logResult["API"].Endpoints["health"]) != 2 {
+			t.Errorf("%s: expected only the renamed endpoint to remain, got %+v", name, logResult["API"].Endpoints)
+		}
+	}
+}
+
+func TestSQLiteStoreImportsJSONLog(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "ponghub_log.json")
+	if err := common.WriteLogs(sampleLog(time.Now()), jsonPath); err != nil {
+		t.Fatalf("Failed to write JSON log: %v", err)
+	}
+
+	store, err := OpenSQLiteStore(filepath.Join(dir, "ponghub.db"), 90, jsonPath)
+	if err != nil {
+		t.Fatalf("Expected no error opening the store, got %v", err)
+	}
+	defer store.Close()
+
+	logResult, err := store.Read(time.Time{}, time.Time{})
+	if err != nil {
+		t.Fatalf("Expected no error reading, got %v", err)
+	}
+	if len(logResult["API"].ServiceHistory) != 2 {
+		t.Errorf("Expected the JSON history to be imported, got %+v", logResult)
+	}
+}
+
+func TestStoreKeepsRollups(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	for name, store := range testStores(t) {
+		logResult := sampleLog(now)
+		serviceLog := logResult["API"]
+		serviceLog.ServiceRollups = logger.Rollups{{Time: "2025-01-01T00:00:00Z", Period: logger.PeriodDay, Count: 288, Uptime: 0.99, AvgResponseTime: 80}}
+		serviceLog.EndpointRollups = map[string]logger.Rollups{
+			"https://api.example.com/health": {{Time: "2025-01-01T00:00:00Z", Period: logger.PeriodDay, Count: 288, Uptime: 0.99, P95ResponseTime: 120}},
+		}
+		logResult["API"] = serviceLog
+		if err := store.Write(logResult); err != nil {
+			t.Fatalf("%s: expected no error writing, got %v", name, err)
+		}
+
+		// rollups are returned whatever the range of raw entries
+		recent, err := store.Read(now.Add(-time.Hour), time.Time{})
+		if err != nil {
+			t.Fatalf("%s: expected no error reading, got %v", name, err)
+		}
+		if rollups := recent["API"].ServiceRollups; len(rollups) != 1 || rollups[0].Count != 288 || rollups[0].Uptime != 0.99 {
+			t.Errorf("%s: expected the service rollup to be kept, got %+v", name, rollups)
+		}
+		if rollups := recent["API"].EndpointRollups["https://api.example.com/health"]; len(rollups) != 1 || rollups[0].P95ResponseTime != 120 {
+			t.Errorf("%s: expected the endpoint rollup to be kept, got %+v", name, rollups)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package telemetry
+
+import (
+	"sort"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// durationBounds are the histogram bucket boundaries in seconds
+var durationBounds = []float64{0.005, 0.01, 0.025, 0.05, 0.075, 0.1, 0.25, 0.5, 0.75, 1, 2.5, 5, 7.5, 10}
+
+// buildMetrics converts check results into OTLP metric data.
+// Request durations of this run are reported as delta histograms, one data point per endpoint.
+// MetricsData has the same wire format as ExportMetricsServiceRequest.
+func buildMetrics(checkResult []checker.Service, serviceName string, now time.Time) *metricspb.MetricsData {
+	var durationPoints []*metricspb.HistogramDataPoint
+	var upPoints []*metricspb.NumberDataPoint
+
+	for _, serviceResult := range checkResult {
+		for _, endpoint := range serviceResult.Endpoints {
+			attributes := []*commonpb.KeyValue{
+				stringAttribute("ponghub.service", serviceResult.Name),
+				stringAttribute("url.full", endpoint.URL),
+				stringAttribute("http.request.method", endpoint.Method),
+			}
+
+			if point := buildDurationPoint(endpoint.Attempts, attributes, now); point != nil {
+				durationPoints = append(durationPoints, point)
+			}
+
+			up := 0.0
+			if endpoint.Status != chk_result.NONE {
+				up = 1
+			}
+			upPoints = append(upPoints, &metricspb.NumberDataPoint{
+				Attributes:   attributes,
+				TimeUnixNano: unixNano(now),
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: up},
+			})
+		}
+	}
+
+	return &metricspb.MetricsData{
+		ResourceMetrics: []*metricspb.ResourceMetrics{
+			{
+				Resource: buildResource(serviceName),
+				ScopeMetrics: []*metricspb.ScopeMetrics{
+					{
+						Scope: &commonpb.InstrumentationScope{Name: scopeName},
+						Metrics: []*metricspb.Metric{
+							{
+								Name:        "ponghub.check.duration",
+								Description: "Duration of the requests made to check an endpoint.",
+								Unit:        "s",
+								Data: &metricspb.Metric_Histogram{
+									Histogram: &metricspb.Histogram{
+										DataPoints:             durationPoints,
+										AggregationTemporality: metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA,
+									},
+								},
+							},
+							{
+								Name:        "ponghub.endpoint.up",
+								Description: "Whether the last check of the endpoint succeeded (1) or not (0).",
+								Unit:        "1",
+								Data: &metricspb.Metric_Gauge{
+									Gauge: &metricspb.Gauge{DataPoints: upPoints},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildDurationPoint aggregates the durations of the given attempts into a histogram data point
+func buildDurationPoint(attempts []checker.Attempt, attributes []*commonpb.KeyValue, now time.Time) *metricspb.HistogramDataPoint {
+	if len(attempts) == 0 {
+		return nil
+	}
+
+	bucketCounts := make([]uint64, len(durationBounds)+1)
+	sum := 0.0
+	minDuration, maxDuration := attempts[0].Duration.Seconds(), attempts[0].Duration.Seconds()
+	startTime := attempts[0].StartTime
+
+	for _, attempt := range attempts {
+		seconds := attempt.Duration.Seconds()
+		sum += seconds
+		minDuration = min(minDuration, seconds)
+		maxDuration = max(maxDuration, seconds)
+		if attempt.StartTime.Before(startTime) {
+			startTime = attempt.StartTime
+		}
+
+		// buckets are upper-inclusive, values above the last bound go to the overflow bucket
+		bucketCounts[sort.SearchFloat64s(durationBounds, seconds)]++
+	}
+
+	return &metricspb.HistogramDataPoint{
+		Attributes:        attributes,
+		StartTimeUnixNano: unixNano(startTime),
+		TimeUnixNano:      unixNano(now),
+		Count:             uint64(len(attempts)),
+		Sum:               &sum,
+		BucketCounts:      bucketCounts,
+		ExplicitBounds:    durationBounds,
+		Min:               &minDuration,
+		Max:               &maxDuration,
+	}
+}
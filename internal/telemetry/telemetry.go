@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+)
+
+const (
+	// defaultEndpoint is the default OTLP/HTTP collector address
+	defaultEndpoint = "http://localhost:4318"
+
+	// defaultServiceName is the default service.name resource attribute
+	defaultServiceName = "ponghub"
+
+	// defaultTimeout is the default timeout for export requests in seconds
+	defaultTimeout = 10
+
+	// scopeName is the instrumentation scope reported with every span and metric
+	scopeName = "github.com/wcy-dt/ponghub"
+)
+
+// Exporter sends check results to an OpenTelemetry collector over OTLP/HTTP with protobuf encoding
+type Exporter struct {
+	config *configure.TelemetryConfig
+	client *http.Client
+}
+
+// NewExporter creates a new OTLP exporter, or returns nil if telemetry is disabled
+func NewExporter(config *configure.TelemetryConfig) *Exporter {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	timeout := defaultTimeout
+	if config.Timeout > 0 {
+		timeout = config.Timeout
+	}
+
+	return &Exporter{
+		config: config,
+		client: &http.Client{Timeout: time.Duration(timeout) * time.Second},
+	}
+}
+
+// Export sends every endpoint check as a trace and the response times as histograms
+func (e *Exporter) Export(checkResult []checker.Service) error {
+	if e == nil || len(checkResult) == 0 {
+		return nil
+	}
+
+	serviceName := e.config.ServiceName
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+
+	if err := e.send("/v1/traces", buildTraces(checkResult, serviceName)); err != nil {
+		return fmt.Errorf("failed to export traces: %w", err)
+	}
+	if err := e.send("/v1/metrics", buildMetrics(checkResult, serviceName, time.Now())); err != nil {
+		return fmt.Errorf("failed to export metrics: %w", err)
+	}
+	return nil
+}
+
+// send posts a protobuf message to the given OTLP signal path of the collector
+func (e *Exporter) send(signalPath string, message proto.Message) error {
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, e.getEndpoint()+signalPath, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector responded with status %d: %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// getEndpoint returns the collector base URL without a trailing slash
+func (e *Exporter) getEndpoint() string {
+	endpoint := e.config.Endpoint
+	if endpoint == "" {
+		endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	return strings.TrimSuffix(endpoint, "/")
+}
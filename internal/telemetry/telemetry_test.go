@@ -0,0 +1,160 @@
+package telemetry
+
+import (
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/structures/configure"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// receiverStub is a minimal OTLP/HTTP receiver that records the request bodies per signal path
+type receiverStub struct {
+	mu       sync.Mutex
+	bodies   map[string][]byte
+	headers  map[string]http.Header
+	response int
+}
+
+func (r *receiverStub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	r.bodies[req.URL.Path] = body
+	r.headers[req.URL.Path] = req.Header.Clone()
+	r.mu.Unlock()
+	w.WriteHeader(r.response)
+}
+
+func newTestCheckResult() []checker.Service {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	return []checker.Service{
+		{
+			Name:   "API",
+			Status: chk_result.PART,
+			Endpoints: []checker.Endpoint{
+				{
+					URL:            "https://api.example.com/health",
+					Method:         "GET",
+					Status:         chk_result.PART,
+					StatusCode:     200,
+					AttemptNum:     2,
+					SuccessNum:     1,
+					FailureDetails: []string{"StatusCode or ResponseRegex mismatch: 503"},
+					TraceID:        "0102030405060708090a0b0c0d0e0f10",
+					SpanID:         "1111111111111111",
+					Attempts: []checker.Attempt{
+						{SpanID: "2222222222222222", StartTime: start, Duration: 30 * time.Millisecond, StatusCode: 503, Error: "StatusCode or ResponseRegex mismatch: 503"},
+						{SpanID: "3333333333333333", StartTime: start.Add(time.Second), Duration: 2 * time.Second, StatusCode: 200, IsSuccessful: true},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestExport(t *testing.T) {
+	stub := &receiverStub{bodies: make(map[string][]byte), headers: make(map[string]http.Header), response: http.StatusOK}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	exporter := NewExporter(&configure.TelemetryConfig{
+		Enabled:  true,
+		Endpoint: srv.URL + "/",
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+	})
+	if err := exporter.Export(newTestCheckResult()); err != nil {
+		t.Fatalf("Export() returned error: %v", err)
+	}
+
+	// traces
+	if got := stub.headers["/v1/traces"].Get("Content-Type"); got != "application/x-protobuf" {
+		t.Errorf("Expected protobuf content type, got %q", got)
+	}
+	if got := stub.headers["/v1/traces"].Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Expected custom header to be sent, got %q", got)
+	}
+	var traces tracepb.TracesData
+	if err := proto.Unmarshal(stub.bodies["/v1/traces"], &traces); err != nil {
+		t.Fatalf("Failed to decode traces: %v", err)
+	}
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("Expected 1 check span and 2 attempt spans, got %d", len(spans))
+	}
+	if hex.EncodeToString(spans[0].TraceId) != "0102030405060708090a0b0c0d0e0f10" {
+		t.Errorf("Expected the check span to use the endpoint trace ID, got %x", spans[0].TraceId)
+	}
+	for _, span := range spans[1:] {
+		if hex.EncodeToString(span.ParentSpanId) != "1111111111111111" {
+			t.Errorf("Expected attempt span to be a child of the check span, got parent %x", span.ParentSpanId)
+		}
+	}
+	if spans[1].Status.Code != tracepb.Status_STATUS_CODE_ERROR || spans[2].Status.Code != tracepb.Status_STATUS_CODE_OK {
+		t.Errorf("Expected failed then successful attempt, got %v and %v", spans[1].Status.Code, spans[2].Status.Code)
+	}
+	if attr := findAttribute(spans[1], "ponghub.attempt"); attr == nil || attr.GetIntValue() != 1 {
+		t.Errorf("Expected first attempt span to carry attempt number 1, got %v", attr)
+	}
+	if attr := findAttribute(spans[2], "http.response.status_code"); attr == nil || attr.GetIntValue() != 200 {
+		t.Errorf("Expected second attempt span to carry status code 200, got %v", attr)
+	}
+
+	// metrics
+	var metrics metricspb.MetricsData
+	if err := proto.Unmarshal(stub.bodies["/v1/metrics"], &metrics); err != nil {
+		t.Fatalf("Failed to decode metrics: %v", err)
+	}
+	histogram := metrics.ResourceMetrics[0].ScopeMetrics[0].Metrics[0].GetHistogram()
+	if histogram == nil || len(histogram.DataPoints) != 1 {
+		t.Fatalf("Expected one histogram data point, got %v", histogram)
+	}
+	point := histogram.DataPoints[0]
+	if point.Count != 2 {
+		t.Errorf("Expected 2 observations, got %d", point.Count)
+	}
+	// 30ms falls into the (0.025, 0.05] bucket and 2s into the (1, 2.5] bucket
+	if point.BucketCounts[3] != 1 || point.BucketCounts[10] != 1 {
+		t.Errorf("Unexpected bucket counts: %v", point.BucketCounts)
+	}
+}
+
+func TestExport_CollectorError(t *testing.T) {
+	stub := &receiverStub{bodies: make(map[string][]byte), headers: make(map[string]http.Header), response: http.StatusBadRequest}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	exporter := NewExporter(&configure.TelemetryConfig{Enabled: true, Endpoint: srv.URL})
+	if err := exporter.Export(newTestCheckResult()); err == nil {
+		t.Error("Expected an error when the collector rejects the request")
+	}
+}
+
+func TestNewExporter_Disabled(t *testing.T) {
+	exporter := NewExporter(&configure.TelemetryConfig{Enabled: false})
+	if exporter != nil {
+		t.Fatal("Expected no exporter when telemetry is disabled")
+	}
+	if err := exporter.Export(newTestCheckResult()); err != nil {
+		t.Errorf("Export() on a nil exporter should be a no-op, got %v", err)
+	}
+}
+
+func findAttribute(span *tracepb.Span, key string) *commonpb.AnyValue {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package telemetry
+
+import (
+	"encoding/hex"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// buildTraces converts check results into OTLP trace data.
+// Each endpoint check becomes a span, with one child span per request attempt.
+// TracesData has the same wire format as ExportTraceServiceRequest.
+func buildTraces(checkResult []checker.Service, serviceName string) *tracepb.TracesData {
+	var spans []*tracepb.Span
+	for _, serviceResult := range checkResult {
+		for _, endpoint := range serviceResult.Endpoints {
+			spans = append(spans, buildEndpointSpans(serviceResult.Name, endpoint)...)
+		}
+	}
+
+	return &tracepb.TracesData{
+		ResourceSpans: []*tracepb.ResourceSpans{
+			{
+				Resource: buildResource(serviceName),
+				ScopeSpans: []*tracepb.ScopeSpans{
+					{
+						Scope: &commonpb.InstrumentationScope{Name: scopeName},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	}
+}
+
+// buildEndpointSpans builds the span of an endpoint check and the spans of its attempts
+func buildEndpointSpans(serviceName string, endpoint checker.Endpoint) []*tracepb.Span {
+	if len(endpoint.Attempts) == 0 {
+		return nil
+	}
+
+	traceID := decodeHex(endpoint.TraceID)
+	checkSpanID := decodeHex(endpoint.SpanID)
+	first := endpoint.Attempts[0]
+	last := endpoint.Attempts[len(endpoint.Attempts)-1]
+
+	checkAttributes := []*commonpb.KeyValue{
+		stringAttribute("ponghub.service", serviceName),
+		stringAttribute("url.full", endpoint.URL),
+		stringAttribute("http.request.method", endpoint.Method),
+		stringAttribute("ponghub.status", endpoint.Status.String()),
+		intAttribute("ponghub.attempt_num", endpoint.AttemptNum),
+		intAttribute("ponghub.success_num", endpoint.SuccessNum),
+	}
+	if endpoint.StatusCode > 0 {
+		checkAttributes = append(checkAttributes, intAttribute("http.response.status_code", endpoint.StatusCode))
+	}
+	if len(endpoint.FailureDetails) > 0 {
+		checkAttributes = append(checkAttributes, stringAttribute("ponghub.failure_detail", endpoint.FailureDetails[len(endpoint.FailureDetails)-1]))
+	}
+	if endpoint.IsHTTPS {
+		checkAttributes = append(checkAttributes, intAttribute("ponghub.cert_remaining_days", endpoint.CertRemainingDays))
+	}
+
+	checkStatus := &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+	if endpoint.Status == chk_result.NONE {
+		checkStatus = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "endpoint is unavailable"}
+	}
+
+	spans := []*tracepb.Span{
+		{
+			TraceId:           traceID,
+			SpanId:            checkSpanID,
+			Name:              "check " + serviceName,
+			Kind:              tracepb.Span_SPAN_KIND_INTERNAL,
+			StartTimeUnixNano: unixNano(first.StartTime),
+			EndTimeUnixNano:   unixNano(last.StartTime.Add(last.Duration)),
+			Attributes:        checkAttributes,
+			Status:            checkStatus,
+		},
+	}
+
+	for i, attempt := range endpoint.Attempts {
+		attributes := []*commonpb.KeyValue{
+			stringAttribute("url.full", endpoint.URL),
+			stringAttribute("http.request.method", endpoint.Method),
+			intAttribute("ponghub.attempt", i+1),
+		}
+		if attempt.StatusCode > 0 {
+			attributes = append(attributes, intAttribute("http.response.status_code", attempt.StatusCode))
+		}
+
+		status := &tracepb.Status{Code: tracepb.Status_STATUS_CODE_OK}
+		if !attempt.IsSuccessful {
+			attributes = append(attributes, stringAttribute("ponghub.failure_detail", attempt.Error))
+			status = &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: attempt.Error}
+		}
+
+		spans = append(spans, &tracepb.Span{
+			TraceId:           traceID,
+			SpanId:            decodeHex(attempt.SpanID),
+			ParentSpanId:      checkSpanID,
+			Name:              endpoint.Method,
+			Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+			StartTimeUnixNano: unixNano(attempt.StartTime),
+			EndTimeUnixNano:   unixNano(attempt.StartTime.Add(attempt.Duration)),
+			Attributes:        attributes,
+			Status:            status,
+		})
+	}
+
+	return spans
+}
+
+// buildResource builds the resource describing this PongHub instance
+func buildResource(serviceName string) *resourcepb.Resource {
+	return &resourcepb.Resource{
+		Attributes: []*commonpb.KeyValue{
+			stringAttribute("service.name", serviceName),
+		},
+	}
+}
+
+// stringAttribute builds a string attribute
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
+
+// intAttribute builds an integer attribute
+func intAttribute(key string, value int) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(value)}},
+	}
+}
+
+// unixNano converts a time to nanoseconds since the Unix epoch
+func unixNano(t time.Time) uint64 {
+	return uint64(t.UnixNano())
+}
+
+// decodeHex decodes a hex ID, returning nil if it is invalid
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,44 @@
+package announcement
+
+// Statuses of an announcement update, following the usual stages of a public incident report
+const (
+	StatusInvestigating = "investigating"
+	StatusIdentified    = "identified"
+	StatusMonitoring    = "monitoring"
+	StatusResolved      = "resolved"
+	StatusNotice        = "notice" // general information that is not about an outage
+)
+
+type (
+	// Announcement is a human-written notice shown on the status page, attached to the services it is about.
+	// Its ID is the name of its file without the extension.
+	Announcement struct {
+		ID       string   `yaml:"-" json:"id"`
+		Title    string   `yaml:"title" json:"title"`
+		Services []string `yaml:"services,omitempty" json:"services,omitempty"`
+		Updates  []Update `yaml:"updates,omitempty" json:"updates"` // from the oldest to the latest
+	}
+
+	// Update is one message of an announcement; Time is RFC 3339 and Message is Markdown
+	Update struct {
+		Time    string `yaml:"time" json:"time"`
+		Status  string `yaml:"status" json:"status"`
+		Message string `yaml:"message" json:"message"`
+	}
+
+	// Announcements lists announcements from the most recently updated to the oldest
+	Announcements []Announcement
+)
+
+// Latest returns the latest update of the announcement, or an empty update if it has none
+func (a Announcement) Latest() Update {
+	if len(a.Updates) == 0 {
+		return Update{}
+	}
+	return a.Updates[len(a.Updates)-1]
+}
+
+// Status returns the status of the latest update of the announcement
+func (a Announcement) Status() string {
+	return a.Latest().Status
+}
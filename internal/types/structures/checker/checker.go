@@ -22,6 +22,8 @@ type (
 
 	// Endpoint defines the structure for the result of checking a port
 	Endpoint struct {
+		Key               string                 `json:"key"`
+		PreviousKeys      []string               `json:"-"`
 		URL               string                 `json:"url"`
 		Method            string                 `json:"method"`
 		Body              string                 `json:"body,omitempty"`
@@ -39,5 +41,18 @@ type (
 		IsCertExpired     bool                   `json:"is_cert_expired,omitempty"`
 		DisplayURL        string                 `json:"display_url,omitempty"`
 		HighlightSegments []highlight.Segment    `json:"highlight_segments,omitempty"`
+		TraceID           string                 `json:"trace_id,omitempty"`
+		SpanID            string                 `json:"span_id,omitempty"`
+		Attempts          []Attempt              `json:"attempts,omitempty"`
+	}
+
+	// Attempt defines the structure for a single request made while checking an endpoint
+	Attempt struct {
+		SpanID       string        `json:"span_id"`
+		StartTime    time.Time     `json:"start_time"`
+		Duration     time.Duration `json:"duration"`
+		StatusCode   int           `json:"status_code,omitempty"`
+		IsSuccessful bool          `json:"is_successful"`
+		Error        string        `json:"error,omitempty"`
 	}
 )
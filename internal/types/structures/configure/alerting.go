@@ -0,0 +1,10 @@
+package configure
+
+import "time"
+
+// FlappingConfig defines when an endpoint that keeps going up and down is reported as flapping
+type FlappingConfig struct {
+	Changes      int           `yaml:"changes,omitempty"`
+	Window       string        `yaml:"window,omitempty"`
+	ParsedWindow time.Duration `yaml:"-"`
+}
@@ -1,14 +1,32 @@
 package configure
 
+import "time"
+
 type (
 	// Configure defines the overall configuration structure for the application
 	Configure struct {
-		Services       []Service           `yaml:"services"`
+		Services       []Service           `yaml:"services,omitempty"`
+		Include        []string            `yaml:"include,omitempty"`
+		Templates      map[string]Template `yaml:"templates,omitempty"`
+		Sources        []string            `yaml:"-"`
 		Timeout        int                 `yaml:"timeout,omitempty"`
 		MaxRetryTimes  int                 `yaml:"max_retry_times,omitempty"`
 		MaxLogDays     int                 `yaml:"max_log_days,omitempty"`
 		CertNotifyDays int                 `yaml:"cert_notify_days,omitempty"`
 		DisplayNum     int                 `yaml:"display_num,omitempty"`
+		Interval       string              `yaml:"interval,omitempty"`
+		ParsedInterval time.Duration       `yaml:"-"`
+		MetricsFile    string              `yaml:"metrics_file,omitempty"`
+		AlertAfter     int                 `yaml:"alert_after,omitempty"`
+		Flapping       *FlappingConfig     `yaml:"flapping,omitempty"`
+		Storage        *StorageConfig      `yaml:"storage,omitempty"`
+		Rollups        *RollupConfig       `yaml:"rollups,omitempty"`
 		Notifications  *NotificationConfig `yaml:"notifications,omitempty"`
+		Telemetry      *TelemetryConfig    `yaml:"telemetry,omitempty"`
+	}
+
+	// IncludeFile defines the structure of a file pulled in through include or the services.d directory
+	IncludeFile struct {
+		Services []Service `yaml:"services"`
 	}
 )
@@ -0,0 +1,24 @@
+package configure
+
+import "time"
+
+type (
+	// EscalationConfig notifies more channels the longer an endpoint of a matching service stays down unacknowledged.
+	// It matches the services meeting every condition it sets, each condition matching if any of its values does.
+	EscalationConfig struct {
+		Name         string           `yaml:"name"`
+		Services     []string         `yaml:"services,omitempty"`
+		Tags         []string         `yaml:"tags,omitempty"`
+		Severities   []string         `yaml:"severities,omitempty"`
+		Steps        []EscalationStep `yaml:"steps"`
+		Repeat       string           `yaml:"repeat,omitempty"`
+		ParsedRepeat time.Duration    `yaml:"-"`
+	}
+
+	// EscalationStep notifies its channels once an outage has lasted After
+	EscalationStep struct {
+		After       string        `yaml:"after,omitempty"`
+		Channels    []string      `yaml:"channels"`
+		ParsedAfter time.Duration `yaml:"-"`
+	}
+)
@@ -0,0 +1,23 @@
+package configure
+
+import (
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/types/cron"
+)
+
+// MaintenanceWindow defines a period during which the failures of a service are expected.
+// It is either a one-off range from Start to End, or a recurring Schedule lasting Duration each time.
+type MaintenanceWindow struct {
+	Start          string         `yaml:"start,omitempty"`
+	End            string         `yaml:"end,omitempty"`
+	Schedule       string         `yaml:"schedule,omitempty"`
+	Duration       string         `yaml:"duration,omitempty"`
+	Timezone       string         `yaml:"timezone,omitempty"`
+	Reason         string         `yaml:"reason,omitempty"`
+	ParsedStart    time.Time      `yaml:"-"`
+	ParsedEnd      time.Time      `yaml:"-"`
+	ParsedSchedule *cron.Schedule `yaml:"-"`
+	ParsedDuration time.Duration  `yaml:"-"`
+	ParsedLocation *time.Location `yaml:"-"`
+}
@@ -3,15 +3,27 @@ package configure
 type (
 	// NotificationConfig defines the configuration for all notification channels
 	NotificationConfig struct {
-		Enabled  bool            `yaml:"enabled,omitempty"`
-		Methods  []string        `yaml:"methods,omitempty"`
-		Default  *DefaultConfig  `yaml:"default,omitempty"`
-		Discord  *DiscordConfig  `yaml:"discord,omitempty"`
-		Email    *EmailConfig    `yaml:"email,omitempty"`
-		Slack    *SlackConfig    `yaml:"slack,omitempty"`
-		Telegram *TelegramConfig `yaml:"telegram,omitempty"`
-		WeChat   *WeChatConfig   `yaml:"wechat,omitempty"`
-		Webhook  *WebhookConfig  `yaml:"webhook,omitempty"`
+		Enabled     bool               `yaml:"enabled,omitempty"`
+		Methods     []string           `yaml:"methods,omitempty"`
+		Default     *DefaultConfig     `yaml:"default,omitempty"`
+		Discord     *DiscordConfig     `yaml:"discord,omitempty"`
+		Email       *EmailConfig       `yaml:"email,omitempty"`
+		Slack       *SlackConfig       `yaml:"slack,omitempty"`
+		Telegram    *TelegramConfig    `yaml:"telegram,omitempty"`
+		WeChat      *WeChatConfig      `yaml:"wechat,omitempty"`
+		Webhook     *WebhookConfig     `yaml:"webhook,omitempty"`
+		Channels    []ChannelConfig    `yaml:"channels,omitempty"`
+		Routes      []RouteConfig      `yaml:"routes,omitempty"`
+		Escalations []EscalationConfig `yaml:"escalations,omitempty"`
+		Message     *MessageTemplate   `yaml:"message,omitempty"`
+		ReportURL   string             `yaml:"report_url,omitempty"`
+	}
+
+	// MessageTemplate defines Go text/templates rendering the title and the body of the alert notifications.
+	// An empty template keeps the built-in one.
+	MessageTemplate struct {
+		Title string `yaml:"title,omitempty"`
+		Body  string `yaml:"body,omitempty"`
 	}
 
 	// DiscordConfig defines Discord webhook notification settings
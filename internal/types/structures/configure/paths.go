@@ -0,0 +1,16 @@
+package configure
+
+// Paths defines the files read and written by PongHub
+type Paths struct {
+	Config        string // configuration file
+	Log           string // JSON file where check history is stored
+	Database      string // SQLite database where check history is stored
+	Report        string // rendered HTML report
+	Template      string // HTML template of the report
+	Notify        string // plain text notification written for GitHub Actions
+	State         string // JSON file where the alerting state of every endpoint is kept
+	Acks          string // JSON file where acknowledgements wait to be applied to the open outages
+	Incidents     string // JSON file where the incidents derived from the history are kept
+	Static        string // directory of static assets served with the report
+	Announcements string // directory of the announcements shown in the report
+}
@@ -0,0 +1,28 @@
+package configure
+
+type (
+	// ChannelConfig defines a named notification channel instance.
+	// Its settings are given under the key matching its type, as for the single channels of NotificationConfig.
+	ChannelConfig struct {
+		Name     string           `yaml:"name"`
+		Type     string           `yaml:"type"`
+		Default  *DefaultConfig   `yaml:"default,omitempty"`
+		Discord  *DiscordConfig   `yaml:"discord,omitempty"`
+		Email    *EmailConfig     `yaml:"email,omitempty"`
+		Slack    *SlackConfig     `yaml:"slack,omitempty"`
+		Telegram *TelegramConfig  `yaml:"telegram,omitempty"`
+		WeChat   *WeChatConfig    `yaml:"wechat,omitempty"`
+		Webhook  *WebhookConfig   `yaml:"webhook,omitempty"`
+		Message  *MessageTemplate `yaml:"message,omitempty"` // overrides the message templates of NotificationConfig
+	}
+
+	// RouteConfig sends the alerts matching every condition it sets to its channels.
+	// Each condition matches if any of its values does.
+	RouteConfig struct {
+		Services   []string `yaml:"services,omitempty"`
+		Tags       []string `yaml:"tags,omitempty"`
+		Severities []string `yaml:"severities,omitempty"`
+		Alerts     []string `yaml:"alerts,omitempty"`
+		Channels   []string `yaml:"channels"`
+	}
+)
@@ -1,16 +1,46 @@
 package configure
 
+import "time"
+
 type (
 	// Service defines the configuration for a service, including its health and Endpoints ports
 	Service struct {
-		Name          string     `yaml:"name"`
-		Endpoints     []Endpoint `yaml:"endpoints"`
-		Timeout       int        `yaml:"timeout,omitempty"`
-		MaxRetryTimes int        `yaml:"max_retry_times,omitempty"`
+		Name           string              `yaml:"name"`
+		Extends        string              `yaml:"extends,omitempty"`
+		Vars           map[string]string   `yaml:"vars,omitempty"`
+		Endpoints      []Endpoint          `yaml:"endpoints,omitempty"`
+		Timeout        int                 `yaml:"timeout,omitempty"`
+		MaxRetryTimes  int                 `yaml:"max_retry_times,omitempty"`
+		Interval       string              `yaml:"interval,omitempty"`
+		ParsedInterval time.Duration       `yaml:"-"`
+		AlertAfter     int                 `yaml:"alert_after,omitempty"`
+		Flapping       *FlappingConfig     `yaml:"flapping,omitempty"`
+		Maintenance    []MaintenanceWindow `yaml:"maintenance,omitempty"`
+		Tags           []string            `yaml:"tags,omitempty"`
+		Severity       string              `yaml:"severity,omitempty"`
+		Source         string              `yaml:"-"`
+	}
+
+	// Template defines settings shared by the services that extend it.
+	// Its endpoints are added in front of the endpoints of every such service.
+	Template struct {
+		Vars          map[string]string   `yaml:"vars,omitempty"`
+		Endpoints     []Endpoint          `yaml:"endpoints,omitempty"`
+		Timeout       int                 `yaml:"timeout,omitempty"`
+		MaxRetryTimes int                 `yaml:"max_retry_times,omitempty"`
+		Interval      string              `yaml:"interval,omitempty"`
+		AlertAfter    int                 `yaml:"alert_after,omitempty"`
+		Flapping      *FlappingConfig     `yaml:"flapping,omitempty"`
+		Maintenance   []MaintenanceWindow `yaml:"maintenance,omitempty"`
+		Tags          []string            `yaml:"tags,omitempty"`
+		Severity      string              `yaml:"severity,omitempty"`
 	}
 
 	// Endpoint defines the configuration for a port
 	Endpoint struct {
+		ID                  string            `yaml:"id,omitempty"`
+		PreviousIDs         []string          `yaml:"previous_ids,omitempty"`
+		Key                 string            `yaml:"-"`
 		URL                 string            `yaml:"url"`
 		ParsedURL           string            `yaml:"-"`
 		Method              string            `yaml:"method,omitempty"`
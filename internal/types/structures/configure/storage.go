@@ -0,0 +1,13 @@
+package configure
+
+// StorageConfig defines where the check history is stored
+type StorageConfig struct {
+	Type          string `yaml:"type,omitempty"`
+	RetentionDays int    `yaml:"retention_days,omitempty"`
+}
+
+// RollupConfig defines how long the rollups of expired history entries are kept
+type RollupConfig struct {
+	HourlyWeeks int `yaml:"hourly_weeks,omitempty"`
+	DailyDays   int `yaml:"daily_days,omitempty"`
+}
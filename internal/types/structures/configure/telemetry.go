@@ -0,0 +1,11 @@
+package configure
+
+// TelemetryConfig defines OpenTelemetry export settings for probe results
+type TelemetryConfig struct {
+	Enabled     bool              `yaml:"enabled,omitempty"`
+	Endpoint    string            `yaml:"endpoint,omitempty"`
+	Headers     map[string]string `yaml:"headers,omitempty"`
+	ServiceName string            `yaml:"service_name,omitempty"`
+	Propagate   bool              `yaml:"propagate,omitempty"`
+	Timeout     int               `yaml:"timeout,omitempty"`
+}
@@ -0,0 +1,40 @@
+package configure
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// ValidationError defines a single problem found in the configuration file
+	ValidationError struct {
+		File    string
+		Line    int
+		Column  int
+		Message string
+	}
+
+	// ValidationErrors defines every problem found in the configuration file
+	ValidationErrors []ValidationError
+)
+
+// Error formats the problem as file:line:column: message
+func (e ValidationError) Error() string {
+	switch {
+	case e.Line > 0 && e.Column > 0:
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+	case e.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	default:
+		return fmt.Sprintf("%s: %s", e.File, e.Message)
+	}
+}
+
+// Error formats every problem on its own line
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
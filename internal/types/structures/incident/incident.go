@@ -0,0 +1,25 @@
+package incident
+
+type (
+	// Incident is a period during which at least one endpoint of a service was down.
+	// Times are RFC 3339; End is empty while the incident is ongoing.
+	Incident struct {
+		Service    string     `json:"service"`
+		Start      string     `json:"start"`
+		End        string     `json:"end,omitempty"`
+		Endpoints  []Endpoint `json:"endpoints"`
+		FirstError string     `json:"first_error,omitempty"` // first error of the endpoint that went down first
+	}
+
+	// Endpoint is an endpoint affected by an incident, down from Start to End
+	Endpoint struct {
+		Key        string `json:"key"`
+		URL        string `json:"url"`
+		Start      string `json:"start"`
+		End        string `json:"end,omitempty"`
+		FirstError string `json:"first_error,omitempty"`
+	}
+
+	// Incidents lists incidents from the most recent to the oldest
+	Incidents []Incident
+)
@@ -1,5 +1,21 @@
 package logger
 
+import "time"
+
+const (
+	// FormatCSV selects comma-separated values with a header row for exported history
+	FormatCSV = "csv"
+
+	// FormatJSONL selects one JSON object per line for exported history
+	FormatJSONL = "jsonl"
+
+	// PeriodHour marks a rollup of the entries of one hour
+	PeriodHour = "hour"
+
+	// PeriodDay marks a rollup of the hourly rollups of one day
+	PeriodDay = "day"
+)
+
 type (
 	// HistoryEntry represents a single history entry
 	HistoryEntry struct {
@@ -13,10 +29,59 @@ type (
 
 	// Service represents log data for a service
 	Service struct {
-		ServiceHistory History   `json:"service_history"`
-		Endpoints      Endpoints `json:"endpoints"`
+		ServiceHistory  History            `json:"service_history"`
+		Endpoints       Endpoints          `json:"endpoints"`
+		ServiceRollups  Rollups            `json:"service_rollups,omitempty"`
+		EndpointRollups map[string]Rollups `json:"endpoint_rollups,omitempty"`
+	}
+
+	// Rollup summarizes the history entries of an hour or a day once they have expired
+	Rollup struct {
+		Time            string  `json:"time"`   // start of the period
+		Period          string  `json:"period"` // PeriodHour or PeriodDay
+		Count           int     `json:"count"`  // checks outside maintenance windows
+		Uptime          float64 `json:"uptime"` // ratio of the counted checks that were up
+		MinResponseTime int     `json:"min_response_time,omitempty"`
+		AvgResponseTime int     `json:"avg_response_time,omitempty"`
+		P95ResponseTime int     `json:"p95_response_time,omitempty"`
+	}
+
+	Rollups []Rollup
+
+	// Retention defines how long raw entries, hourly rollups and daily rollups are kept
+	Retention struct {
+		RawDays     int
+		HourlyWeeks int
+		DailyDays   int
+	}
+
+	// EndpointMove moves the history of an endpoint of a service from a previous key to its current key
+	EndpointMove struct {
+		Service string
+		From    string
+		To      string
 	}
 
 	// Logger represents the entire log structure
 	Logger map[string]Service
+
+	// Record is a single history entry flattened for export and import.
+	// Service history entries have an empty endpoint.
+	Record struct {
+		Service      string `json:"service"`
+		Endpoint     string `json:"endpoint,omitempty"`
+		Time         string `json:"time"`
+		Status       string `json:"status"`
+		ResponseTime int    `json:"response_time,omitempty"`
+	}
+
+	Records []Record
+
+	// RecordFilter selects the records to export; empty fields match everything
+	RecordFilter struct {
+		Services  []string
+		Endpoints []string
+		From      time.Time
+		To        time.Time
+	}
 )
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/types/chk_result"
+)
+
+// AddEntries rolls the expired entries up into hourly rollups.
+// Hours that already have a rollup are skipped, so entries seen twice are only counted once.
+func (r Rollups) AddEntries(expired History) Rollups {
+	byHour := make(map[time.Time]History)
+	for _, entry := range expired {
+		entryTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil {
+			continue
+		}
+		hour := entryTime.UTC().Truncate(time.Hour)
+		byHour[hour] = append(byHour[hour], entry)
+	}
+
+	for hour, entries := range byHour {
+		if r.covers(hour) {
+			continue
+		}
+		r = append(r, newRollup(hour, PeriodHour, entries))
+	}
+	r.sort()
+	return r
+}
+
+// Compact turns the hourly rollups older than the hourly retention into daily rollups
+// and drops the daily rollups older than the daily retention
+func (r Rollups) Compact(retention Retention, now time.Time) Rollups {
+	hourlyCutoff := now.UTC().AddDate(0, 0, -7*retention.HourlyWeeks).Truncate(24 * time.Hour)
+	dailyCutoff := now.UTC().AddDate(0, 0, -retention.DailyDays).Truncate(24 * time.Hour)
+
+	var compacted Rollups
+	byDay := make(map[time.Time]Rollups)
+	for _, rollup := range r {
+		start, err := time.Parse(time.RFC3339, rollup.Time)
+		if err != nil {
+			continue
+		}
+		if rollup.Period == PeriodHour && retention.HourlyWeeks > 0 && start.Before(hourlyCutoff) {
+			day := start.UTC().Truncate(24 * time.Hour)
+			byDay[day] = append(byDay[day], rollup)
+			continue
+		}
+		compacted = append(compacted, rollup)
+	}
+
+	for day, rollups := range byDay {
+		compacted = append(compacted, mergeRollups(day, PeriodDay, rollups))
+	}
+
+	var kept Rollups
+	for _, rollup := range compacted {
+		start, err := time.Parse(time.RFC3339, rollup.Time)
+		if err != nil {
+			continue
+		}
+		if retention.DailyDays > 0 && start.Before(dailyCutoff) {
+			continue
+		}
+		kept = append(kept, rollup)
+	}
+	kept.sort()
+	return kept
+}
+
+// Merge combines two rollup lists, keeping the rollups of r where both cover the same period
+func (r Rollups) Merge(other Rollups) Rollups {
+	merged := append(Rollups{}, r...)
+	for _, rollup := range other {
+		start, err := time.Parse(time.RFC3339, rollup.Time)
+		if err != nil || merged.covers(start) {
+			continue
+		}
+		merged = append(merged, rollup)
+	}
+	merged.sort()
+	return merged
+}
+
+// Uncovered returns the entries of history whose time no rollup includes yet
+func (r Rollups) Uncovered(history History) History {
+	var uncovered History
+	for _, entry := range history {
+		entryTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil || r.covers(entryTime) {
+			continue
+		}
+		uncovered = append(uncovered, entry)
+	}
+	return uncovered
+}
+
+// covers reports whether a rollup already includes the hour starting at start
+func (r Rollups) covers(start time.Time) bool {
+	for _, rollup := range r {
+		rollupStart, err := time.Parse(time.RFC3339, rollup.Time)
+		if err != nil {
+			continue
+		}
+		end := rollupStart.Add(time.Hour)
+		if rollup.Period == PeriodDay {
+			end = rollupStart.Add(24 * time.Hour)
+		}
+		if !start.Before(rollupStart) && start.Before(end) {
+			return true
+		}
+	}
+	return false
+}
+
+// sort orders the rollups by the start of their period
+func (r Rollups) sort() {
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Time < r[j].Time
+	})
+}
+
+// newRollup summarizes history entries into a rollup of the given period
+func newRollup(start time.Time, period string, entries History) Rollup {
+	rollup := Rollup{
+		Time:   start.Format(time.RFC3339),
+		Period: period,
+	}
+
+	upNum := 0
+	var responseTimes []int
+	for _, entry := range entries {
+		// checks during maintenance do not count towards the uptime
+		if !chk_result.IsMaintenance(entry.Status) {
+			rollup.Count++
+		}
+		if chk_result.IsALL(entry.Status) {
+			upNum++
+		}
+		if entry.ResponseTime > 0 {
+			responseTimes = append(responseTimes, entry.ResponseTime)
+		}
+	}
+	if rollup.Count > 0 {
+		rollup.Uptime = float64(upNum) / float64(rollup.Count)
+	}
+
+	if len(responseTimes) > 0 {
+		sort.Ints(responseTimes)
+		total := 0
+		for _, responseTime := range responseTimes {
+			total += responseTime
+		}
+		rollup.MinResponseTime = responseTimes[0]
+		rollup.AvgResponseTime = total / len(responseTimes)
+		rollup.P95ResponseTime = responseTimes[percentileIndex(len(responseTimes), 0.95)]
+	}
+	return rollup
+}
+
+// mergeRollups combines rollups into a single rollup of the given period.
+// The p95 response time is estimated as the count-weighted 95th percentile of the p95 of the parts.
+func mergeRollups(start time.Time, period string, rollups Rollups) Rollup {
+	merged := Rollup{Time: start.Format(time.RFC3339), Period: period}
+
+	upSum, responseSum, responseCount := 0.0, 0, 0
+	for _, rollup := range rollups {
+		merged.Count += rollup.Count
+		upSum += rollup.Uptime * float64(rollup.Count)
+		if rollup.AvgResponseTime > 0 {
+			responseSum += rollup.AvgResponseTime * rollup.Count
+			responseCount += rollup.Count
+			if merged.MinResponseTime == 0 || rollup.MinResponseTime < merged.MinResponseTime {
+				merged.MinResponseTime = rollup.MinResponseTime
+			}
+		}
+	}
+	if merged.Count > 0 {
+		merged.Uptime = upSum / float64(merged.Count)
+	}
+	if responseCount > 0 {
+		merged.AvgResponseTime = responseSum / responseCount
+	}
+
+	parts := append(Rollups{}, rollups...)
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].P95ResponseTime < parts[j].P95ResponseTime
+	})
+	target, seen := 0.95*float64(responseCount), 0
+	for _, part := range parts {
+		if part.AvgResponseTime == 0 {
+			continue
+		}
+		seen += part.Count
+		merged.P95ResponseTime = part.P95ResponseTime
+		if float64(seen) >= target {
+			break
+		}
+	}
+	return merged
+}
+
+// percentileIndex returns the index of the p-th percentile in a sorted list of n values using the nearest-rank method
+func percentileIndex(n int, p float64) int {
+	index := int(math.Ceil(p*float64(n))) - 1
+	if index < 0 {
+		return 0
+	}
+	return index
+}
@@ -2,18 +2,26 @@ package logger
 
 import (
 	"log"
+	"sort"
 	"time"
 )
 
 // CleanExpiredEntries removes entries older than maxDays from the history entry list.
 func (h History) CleanExpiredEntries(maxDays int) History {
+	kept, _ := h.SplitExpiredEntries(maxDays)
+	return kept
+}
+
+// SplitExpiredEntries separates the entries older than maxDays from the history entry list.
+// The cutoff is aligned to the hour, so that every hour expires as a whole and can be rolled up once.
+func (h History) SplitExpiredEntries(maxDays int) (History, History) {
 	if maxDays <= 0 {
 		log.Println("Max days for cleaning history is not set or invalid, skipping cleaning.")
-		return h // No cleaning needed if maxDays is not set
+		return h, nil // No cleaning needed if maxDays is not set
 	}
 
-	cutoffTime := time.Now().AddDate(0, 0, -maxDays)
-	var cleanedHistory History
+	cutoffTime := time.Now().AddDate(0, 0, -maxDays).Truncate(time.Hour)
+	var cleanedHistory, expiredHistory History
 
 	for _, entry := range h {
 		entryTime, err := time.Parse(time.RFC3339, entry.Time)
@@ -21,12 +29,14 @@ func (h History) CleanExpiredEntries(maxDays int) History {
 			log.Printf("Error parsing time %s: %v", entry.Time, err)
 			continue // Skip entries with invalid time format
 		}
-		if entryTime.After(cutoffTime) {
+		if entryTime.Before(cutoffTime) {
+			expiredHistory = append(expiredHistory, entry)
+		} else {
 			cleanedHistory = append(cleanedHistory, entry)
 		}
 	}
 
-	return cleanedHistory
+	return cleanedHistory, expiredHistory
 }
 
 // AddEntry adds a new entry to the history entry list.
@@ -34,3 +44,52 @@ func (h History) AddEntry(entry HistoryEntry) History {
 	newHistory := append(h, entry)
 	return newHistory
 }
+
+// Merge combines two history entry lists, sorted by time.
+func (h History) Merge(other History) History {
+	merged := append(append(History{}, h...), other...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Time < merged[j].Time
+	})
+	return merged
+}
+
+// MergeUnique adds the entries of other whose timestamp is not in the history yet, sorted by time.
+// Timestamps are compared as instants, so the same time written with another offset is a duplicate.
+// It returns the merged history and the number of entries added.
+func (h History) MergeUnique(other History) (History, int) {
+	seen := make(map[int64]bool, len(h))
+	for _, entry := range h {
+		if entryTime, err := time.Parse(time.RFC3339, entry.Time); err == nil {
+			seen[entryTime.Unix()] = true
+		}
+	}
+
+	var added History
+	for _, entry := range other {
+		entryTime, err := time.Parse(time.RFC3339, entry.Time)
+		if err != nil || seen[entryTime.Unix()] {
+			continue
+		}
+		seen[entryTime.Unix()] = true
+		added = append(added, entry)
+	}
+	if len(added) == 0 {
+		return h, 0
+	}
+
+	merged := append(append(History{}, h...), added...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return entryUnix(merged[i]) < entryUnix(merged[j])
+	})
+	return merged, len(added)
+}
+
+// entryUnix returns the time of an entry in seconds, or 0 if it cannot be parsed
+func entryUnix(entry HistoryEntry) int64 {
+	entryTime, err := time.Parse(time.RFC3339, entry.Time)
+	if err != nil {
+		return 0
+	}
+	return entryTime.Unix()
+}
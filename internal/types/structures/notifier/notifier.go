@@ -0,0 +1,120 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/wcy-dt/ponghub/internal/types/structures/checker"
+)
+
+const (
+	// StateUp marks an endpoint whose last check did not fail
+	StateUp = "up"
+
+	// StateDown marks an endpoint whose last check failed
+	StateDown = "down"
+
+	// CertExpiring marks a certificate that expires within cert_notify_days
+	CertExpiring = "expiring"
+
+	// CertExpired marks a certificate that has expired
+	CertExpired = "expired"
+
+	// AlertDown is sent when an endpoint goes from up to down
+	AlertDown = "down"
+
+	// AlertRecovered is sent when an endpoint comes back up
+	AlertRecovered = "recovered"
+
+	// AlertCert is sent when the certificate of an endpoint starts expiring or expires
+	AlertCert = "cert"
+
+	// AlertFlapping is sent once when an endpoint changes status too often, instead of a stream of down and recovered alerts
+	AlertFlapping = "flapping"
+
+	// AlertStable is sent when a flapping endpoint has stayed up for a whole flapping window
+	AlertStable = "stable"
+
+	// AlertEscalated is sent to the channels of an escalation step once an unacknowledged outage has lasted its delay
+	AlertEscalated = "escalated"
+
+	// AlertReminder is sent at the repeat interval of its escalation policy while an outage is unacknowledged
+	AlertReminder = "reminder"
+)
+
+type (
+	// EndpointState is the alerting state of an endpoint, kept between runs
+	EndpointState struct {
+		Status        string   `json:"status"`                   // StateUp or StateDown, as last reported
+		Since         string   `json:"since"`                    // time of the first check in this status
+		LastStatus    string   `json:"last_status,omitempty"`    // StateUp or StateDown, as last checked
+		Failures      int      `json:"failures,omitempty"`       // consecutive failed checks
+		FailingSince  string   `json:"failing_since,omitempty"`  // time of the first of the consecutive failed checks
+		Changes       []string `json:"changes,omitempty"`        // times of the status changes within the flapping window
+		Flapping      bool     `json:"flapping,omitempty"`       // whether the endpoint is reported as flapping
+		FlappingSince string   `json:"flapping_since,omitempty"` // time of the first change counted when flapping began
+		CertStatus    string   `json:"cert_status,omitempty"`    // CertExpiring, CertExpired or empty
+
+		Escalation *EscalationState `json:"escalation,omitempty"` // progress of the escalation of the current outage
+	}
+
+	// EscalationState is the progress of the escalation of an outage, kept between runs
+	EscalationState struct {
+		Policy         string `json:"policy"`                    // name of the escalation policy
+		Steps          int    `json:"steps,omitempty"`           // number of steps notified
+		LastNotified   string `json:"last_notified,omitempty"`   // time of the last escalation or reminder
+		AcknowledgedBy string `json:"acknowledged_by,omitempty"` // who acknowledged the outage
+		AcknowledgedAt string `json:"acknowledged_at,omitempty"` // when the outage was acknowledged, empty while it is not
+		Note           string `json:"note,omitempty"`            // note left with the acknowledgement
+	}
+
+	// Acknowledgement stops the escalation of the outages of a service, or of one of its endpoints, open at its time
+	Acknowledgement struct {
+		Service  string `json:"service"`
+		Endpoint string `json:"endpoint,omitempty"` // URL or key of the endpoint, every endpoint of the service if empty
+		By       string `json:"by,omitempty"`
+		Note     string `json:"note,omitempty"`
+		Time     string `json:"time"` // RFC 3339 time of the acknowledgement
+	}
+
+	// State maps service names to the alerting state of their endpoints, keyed by endpoint key
+	State map[string]map[string]EndpointState
+
+	// Alert describes a change in the state of an endpoint
+	Alert struct {
+		Type     string           // AlertDown, AlertRecovered, AlertCert, AlertFlapping, AlertStable, AlertEscalated or AlertReminder
+		Service  string           // name of the service
+		Endpoint checker.Endpoint // result of the check that changed the state
+		Since    string           // start of the outage, or of the flapping
+		Duration time.Duration    // for recoveries, how long the endpoint was down; for stable endpoints, how long it flapped
+		Failures int              // for down alerts, the number of consecutive failed checks
+		Changes  int              // for flapping alerts, the number of status changes within the window
+		Policy   string           // for escalations and reminders, the name of the escalation policy
+		Step     int              // for escalations, the number of the step reached, counting from 1
+		Channels []string         // channels notified on top of the routed ones; the only ones for escalations
+	}
+
+	// MessageData is the data the message templates of a channel are executed with
+	MessageData struct {
+		Channel   string          // name of the channel the notification is sent through
+		Time      string          // time the notification was generated at, RFC 3339
+		ReportURL string          // URL of the status page, if configured
+		Alerts    []Alert         // every alert sent through the channel
+		Services  []ServiceAlerts // the alerts grouped by service, in the order of the alerts
+		Counts    map[string]int  // number of alerts of each type
+	}
+
+	// ServiceAlerts holds the alerts about a single service
+	ServiceAlerts struct {
+		Name   string
+		Alerts []Alert
+	}
+
+	// Delivery is the outcome of sending a notification through a channel
+	Delivery struct {
+		Channel string        // name of the channel instance, or the method for the single channels
+		Type    string        // type of the channel, such as slack or webhook
+		Alerts  int           // number of alerts in the notification
+		Elapsed time.Duration // time taken to send the notification, retries included
+		Err     error         // nil if the notification was delivered
+	}
+)
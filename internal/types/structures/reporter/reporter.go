@@ -6,32 +6,42 @@ import "github.com/wcy-dt/ponghub/internal/types/types/highlight"
 type (
 	// HistoryEntry represents a single history entry
 	HistoryEntry struct {
-		Time         string
-		Status       string
-		ResponseTime int
+		Time         string `json:"time"`
+		Status       string `json:"status"`
+		ResponseTime int    `json:"response_time,omitempty"`
 	}
 
 	History []HistoryEntry
 
 	Endpoint struct {
-		URL               string // Added URL field to store the endpoint URL
-		EndpointHistory   History
-		IsHTTPS           bool
-		IsCertExpired     bool
-		CertRemainingDays int
-		DisplayURL        string              // Resolved URL for display
-		HighlightSegments []highlight.Segment // Segments with highlight info
+		Key               string              `json:"key"`
+		URL               string              `json:"url"` // Added URL field to store the endpoint URL
+		EndpointHistory   History             `json:"history"`
+		IsHTTPS           bool                `json:"is_https,omitempty"`
+		IsCertExpired     bool                `json:"is_cert_expired,omitempty"`
+		CertRemainingDays int                 `json:"cert_remaining_days,omitempty"`
+		DisplayURL        string              `json:"display_url,omitempty"`        // Resolved URL for display
+		HighlightSegments []highlight.Segment `json:"highlight_segments,omitempty"` // Segments with highlight info
 	}
 
 	// Endpoints is a slice of Endpoint
 	Endpoints []Endpoint
 
+	// AvailabilityWindow is the availability of a service over the last Days days,
+	// computed from the raw history and the rollups of older checks
+	AvailabilityWindow struct {
+		Days         int     `json:"days"`
+		Availability float64 `json:"availability"`
+		Count        int     `json:"count"`
+	}
+
 	// Service represents the result of checking a service
 	Service struct {
-		Name           string // Added Name field to identify the service
-		ServiceHistory History
-		Availability   float64
-		Endpoints      Endpoints
+		Name                string               `json:"name"` // Added Name field to identify the service
+		ServiceHistory      History              `json:"history"`
+		Availability        float64              `json:"availability"`
+		AvailabilityWindows []AvailabilityWindow `json:"availability_windows,omitempty"`
+		Endpoints           Endpoints            `json:"endpoints"`
 	}
 
 	// Reporter is a slice of Service
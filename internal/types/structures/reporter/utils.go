@@ -63,21 +63,22 @@ func ParseLogResult(logResult logger.Logger, serviceNames []string, cfg *configu
 		if serviceConfig != nil {
 			// Process endpoints in config order
 			for _, endpointConfig := range serviceConfig.Endpoints {
-				url := endpointConfig.URL
-				if endpointLog, exists := serviceLog.Endpoints[url]; exists {
+				if endpointLog, exists := serviceLog.Endpoints[endpointConfig.Key]; exists {
 					endpointHistory := convertToHistory(endpointLog, cfg.DisplayNum)
 					endpoints = append(endpoints, Endpoint{
-						URL:             url,
+						Key:             endpointConfig.Key,
+						URL:             endpointConfig.URL,
 						EndpointHistory: endpointHistory,
 					})
 				}
 			}
 		} else {
 			// Fallback: if no config found, use existing endpoints (shouldn't happen normally)
-			for url, endpointLog := range serviceLog.Endpoints {
+			for key, endpointLog := range serviceLog.Endpoints {
 				endpointHistory := convertToHistory(endpointLog, cfg.DisplayNum)
 				endpoints = append(endpoints, Endpoint{
-					URL:             url,
+					Key:             key,
+					URL:             key,
 					EndpointHistory: endpointHistory,
 				})
 			}
@@ -0,0 +1,33 @@
+package server
+
+import "github.com/wcy-dt/ponghub/internal/types/structures/reporter"
+
+type (
+	// ServiceSummary describes the current status of a service in the JSON API
+	ServiceSummary struct {
+		Name                string                        `json:"name"`
+		Status              string                        `json:"status"`
+		Availability        float64                       `json:"availability"`
+		AvailabilityWindows []reporter.AvailabilityWindow `json:"availability_windows,omitempty"`
+		LastCheck           string                        `json:"last_check"`
+		Endpoints           []EndpointSummary             `json:"endpoints"`
+	}
+
+	// EndpointSummary describes the current status of an endpoint in the JSON API
+	EndpointSummary struct {
+		Key               string `json:"key"`
+		URL               string `json:"url"`
+		DisplayURL        string `json:"display_url,omitempty"`
+		Status            string `json:"status"`
+		ResponseTime      int    `json:"response_time,omitempty"`
+		LastCheck         string `json:"last_check"`
+		IsHTTPS           bool   `json:"is_https,omitempty"`
+		IsCertExpired     bool   `json:"is_cert_expired,omitempty"`
+		CertRemainingDays int    `json:"cert_remaining_days,omitempty"`
+	}
+
+	// ErrorResponse is returned by the JSON API when a request fails
+	ErrorResponse struct {
+		Error string `json:"error"`
+	}
+)
@@ -12,6 +12,9 @@ const (
 	// NONE represents no ports are online
 	NONE CheckResult = "none"
 
+	// MAINTENANCE represents a failed check during a maintenance window of the service
+	MAINTENANCE CheckResult = "maintenance"
+
 	// UNKNOWN represents an unknown test result
 	UNKNOWN CheckResult = "unknown"
 )
@@ -25,6 +28,8 @@ func (tr CheckResult) String() string {
 		return "part"
 	case NONE:
 		return "none"
+	case MAINTENANCE:
+		return "maintenance"
 	default:
 		return "unknown"
 	}
@@ -32,7 +37,7 @@ func (tr CheckResult) String() string {
 
 // IsValid checks if the CheckResult is valid
 func (tr CheckResult) IsValid() bool {
-	return tr == ALL || tr == PART || tr == NONE
+	return tr == ALL || tr == PART || tr == NONE || tr == MAINTENANCE
 }
 
 // IsALL checks if the CheckResult is ALL
@@ -40,6 +45,11 @@ func IsALL(resultStr string) bool {
 	return ParseCheckResult(resultStr) == ALL
 }
 
+// IsMaintenance checks if the CheckResult is MAINTENANCE
+func IsMaintenance(resultStr string) bool {
+	return ParseCheckResult(resultStr) == MAINTENANCE
+}
+
 // ParseCheckResult parses a string into a CheckResult
 func ParseCheckResult(s string) CheckResult {
 	switch s {
@@ -49,6 +59,8 @@ func ParseCheckResult(s string) CheckResult {
 		return PART
 	case "none":
 		return NONE
+	case "maintenance":
+		return MAINTENANCE
 	default:
 		return UNKNOWN
 	}
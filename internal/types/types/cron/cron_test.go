@@ -0,0 +1,42 @@
+package cron
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSchedule(t *testing.T) {
+	tests := []struct {
+		expr    string
+		time    string
+		matches bool
+	}{
+		{"0 3 * * 0", "2025-01-05T03:00:00Z", true},  // Sunday 03:00
+		{"0 3 * * 7", "2025-01-05T03:00:00Z", true},  // 7 is Sunday as well
+		{"0 3 * * 0", "2025-01-06T03:00:00Z", false}, // Monday
+		{"*/15 * * * *", "2025-01-06T10:45:00Z", true},
+		{"*/15 * * * *", "2025-01-06T10:50:00Z", false},
+		{"30 22 1-7 * 1-5", "2025-01-06T22:30:00Z", true}, // day of month matches
+		{"30 22 1,15 * 6", "2025-01-11T22:30:00Z", true},  // day of week matches
+		{"30 22 1,15 * 6", "2025-01-10T22:30:00Z", false}, // neither matches
+		{"@daily", "2025-01-10T00:00:00Z", true},
+		{"@monthly", "2025-02-01T00:00:00Z", true},
+	}
+	for _, test := range tests {
+		schedule, err := ParseSchedule(test.expr)
+		if err != nil {
+			t.Errorf("Expected %q to parse, got %v", test.expr, err)
+			continue
+		}
+		at, _ := time.Parse(time.RFC3339, test.time)
+		if schedule.Matches(at) != test.matches {
+			t.Errorf("Expected %q to match %s: %v, got %v", test.expr, test.time, test.matches, !test.matches)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := ParseSchedule(expr); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
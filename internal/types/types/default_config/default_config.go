@@ -1,5 +1,10 @@
 package default_config
 
+import (
+	"path/filepath"
+	"time"
+)
+
 const (
 	// timeout is the default timeout for service checks in seconds
 	timeout = 5
@@ -12,6 +17,12 @@ const (
 
 	// certNotifyDays is the default number of days to notify before certificate expiration
 	certNotifyDays = 7
+
+	// alertAfter is the default number of consecutive failed checks before an endpoint is reported down
+	alertAfter = 1
+
+	// severity is the default severity of the alerts of a service, matched by the notification routes
+	severity = "warning"
 )
 
 // GetDefaultTimeout returns the default timeout for service checks
@@ -34,6 +45,11 @@ func GetDefaultCertNotifyDays() int {
 	return certNotifyDays
 }
 
+// GetDefaultAlertAfter returns the default number of consecutive failed checks before an endpoint is reported down
+func GetDefaultAlertAfter() int {
+	return alertAfter
+}
+
 // SetDefaultTimeout sets the default timeout for a given configuration pointer
 func SetDefaultTimeout(cfg *int) {
 	if *cfg <= 0 {
@@ -62,6 +78,105 @@ func SetDefaultCertNotifyDays(cfg *int) {
 	}
 }
 
+const (
+	// interval is the default interval between two checks of a service in daemon mode
+	interval = 30 * time.Minute
+)
+
+// GetDefaultInterval returns the default interval between two checks of a service in daemon mode
+func GetDefaultInterval() time.Duration {
+	return interval
+}
+
+// SetDefaultInterval sets the default check interval for a given configuration pointer
+func SetDefaultInterval(cfg *time.Duration, fallback time.Duration) {
+	if *cfg <= 0 {
+		*cfg = fallback
+	}
+}
+
+// GetDefaultSeverity returns the default severity of the alerts of a service
+func GetDefaultSeverity() string {
+	return severity
+}
+
+// SetDefaultSeverity sets the default severity of the alerts of a service for a given configuration pointer
+func SetDefaultSeverity(cfg *string) {
+	if *cfg == "" {
+		*cfg = severity
+	}
+}
+
+// SetDefaultAlertAfter sets the number of consecutive failed checks before an endpoint is reported down
+// for a given configuration pointer, using fallback if it is not set
+func SetDefaultAlertAfter(cfg *int, fallback int) {
+	if *cfg <= 0 {
+		*cfg = fallback
+	}
+}
+
+const (
+	// storageType is the default backend storing the check history
+	storageType = "json"
+
+	// retentionDays is the default number of days of history kept by the SQLite store
+	retentionDays = 90
+
+	// rollupHourlyWeeks is the default number of weeks hourly rollups are kept before they become daily rollups
+	rollupHourlyWeeks = 4
+
+	// rollupDailyDays is the default number of days daily rollups are kept
+	rollupDailyDays = 365
+)
+
+// GetDefaultStorageType returns the default backend storing the check history
+func GetDefaultStorageType() string {
+	return storageType
+}
+
+// GetDefaultRetentionDays returns the default number of days of history kept by the SQLite store
+func GetDefaultRetentionDays() int {
+	return retentionDays
+}
+
+// GetDefaultRollupHourlyWeeks returns the default number of weeks hourly rollups are kept
+func GetDefaultRollupHourlyWeeks() int {
+	return rollupHourlyWeeks
+}
+
+// GetDefaultRollupDailyDays returns the default number of days daily rollups are kept
+func GetDefaultRollupDailyDays() int {
+	return rollupDailyDays
+}
+
+// SetDefaultRollupHourlyWeeks sets the default number of weeks hourly rollups are kept for a given configuration pointer
+func SetDefaultRollupHourlyWeeks(cfg *int) {
+	if *cfg <= 0 {
+		*cfg = GetDefaultRollupHourlyWeeks()
+	}
+}
+
+// SetDefaultRollupDailyDays sets the default number of days daily rollups are kept for a given configuration pointer
+func SetDefaultRollupDailyDays(cfg *int) {
+	if *cfg <= 0 {
+		*cfg = GetDefaultRollupDailyDays()
+	}
+}
+
+// SetDefaultStorageType sets the default storage backend for a given configuration pointer
+func SetDefaultStorageType(cfg *string) {
+	if *cfg == "" {
+		*cfg = GetDefaultStorageType()
+	}
+}
+
+// SetDefaultRetentionDays sets the default number of days of history kept by the SQLite store for a given configuration pointer
+func SetDefaultRetentionDays(cfg *int) {
+	if *cfg <= 0 {
+		*cfg = GetDefaultRetentionDays()
+	}
+}
+
 const (
 	// displayNum is the default number of logs per endpoint to display in the HTML report
 	displayNum = 72
@@ -83,17 +198,41 @@ const (
 	// configPath is the default path to the configuration file
 	configPath = "config.yaml"
 
-	// logPath is the default path to the data file where logs are stored
-	logPath = "data/ponghub_log.json"
+	// servicesDir is the directory next to the configuration file whose YAML files are always included
+	servicesDir = "services.d"
+
+	// dataDir is the default directory where logs, the report and notifications are written
+	dataDir = "data"
 
-	// reportPath is the default path to the HTML report file
-	reportPath = "data/index.html"
+	// logFile is the name of the data file where logs are stored
+	logFile = "ponghub_log.json"
+
+	// databaseFile is the name of the SQLite database where logs are stored
+	databaseFile = "ponghub.db"
+
+	// reportFile is the name of the HTML report file
+	reportFile = "index.html"
 
 	// templatePath is the default path to the HTML template file
 	templatePath = "templates/report.html"
 
-	// notifyPath is the default path to the notification template file
-	notifyPath = "data/notify.txt"
+	// notifyFile is the name of the notification template file
+	notifyFile = "notify.txt"
+
+	// alertStateFile is the name of the file where the alerting state of every endpoint is kept between runs
+	alertStateFile = "alert_state.json"
+
+	// acksFile is the name of the file where acknowledgements wait to be applied to the open outages
+	acksFile = "acknowledgements.json"
+
+	// incidentsFile is the name of the file where the incidents derived from the history are kept
+	incidentsFile = "incidents.json"
+
+	// staticPath is the default path to the static assets served with the report
+	staticPath = "static"
+
+	// announcementsPath is the default directory of the announcements shown in the report
+	announcementsPath = "announcements"
 )
 
 // GetConfigPath returns the default path to the configuration file
@@ -101,14 +240,29 @@ func GetConfigPath() string {
 	return configPath
 }
 
-// GetLogPath returns the default path to the data file where logs are stored
-func GetLogPath() string {
-	return logPath
+// GetServicesDir returns the directory next to the configuration file whose YAML files are always included
+func GetServicesDir() string {
+	return servicesDir
+}
+
+// GetDataDir returns the default directory where logs, the report and notifications are written
+func GetDataDir() string {
+	return dataDir
 }
 
-// GetReportPath returns the default path to the HTML report file
-func GetReportPath() string {
-	return reportPath
+// GetLogPath returns the path to the data file where logs are stored inside the given data directory
+func GetLogPath(dataDir string) string {
+	return filepath.Join(dataDir, logFile)
+}
+
+// GetDatabasePath returns the path to the SQLite database where logs are stored inside the given data directory
+func GetDatabasePath(dataDir string) string {
+	return filepath.Join(dataDir, databaseFile)
+}
+
+// GetReportPath returns the path to the HTML report file inside the given data directory
+func GetReportPath(dataDir string) string {
+	return filepath.Join(dataDir, reportFile)
 }
 
 // GetTemplatePath returns the default path to the HTML template file
@@ -116,7 +270,32 @@ func GetTemplatePath() string {
 	return templatePath
 }
 
-// GetNotifyPath returns the default path to the notification template file
-func GetNotifyPath() string {
-	return notifyPath
+// GetNotifyPath returns the path to the notification template file inside the given data directory
+func GetNotifyPath(dataDir string) string {
+	return filepath.Join(dataDir, notifyFile)
+}
+
+// GetAlertStatePath returns the path to the alerting state file inside the given data directory
+func GetAlertStatePath(dataDir string) string {
+	return filepath.Join(dataDir, alertStateFile)
+}
+
+// GetAcksPath returns the path to the acknowledgements file inside the given data directory
+func GetAcksPath(dataDir string) string {
+	return filepath.Join(dataDir, acksFile)
+}
+
+// GetIncidentsPath returns the path to the incidents file inside the given data directory
+func GetIncidentsPath(dataDir string) string {
+	return filepath.Join(dataDir, incidentsFile)
+}
+
+// GetStaticPath returns the default path to the static assets served with the report
+func GetStaticPath() string {
+	return staticPath
+}
+
+// GetAnnouncementsPath returns the default directory of the announcements shown in the report
+func GetAnnouncementsPath() string {
+	return announcementsPath
 }